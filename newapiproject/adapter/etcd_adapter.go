@@ -5,6 +5,8 @@ import (
 
 	"newapiprojet/database"
 	"newapiprojet/etcd"
+
+	"go.etcd.io/etcd/client/v3/concurrency"
 )
 
 type EtcdAdapter struct {
@@ -27,6 +29,46 @@ func (e *EtcdAdapter) Delete(ctx context.Context, key string) error {
 	return e.client.Delete(ctx, key)
 }
 
+func (e *EtcdAdapter) List(ctx context.Context, prefix string) (map[string][]byte, error) {
+	return e.client.List(ctx, prefix)
+}
+
+func (e *EtcdAdapter) Range(ctx context.Context, start, end string) (map[string][]byte, error) {
+	return e.client.Range(ctx, start, end)
+}
+
+func (e *EtcdAdapter) Latest(ctx context.Context, start, end string) (string, []byte, error) {
+	return e.client.Latest(ctx, start, end)
+}
+
+func (e *EtcdAdapter) Atomic(ctx context.Context, fn func(tx database.Tx) error) error {
+	return e.client.STM(ctx, func(stm concurrency.STM) error {
+		return fn(stmTx{stm})
+	})
+}
+
+// stmTx adapts an etcd STM to database.Tx. Values are never stored empty, so
+// an empty read means the key is missing.
+type stmTx struct {
+	stm concurrency.STM
+}
+
+func (t stmTx) Get(key string) []byte {
+	value := t.stm.Get(key)
+	if value == "" {
+		return nil
+	}
+	return []byte(value)
+}
+
+func (t stmTx) Put(key string, value []byte) {
+	t.stm.Put(key, string(value))
+}
+
+func (t stmTx) Delete(key string) {
+	t.stm.Del(key)
+}
+
 func (e *EtcdAdapter) Post(ctx context.Context, key string, value []byte) error {
 	return e.client.Post(ctx, key, value)
 }
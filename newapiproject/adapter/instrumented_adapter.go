@@ -0,0 +1,146 @@
+package adapter
+
+import (
+	"context"
+	"time"
+
+	"newapiprojet/database"
+	"newapiprojet/metrics"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	maxRetries   = 2
+	retryBackoff = 50 * time.Millisecond
+)
+
+// InstrumentedAdapter decorates a database.Database with Prometheus metrics
+// and retries single-key operations that failed with a transient etcd error.
+type InstrumentedAdapter struct {
+	next database.Database
+}
+
+func NewInstrumentedAdapter(next database.Database) database.Database {
+	return &InstrumentedAdapter{next: next}
+}
+
+func (a *InstrumentedAdapter) Get(ctx context.Context, key string) ([]byte, error) {
+	var value []byte
+	err := a.observe(ctx, "get", key, func() error {
+		var err error
+		value, err = a.next.Get(ctx, key)
+		return err
+	})
+	return value, err
+}
+
+func (a *InstrumentedAdapter) Put(ctx context.Context, key string, value []byte) error {
+	return a.observe(ctx, "put", key, func() error {
+		return a.next.Put(ctx, key, value)
+	})
+}
+
+func (a *InstrumentedAdapter) Delete(ctx context.Context, key string) error {
+	return a.observe(ctx, "delete", key, func() error {
+		return a.next.Delete(ctx, key)
+	})
+}
+
+func (a *InstrumentedAdapter) Post(ctx context.Context, key string, value []byte) error {
+	return a.observe(ctx, "post", key, func() error {
+		return a.next.Post(ctx, key, value)
+	})
+}
+
+func (a *InstrumentedAdapter) List(ctx context.Context, prefix string) (map[string][]byte, error) {
+	var values map[string][]byte
+	err := a.observe(ctx, "list", prefix, func() error {
+		var err error
+		values, err = a.next.List(ctx, prefix)
+		return err
+	})
+	return values, err
+}
+
+func (a *InstrumentedAdapter) Range(ctx context.Context, start, end string) (map[string][]byte, error) {
+	var values map[string][]byte
+	err := a.observe(ctx, "range", start, func() error {
+		var err error
+		values, err = a.next.Range(ctx, start, end)
+		return err
+	})
+	return values, err
+}
+
+func (a *InstrumentedAdapter) Latest(ctx context.Context, start, end string) (string, []byte, error) {
+	var key string
+	var value []byte
+	err := a.observe(ctx, "latest", start, func() error {
+		var err error
+		key, value, err = a.next.Latest(ctx, start, end)
+		return err
+	})
+	return key, value, err
+}
+
+// Atomic is labelled with the prefix "txn" and never retried: an Unavailable
+// error does not tell whether the commit was applied, and running a posting
+// again could apply it twice.
+func (a *InstrumentedAdapter) Atomic(ctx context.Context, fn func(tx database.Tx) error) error {
+	return a.observeOnce(ctx, "txn", "txn", func() error {
+		return a.next.Atomic(ctx, fn)
+	})
+}
+
+// observe records fn's metrics and retries it on transient errors. Only
+// idempotent operations may be retried.
+func (a *InstrumentedAdapter) observe(ctx context.Context, op, key string, fn func() error) error {
+	return a.run(ctx, op, key, fn, maxRetries)
+}
+
+// observeOnce records fn's metrics without retrying it.
+func (a *InstrumentedAdapter) observeOnce(ctx context.Context, op, key string, fn func() error) error {
+	return a.run(ctx, op, key, fn, 0)
+}
+
+func (a *InstrumentedAdapter) run(ctx context.Context, op, key string, fn func() error, retries int) error {
+	prefix := database.KeyPrefix(key)
+	start := time.Now()
+	defer func() {
+		metrics.EtcdOpDuration.WithLabelValues(op, prefix).Observe(time.Since(start).Seconds())
+	}()
+
+	err := fn()
+	for attempt := 1; attempt <= retries && retryable(err); attempt++ {
+		metrics.EtcdOpRetriesTotal.WithLabelValues(op, prefix).Inc()
+		if err = sleep(ctx, retryBackoff*time.Duration(attempt)); err != nil {
+			break
+		}
+		err = fn()
+	}
+
+	if err != nil {
+		metrics.EtcdOpErrorsTotal.WithLabelValues(op, prefix).Inc()
+	}
+	return err
+}
+
+// retryable reports whether err is a transient etcd failure (no leader, node
+// unreachable) rather than a deadline or a rejected request.
+func retryable(err error) bool {
+	return status.Code(err) == codes.Unavailable
+}
+
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
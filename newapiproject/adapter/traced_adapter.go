@@ -0,0 +1,115 @@
+package adapter
+
+import (
+	"context"
+
+	"newapiprojet/database"
+	"newapiprojet/tracing"
+
+	"go.opentelemetry.io/otel/attribute"
+	semconv "go.opentelemetry.io/otel/semconv/v1.21.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// TracedAdapter decorates a database.Database with one client span per etcd
+// call. Only the key prefix is recorded, since keys can contain usernames.
+type TracedAdapter struct {
+	next database.Database
+}
+
+func NewTracedAdapter(next database.Database) database.Database {
+	return &TracedAdapter{next: next}
+}
+
+func (a *TracedAdapter) Get(ctx context.Context, key string) ([]byte, error) {
+	ctx, span := startSpan(ctx, "get", key)
+	defer span.End()
+
+	value, err := a.next.Get(ctx, key)
+	span.SetAttributes(attribute.Bool("etcd.found", value != nil))
+	tracing.RecordError(span, err)
+	return value, err
+}
+
+func (a *TracedAdapter) Put(ctx context.Context, key string, value []byte) error {
+	ctx, span := startSpan(ctx, "put", key)
+	defer span.End()
+
+	err := a.next.Put(ctx, key, value)
+	tracing.RecordError(span, err)
+	return err
+}
+
+func (a *TracedAdapter) Delete(ctx context.Context, key string) error {
+	ctx, span := startSpan(ctx, "delete", key)
+	defer span.End()
+
+	err := a.next.Delete(ctx, key)
+	tracing.RecordError(span, err)
+	return err
+}
+
+func (a *TracedAdapter) Post(ctx context.Context, key string, value []byte) error {
+	ctx, span := startSpan(ctx, "post", key)
+	defer span.End()
+
+	err := a.next.Post(ctx, key, value)
+	tracing.RecordError(span, err)
+	return err
+}
+
+func (a *TracedAdapter) List(ctx context.Context, prefix string) (map[string][]byte, error) {
+	ctx, span := startSpan(ctx, "list", prefix)
+	defer span.End()
+
+	values, err := a.next.List(ctx, prefix)
+	span.SetAttributes(attribute.Int("etcd.count", len(values)))
+	tracing.RecordError(span, err)
+	return values, err
+}
+
+func (a *TracedAdapter) Range(ctx context.Context, start, end string) (map[string][]byte, error) {
+	ctx, span := startSpan(ctx, "range", start)
+	defer span.End()
+
+	values, err := a.next.Range(ctx, start, end)
+	span.SetAttributes(attribute.Int("etcd.count", len(values)))
+	tracing.RecordError(span, err)
+	return values, err
+}
+
+func (a *TracedAdapter) Latest(ctx context.Context, start, end string) (string, []byte, error) {
+	ctx, span := startSpan(ctx, "latest", start)
+	defer span.End()
+
+	key, value, err := a.next.Latest(ctx, start, end)
+	span.SetAttributes(attribute.Bool("etcd.found", value != nil))
+	tracing.RecordError(span, err)
+	return key, value, err
+}
+
+// Atomic records one span for the whole transaction, including its retries.
+func (a *TracedAdapter) Atomic(ctx context.Context, fn func(tx database.Tx) error) error {
+	ctx, span := startSpan(ctx, "txn", "")
+	defer span.End()
+
+	attempts := 0
+	err := a.next.Atomic(ctx, func(tx database.Tx) error {
+		attempts++
+		return fn(tx)
+	})
+	span.SetAttributes(attribute.Int("etcd.txn_attempts", attempts))
+	tracing.RecordError(span, err)
+	return err
+}
+
+func startSpan(ctx context.Context, op, key string) (context.Context, trace.Span) {
+	return tracing.Start(ctx, "etcd "+op,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			semconv.DBSystemKey.String("etcd"),
+			semconv.DBOperation(op),
+			attribute.String("etcd.key_prefix", database.KeyPrefix(key)),
+		),
+	)
+}
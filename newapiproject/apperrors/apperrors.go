@@ -0,0 +1,221 @@
+package apperrors
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"newapiprojet/i18n"
+	"strings"
+)
+
+// Code is a stable, machine-readable error identifier. Codes are part of the
+// public API: clients switch on them, so existing values must not change.
+type Code string
+
+const (
+	InvalidRequest        Code = "INVALID_REQUEST"
+	ValidationFailed      Code = "VALIDATION_FAILED"
+	Unauthorized          Code = "UNAUTHORIZED"
+	TokenInvalid          Code = "TOKEN_INVALID"
+	InvalidCredentials    Code = "INVALID_CREDENTIALS"
+	Forbidden             Code = "FORBIDDEN"
+	UserNotFound          Code = "USER_NOT_FOUND"
+	AccountNotFound       Code = "ACCOUNT_NOT_FOUND"
+	AccountUnavailable    Code = "ACCOUNT_UNAVAILABLE"
+	StandingOrderNotFound Code = "STANDING_ORDER_NOT_FOUND"
+	TransactionNotFound   Code = "TRANSACTION_NOT_FOUND"
+	HoldNotFound          Code = "HOLD_NOT_FOUND"
+	CardNotFound          Code = "CARD_NOT_FOUND"
+	CardUnavailable       Code = "CARD_UNAVAILABLE"
+	PINLocked             Code = "PIN_LOCKED"
+	TerminalNotFound      Code = "TERMINAL_NOT_FOUND"
+	TerminalUnauthorized  Code = "TERMINAL_UNAUTHORIZED"
+	CannotDispense        Code = "CANNOT_DISPENSE"
+	UsernameTaken         Code = "USERNAME_TAKEN"
+	Conflict              Code = "CONFLICT"
+	InvalidPhoneNumber    Code = "INVALID_PHONE_NUMBER"
+	InvalidPINFormat      Code = "INVALID_PIN_FORMAT"
+	PINMismatch           Code = "PIN_MISMATCH"
+	InvalidAmount         Code = "INVALID_AMOUNT"
+	CurrencyMismatch      Code = "CURRENCY_MISMATCH"
+	RateUnavailable       Code = "RATE_UNAVAILABLE"
+	InvalidRate           Code = "INVALID_RATE"
+	InsufficientFunds     Code = "INSUFFICIENT_FUNDS"
+	LimitExceeded         Code = "LIMIT_EXCEEDED"
+	RateLimited           Code = "RATE_LIMITED"
+	Timeout               Code = "TIMEOUT"
+	Internal              Code = "INTERNAL_ERROR"
+)
+
+// statuses maps each code to its HTTP status. Titles live in the i18n
+// catalog, keyed by code.
+var statuses = map[Code]int{
+	InvalidRequest:        http.StatusBadRequest,
+	ValidationFailed:      http.StatusBadRequest,
+	Unauthorized:          http.StatusUnauthorized,
+	TokenInvalid:          http.StatusForbidden,
+	InvalidCredentials:    http.StatusUnauthorized,
+	Forbidden:             http.StatusForbidden,
+	UserNotFound:          http.StatusNotFound,
+	AccountNotFound:       http.StatusNotFound,
+	AccountUnavailable:    http.StatusConflict,
+	StandingOrderNotFound: http.StatusNotFound,
+	TransactionNotFound:   http.StatusNotFound,
+	HoldNotFound:          http.StatusNotFound,
+	CardNotFound:          http.StatusNotFound,
+	CardUnavailable:       http.StatusForbidden,
+	PINLocked:             http.StatusForbidden,
+	TerminalNotFound:      http.StatusNotFound,
+	TerminalUnauthorized:  http.StatusUnauthorized,
+	CannotDispense:        http.StatusUnprocessableEntity,
+	UsernameTaken:         http.StatusConflict,
+	Conflict:              http.StatusConflict,
+	InvalidPhoneNumber:    http.StatusBadRequest,
+	InvalidPINFormat:      http.StatusBadRequest,
+	PINMismatch:           http.StatusBadRequest,
+	InvalidAmount:         http.StatusBadRequest,
+	CurrencyMismatch:      http.StatusBadRequest,
+	RateUnavailable:       http.StatusUnprocessableEntity,
+	InvalidRate:           http.StatusBadRequest,
+	InsufficientFunds:     http.StatusBadRequest,
+	LimitExceeded:         http.StatusUnprocessableEntity,
+	RateLimited:           http.StatusTooManyRequests,
+	Timeout:               http.StatusGatewayTimeout,
+	Internal:              http.StatusInternalServerError,
+}
+
+// Error is an API error. Detail is an i18n message key shown to clients; Err
+// is the internal cause, which is logged but never rendered.
+type Error struct {
+	Code   Code
+	Detail string
+	Fields []FieldError
+	Err    error
+}
+
+// FieldError describes one request field that failed validation. Rule is the
+// validation tag that failed and Param its argument, if any.
+type FieldError struct {
+	Field   string `json:"field"`
+	Rule    string `json:"rule"`
+	Param   string `json:"param,omitempty"`
+	Message string `json:"message"`
+}
+
+func New(code Code) *Error {
+	return &Error{Code: code}
+}
+
+// Wrap attaches an internal cause to code.
+func Wrap(code Code, err error) *Error {
+	return &Error{Code: code, Err: err}
+}
+
+// WithDetail returns a copy of e with a client-facing explanation, given as
+// an i18n message key.
+func (e *Error) WithDetail(detail string) *Error {
+	copied := *e
+	copied.Detail = detail
+	return &copied
+}
+
+// WithFields returns a copy of e carrying per-field validation errors.
+func (e *Error) WithFields(fields []FieldError) *Error {
+	copied := *e
+	copied.Fields = fields
+	return &copied
+}
+
+func (e *Error) Error() string {
+	msg := string(e.Code)
+	if e.Detail != "" {
+		msg += ": " + e.Detail
+	}
+	for _, f := range e.Fields {
+		msg += " [" + f.Field + ":" + f.Rule + "]"
+	}
+	if e.Err != nil {
+		msg += ": " + e.Err.Error()
+	}
+	return msg
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+func (e *Error) Status() int {
+	return statuses[e.Code]
+}
+
+// Title returns the localized, human-readable summary for e.Code.
+func (e *Error) Title(lang i18n.Language) string {
+	return i18n.T(lang, string(e.Code))
+}
+
+// From converts any error into an *Error. Unknown errors become Internal, and
+// errors caused by an expired request deadline become Timeout.
+func From(err error) *Error {
+	var appErr *Error
+	if !errors.As(err, &appErr) {
+		appErr = Wrap(Internal, err)
+	}
+	if appErr.Code == Internal && errors.Is(err, context.DeadlineExceeded) {
+		return Wrap(Timeout, err)
+	}
+	if _, ok := statuses[appErr.Code]; !ok {
+		return Wrap(Internal, err)
+	}
+	return appErr
+}
+
+// Problem is an RFC 7807 problem details body.
+type Problem struct {
+	Type      string       `json:"type"`
+	Title     string       `json:"title"`
+	Status    int          `json:"status"`
+	Detail    string       `json:"detail,omitempty"`
+	Instance  string       `json:"instance,omitempty"`
+	Code      Code         `json:"code"`
+	RequestID string       `json:"request_id,omitempty"`
+	Errors    []FieldError `json:"errors,omitempty"`
+}
+
+const ProblemContentType = "application/problem+json"
+
+// Problem renders e for a client, localizing title and detail into lang.
+func (e *Error) Problem(instance, requestID string, lang i18n.Language) Problem {
+	detail := ""
+	if e.Detail != "" {
+		detail = i18n.T(lang, e.Detail)
+	}
+	return Problem{
+		Type:      "urn:newapiproject:problem:" + strings.ToLower(strings.ReplaceAll(string(e.Code), "_", "-")),
+		Title:     e.Title(lang),
+		Status:    e.Status(),
+		Detail:    detail,
+		Instance:  instance,
+		Code:      e.Code,
+		RequestID: requestID,
+		Errors:    e.localizedFields(lang),
+	}
+}
+
+// localizedFields fills in each field's message from the i18n catalog, keyed
+// as "VALIDATION_<RULE>" with "{param}" replaced by the rule's argument.
+func (e *Error) localizedFields(lang i18n.Language) []FieldError {
+	if len(e.Fields) == 0 {
+		return nil
+	}
+	fields := make([]FieldError, len(e.Fields))
+	for i, f := range e.Fields {
+		key := "VALIDATION_" + strings.ToUpper(f.Rule)
+		msg := i18n.T(lang, key)
+		if msg == key {
+			msg = i18n.T(lang, i18n.MsgValidationInvalid)
+		}
+		f.Message = strings.ReplaceAll(msg, "{param}", f.Param)
+		fields[i] = f
+	}
+	return fields
+}
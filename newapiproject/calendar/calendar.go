@@ -0,0 +1,86 @@
+package calendar
+
+import (
+	"fmt"
+	"time"
+)
+
+// DayLayout formats a business day, e.g. "2024-07-22".
+const DayLayout = "2006-01-02"
+
+// Calendar decides which business day a moment belongs to. Business days
+// end at a daily cut-off in the bank's time zone; anything after the
+// cut-off counts towards the next day, so with a 17:30 cut-off a withdrawal
+// at 18:00 on the 1st is booked on the 2nd.
+type Calendar struct {
+	loc    *time.Location
+	cutoff time.Duration
+}
+
+// New returns a calendar for timezone (an IANA name, default UTC) with days
+// ending at cutoff ("HH:MM", default "00:00", i.e. midnight).
+func New(timezone, cutoff string) (*Calendar, error) {
+	loc := time.UTC
+	if timezone != "" {
+		var err error
+		loc, err = time.LoadLocation(timezone)
+		if err != nil {
+			return nil, fmt.Errorf("loading business time zone: %w", err)
+		}
+	}
+
+	var d time.Duration
+	if cutoff != "" {
+		t, err := time.Parse("15:04", cutoff)
+		if err != nil {
+			return nil, fmt.Errorf("parsing business day cut-off %q: %w", cutoff, err)
+		}
+		d = time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute
+	}
+	return &Calendar{loc: loc, cutoff: d}, nil
+}
+
+// Location returns the bank's time zone.
+func (c *Calendar) Location() *time.Location {
+	return c.loc
+}
+
+// Day returns the business day t falls on, formatted with DayLayout.
+func (c *Calendar) Day(t time.Time) string {
+	return c.date(t).Format(DayLayout)
+}
+
+// NextBoundary returns the cut-off that ends the business day containing t.
+func (c *Calendar) NextBoundary(t time.Time) time.Time {
+	return c.cutoffOn(c.date(t))
+}
+
+// date returns local midnight of the business day containing t.
+func (c *Calendar) date(t time.Time) time.Time {
+	local := t.In(c.loc)
+	midnight := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, c.loc)
+	if c.cutoff > 0 && !local.Before(c.cutoffOn(midnight)) {
+		midnight = midnight.AddDate(0, 0, 1)
+	}
+	return midnight
+}
+
+// cutoffOn returns the cut-off that ends the business day dated midnight.
+// With a midnight cut-off that is the start of the following calendar day.
+func (c *Calendar) cutoffOn(midnight time.Time) time.Time {
+	if c.cutoff == 0 {
+		return midnight.AddDate(0, 0, 1)
+	}
+	h, m := int(c.cutoff/time.Hour), int(c.cutoff%time.Hour/time.Minute)
+	return time.Date(midnight.Year(), midnight.Month(), midnight.Day(), h, m, 0, 0, c.loc)
+}
+
+// AddDays returns the business day n days after day, both formatted with
+// DayLayout.
+func AddDays(day string, n int) (string, error) {
+	t, err := time.Parse(DayLayout, day)
+	if err != nil {
+		return "", fmt.Errorf("parsing business day %q: %w", day, err)
+	}
+	return t.AddDate(0, 0, n).Format(DayLayout), nil
+}
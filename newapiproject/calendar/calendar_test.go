@@ -0,0 +1,129 @@
+package calendar
+
+import (
+	"testing"
+	"time"
+	_ "time/tzdata"
+)
+
+func TestDay(t *testing.T) {
+	tests := []struct {
+		name     string
+		timezone string
+		cutoff   string
+		at       time.Time
+		day      string
+		boundary time.Time
+	}{
+		{
+			name:     "midnight cut-off",
+			at:       time.Date(2026, 10, 19, 23, 59, 0, 0, time.UTC),
+			day:      "2026-10-19",
+			boundary: time.Date(2026, 10, 20, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:     "before cut-off",
+			cutoff:   "17:30",
+			at:       time.Date(2026, 10, 19, 17, 29, 59, 0, time.UTC),
+			day:      "2026-10-19",
+			boundary: time.Date(2026, 10, 19, 17, 30, 0, 0, time.UTC),
+		},
+		{
+			name:     "at cut-off",
+			cutoff:   "17:30",
+			at:       time.Date(2026, 10, 19, 17, 30, 0, 0, time.UTC),
+			day:      "2026-10-20",
+			boundary: time.Date(2026, 10, 20, 17, 30, 0, 0, time.UTC),
+		},
+		{
+			name:     "after cut-off at month end",
+			cutoff:   "17:30",
+			at:       time.Date(2026, 10, 31, 18, 0, 0, 0, time.UTC),
+			day:      "2026-11-01",
+			boundary: time.Date(2026, 11, 1, 17, 30, 0, 0, time.UTC),
+		},
+		{
+			// 15:00 UTC is 18:00 in Istanbul, past the local cut-off.
+			name:     "cut-off in bank time zone",
+			timezone: "Europe/Istanbul",
+			cutoff:   "17:30",
+			at:       time.Date(2026, 10, 19, 15, 0, 0, 0, time.UTC),
+			day:      "2026-10-20",
+			boundary: time.Date(2026, 10, 20, 14, 30, 0, 0, time.UTC),
+		},
+		{
+			// 22:00 UTC is already the next calendar day in Istanbul.
+			name:     "local date differs from UTC",
+			timezone: "Europe/Istanbul",
+			at:       time.Date(2026, 10, 19, 22, 0, 0, 0, time.UTC),
+			day:      "2026-10-20",
+			boundary: time.Date(2026, 10, 20, 21, 0, 0, 0, time.UTC),
+		},
+		{
+			// Clocks go forward on 2026-03-29, so that day's cut-off is
+			// an hour earlier in UTC than the day before.
+			name:     "daylight saving change",
+			timezone: "Europe/Berlin",
+			cutoff:   "17:30",
+			at:       time.Date(2026, 3, 28, 17, 0, 0, 0, time.UTC),
+			day:      "2026-03-29",
+			boundary: time.Date(2026, 3, 29, 15, 30, 0, 0, time.UTC),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cal, err := New(tt.timezone, tt.cutoff)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := cal.Day(tt.at); got != tt.day {
+				t.Errorf("Day(%v) = %s, want %s", tt.at, got, tt.day)
+			}
+			if got := cal.NextBoundary(tt.at); !got.Equal(tt.boundary) {
+				t.Errorf("NextBoundary(%v) = %v, want %v", tt.at, got, tt.boundary)
+			}
+			// The boundary itself starts the following business day.
+			if next := cal.Day(tt.boundary); next == tt.day {
+				t.Errorf("Day(NextBoundary) = %s, want the day after %s", next, tt.day)
+			}
+		})
+	}
+}
+
+func TestNewErrors(t *testing.T) {
+	tests := []struct{ timezone, cutoff string }{
+		{"Mars/Olympus_Mons", ""},
+		{"", "25:00"},
+		{"", "5pm"},
+	}
+	for _, tt := range tests {
+		if _, err := New(tt.timezone, tt.cutoff); err == nil {
+			t.Errorf("New(%q, %q) succeeded, want an error", tt.timezone, tt.cutoff)
+		}
+	}
+}
+
+func TestAddDays(t *testing.T) {
+	tests := []struct {
+		day  string
+		n    int
+		want string
+	}{
+		{"2026-10-19", 1, "2026-10-20"},
+		{"2026-12-31", 1, "2027-01-01"},
+		{"2028-03-01", -1, "2028-02-29"},
+		{"2026-10-19", 0, "2026-10-19"},
+	}
+	for _, tt := range tests {
+		got, err := AddDays(tt.day, tt.n)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != tt.want {
+			t.Errorf("AddDays(%s, %d) = %s, want %s", tt.day, tt.n, got, tt.want)
+		}
+	}
+	if _, err := AddDays("19/10/2026", 1); err == nil {
+		t.Error("AddDays with a malformed day succeeded, want an error")
+	}
+}
@@ -0,0 +1,71 @@
+package cards
+
+import (
+	"crypto/rand"
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+// IIN is the issuer identification number that starts every card number
+// this bank issues.
+const IIN = "979215"
+
+// panLength is the length of issued card numbers.
+const panLength = 16
+
+// ValidPAN reports whether pan is 12 to 19 digits with a correct Luhn
+// check digit.
+func ValidPAN(pan string) bool {
+	if len(pan) < 12 || len(pan) > 19 {
+		return false
+	}
+	for _, r := range pan {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return checkDigit(pan[:len(pan)-1]) == pan[len(pan)-1]
+}
+
+// checkDigit returns the Luhn check digit to append to digits.
+func checkDigit(digits string) byte {
+	sum := 0
+	for i := len(digits) - 1; i >= 0; i-- {
+		d := int(digits[i] - '0')
+		// Starting from the rightmost digit, every other digit is doubled,
+		// since the check digit will follow it.
+		if (len(digits)-1-i)%2 == 0 {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+	}
+	return byte('0' + (10-sum%10)%10)
+}
+
+// NewPAN returns a random card number under IIN with a valid check digit.
+func NewPAN() (string, error) {
+	var b strings.Builder
+	b.WriteString(IIN)
+	for b.Len() < panLength-1 {
+		n, err := rand.Int(rand.Reader, big.NewInt(10))
+		if err != nil {
+			return "", fmt.Errorf("generating card number: %w", err)
+		}
+		b.WriteByte(byte('0' + n.Int64()))
+	}
+	digits := b.String()
+	return digits + string(checkDigit(digits)), nil
+}
+
+// Mask hides all but the first six and last four digits of pan, the most
+// that may be shown or logged.
+func Mask(pan string) string {
+	if len(pan) <= 10 {
+		return strings.Repeat("*", len(pan))
+	}
+	return pan[:6] + strings.Repeat("*", len(pan)-10) + pan[len(pan)-4:]
+}
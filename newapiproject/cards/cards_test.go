@@ -0,0 +1,77 @@
+package cards
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidPAN(t *testing.T) {
+	tests := []struct {
+		pan   string
+		valid bool
+	}{
+		{"4111111111111111", true},
+		{"5555555555554444", true},
+		{"378282246310005", true},
+		{"6011111111111117", true},
+		{"4222222222222", true},
+		{"000000000000", true},
+		{"4111111111111112", false},
+		{"5555555555554440", false},
+		{"4111 1111 1111 1111", false},
+		{"411111111111111a", false},
+		{"-411111111111111", false},
+		{"79927398713", false},          // valid check digit, too short
+		{"41111111111111111111", false}, // too long
+		{"", false},
+	}
+	for _, tt := range tests {
+		if got := ValidPAN(tt.pan); got != tt.valid {
+			t.Errorf("ValidPAN(%q) = %v, want %v", tt.pan, got, tt.valid)
+		}
+	}
+}
+
+func TestCheckDigit(t *testing.T) {
+	tests := []struct {
+		digits string
+		want   byte
+	}{
+		{"7992739871", '3'},
+		{"411111111111111", '1'},
+		{"37828224631000", '5'},
+		{"0", '0'},
+		{"9", '1'},
+	}
+	for _, tt := range tests {
+		if got := checkDigit(tt.digits); got != tt.want {
+			t.Errorf("checkDigit(%q) = %c, want %c", tt.digits, got, tt.want)
+		}
+	}
+}
+
+func TestNewPAN(t *testing.T) {
+	for range 100 {
+		pan, err := NewPAN()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(pan) != panLength || !strings.HasPrefix(pan, IIN) || !ValidPAN(pan) {
+			t.Fatalf("NewPAN() = %q, want a valid %d-digit number under %s", pan, panLength, IIN)
+		}
+	}
+}
+
+func TestMask(t *testing.T) {
+	tests := []struct{ pan, want string }{
+		{"4111111111111111", "411111******1111"},
+		{"378282246310005", "378282*****0005"},
+		{"1234567890", "**********"},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if got := Mask(tt.pan); got != tt.want {
+			t.Errorf("Mask(%q) = %q, want %q", tt.pan, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,241 @@
+package cassettes
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"slices"
+
+	"newapiprojet/models"
+	"newapiprojet/money"
+)
+
+// Strategy decides which notes an ATM dispenses when several combinations
+// make the amount.
+type Strategy string
+
+const (
+	// FewestNotes dispenses as few notes as possible.
+	FewestNotes Strategy = "fewest_notes"
+	// Balanced takes each note from the fullest cassette that still lets
+	// the rest of the amount be made, so cassettes run empty at about the
+	// same time.
+	Balanced Strategy = "balanced"
+)
+
+// DefaultMaxNotes is how many notes an ATM dispenses at once when the
+// configuration does not say.
+const DefaultMaxNotes = 40
+
+var (
+	// ErrCannotDispense means the notes in the cassettes cannot make the
+	// amount exactly.
+	ErrCannotDispense = errors.New("amount cannot be made from the notes in the cassettes")
+	// ErrTooManyNotes means the amount can be made, but only with more
+	// notes than the ATM dispenses at once.
+	ErrTooManyNotes = errors.New("amount needs more notes than the ATM dispenses at once")
+)
+
+// Plan returns the notes to dispense for amount from cassettes, largest
+// denomination first, using at most maxNotes notes (DefaultMaxNotes if not
+// positive). Only cassettes in the amount's currency are used.
+func Plan(amount money.Money, cassettes []models.Notes, maxNotes int, strategy Strategy) ([]models.Notes, error) {
+	if !amount.IsPositive() {
+		return nil, fmt.Errorf("dispensing %s: amount must be positive", amount)
+	}
+
+	var denoms []models.Notes
+	for _, c := range cassettes {
+		if c.Denomination.Currency() == amount.Currency() && c.Denomination.IsPositive() && c.Count > 0 {
+			denoms = append(denoms, c)
+		}
+	}
+	slices.SortFunc(denoms, func(a, b models.Notes) int {
+		return cmp.Compare(b.Denomination.Minor(), a.Denomination.Minor())
+	})
+	values := make([]int64, len(denoms))
+	counts := make([]int, len(denoms))
+	var total int64
+	for i, d := range denoms {
+		values[i], counts[i] = d.Denomination.Minor(), d.Count
+		total += values[i] * int64(d.Count)
+	}
+	if len(denoms) == 0 || amount.Minor() > total {
+		return nil, ErrCannotDispense
+	}
+	if maxNotes <= 0 {
+		maxNotes = DefaultMaxNotes
+	}
+	if amount.Minor() > values[0]*int64(maxNotes) {
+		return nil, ErrTooManyNotes
+	}
+
+	var plan []int
+	switch strategy {
+	case Balanced:
+		plan = balanced(amount.Minor(), values, counts, maxNotes)
+	default:
+		plan = fewest(amount.Minor(), values, counts, maxNotes)
+	}
+	if plan == nil {
+		if fewest(amount.Minor(), values, counts, -1) != nil {
+			return nil, ErrTooManyNotes
+		}
+		return nil, ErrCannotDispense
+	}
+
+	var notes []models.Notes
+	for i, n := range plan {
+		if n > 0 {
+			notes = append(notes, models.Notes{Denomination: denoms[i].Denomination, Count: n})
+		}
+	}
+	return notes, nil
+}
+
+// Take returns cassettes with notes removed. Every note must be in the
+// cassettes.
+func Take(cassettes, notes []models.Notes) ([]models.Notes, error) {
+	left := slices.Clone(cassettes)
+	for _, n := range notes {
+		i := slices.IndexFunc(left, func(c models.Notes) bool { return c.Denomination == n.Denomination })
+		if i < 0 || left[i].Count < n.Count {
+			return nil, fmt.Errorf("taking %d notes of %s: %w", n.Count, n.Denomination, ErrCannotDispense)
+		}
+		left[i].Count -= n.Count
+	}
+	return left, nil
+}
+
+// Return returns cassettes with notes put back, as when an ATM failed to pay
+// them out. Denominations the cassettes do not hold yet are added.
+func Return(cassettes, notes []models.Notes) []models.Notes {
+	back := slices.Clone(cassettes)
+	for _, n := range notes {
+		i := slices.IndexFunc(back, func(c models.Notes) bool { return c.Denomination == n.Denomination })
+		if i < 0 {
+			back = append(back, n)
+			continue
+		}
+		back[i].Count += n.Count
+	}
+	return back
+}
+
+// Total returns the value of the notes in currency.
+func Total(notes []models.Notes, currency money.Currency) money.Money {
+	var minor int64
+	for _, n := range notes {
+		if n.Denomination.Currency() == currency {
+			minor += n.Denomination.Minor() * int64(n.Count)
+		}
+	}
+	return money.New(minor, currency)
+}
+
+// fewest returns how many notes of each denomination make amount with as
+// few notes as possible, or nil if it cannot be made with at most maxNotes
+// notes. A negative maxNotes means no limit. values must be positive and in
+// descending order.
+func fewest(amount int64, values []int64, counts []int, maxNotes int) []int {
+	plan := make([]int, len(values))
+	if amount == 0 {
+		return plan
+	}
+	var unit int64
+	for _, v := range values {
+		unit = gcd(unit, v)
+	}
+	if unit == 0 || amount%unit != 0 || maxNotes == 0 {
+		return nil
+	}
+	if maxNotes > 0 && amount > values[0]*int64(maxNotes) {
+		return nil
+	}
+
+	// best[v] is the fewest notes that make v units with the denominations
+	// seen so far; take[i][v] is how many of denomination i that uses.
+	const unreachable = -1
+	size := amount / unit
+	best := make([]int, size+1)
+	for v := range best[1:] {
+		best[v+1] = unreachable
+	}
+	take := make([][]int, len(values))
+	for i, value := range values {
+		step := value / unit
+		limit := counts[i]
+		if maxNotes > 0 {
+			limit = min(limit, maxNotes)
+		}
+		next := slices.Clone(best)
+		take[i] = make([]int, size+1)
+		for v := int64(1); v <= size; v++ {
+			for k := 1; k <= limit && int64(k)*step <= v; k++ {
+				prev := best[v-int64(k)*step]
+				if prev == unreachable {
+					continue
+				}
+				if next[v] == unreachable || prev+k < next[v] {
+					next[v], take[i][v] = prev+k, k
+				}
+			}
+		}
+		best = next
+	}
+	if best[size] == unreachable || (maxNotes > 0 && best[size] > maxNotes) {
+		return nil
+	}
+
+	for i, v := len(values)-1, size; i >= 0; i-- {
+		plan[i] = take[i][v]
+		v -= int64(plan[i]) * (values[i] / unit)
+	}
+	return plan
+}
+
+// balanced picks notes one at a time from the cassette with the most notes
+// left, larger denominations first on a tie, as long as the rest of the
+// amount can still be made. It returns nil if amount cannot be made with at
+// most maxNotes notes.
+func balanced(amount int64, values []int64, counts []int, maxNotes int) []int {
+	if fewest(amount, values, counts, maxNotes) == nil {
+		return nil
+	}
+	left := slices.Clone(counts)
+	plan := make([]int, len(values))
+	order := make([]int, len(values))
+	for n := 0; amount > 0; n++ {
+		for i := range order {
+			order[i] = i
+		}
+		slices.SortStableFunc(order, func(a, b int) int {
+			return cmp.Compare(left[b], left[a])
+		})
+		picked := false
+		for _, i := range order {
+			if left[i] == 0 || values[i] > amount {
+				continue
+			}
+			left[i]--
+			if fewest(amount-values[i], values, left, maxNotes-n-1) != nil {
+				plan[i]++
+				amount -= values[i]
+				picked = true
+				break
+			}
+			left[i]++
+		}
+		if !picked {
+			return nil
+		}
+	}
+	return plan
+}
+
+func gcd(a, b int64) int64 {
+	for b != 0 {
+		a, b = b, a%b
+	}
+	return a
+}
@@ -0,0 +1,229 @@
+package cassettes
+
+import (
+	"errors"
+	"slices"
+	"testing"
+
+	"newapiprojet/models"
+	"newapiprojet/money"
+)
+
+// notes builds cassettes from pairs of whole lira and note counts.
+func notes(pairs ...int) []models.Notes {
+	var n []models.Notes
+	for i := 0; i < len(pairs); i += 2 {
+		n = append(n, models.Notes{Denomination: money.New(int64(pairs[i])*100, money.TRY), Count: pairs[i+1]})
+	}
+	return n
+}
+
+func lira(major int64) money.Money {
+	return money.New(major*100, money.TRY)
+}
+
+func TestPlan(t *testing.T) {
+	tests := []struct {
+		name      string
+		amount    money.Money
+		cassettes []models.Notes
+		maxNotes  int
+		strategy  Strategy
+		want      []models.Notes
+		err       error
+	}{
+		{
+			name:      "fewest notes",
+			amount:    lira(370),
+			cassettes: notes(20, 50, 200, 10, 50, 10, 100, 10),
+			strategy:  FewestNotes,
+			want:      notes(200, 1, 100, 1, 50, 1, 20, 1),
+		},
+		{
+			// Greedy would take the 50 and be left with 10.
+			name:      "fewest where greedy fails",
+			amount:    lira(60),
+			cassettes: notes(50, 10, 20, 10),
+			strategy:  FewestNotes,
+			want:      notes(20, 3),
+		},
+		{
+			name:      "fewest when large notes run out",
+			amount:    lira(500),
+			cassettes: notes(200, 1, 100, 1, 50, 10),
+			strategy:  FewestNotes,
+			want:      notes(200, 1, 100, 1, 50, 4),
+		},
+		{
+			name:      "unknown strategy means fewest notes",
+			amount:    lira(300),
+			cassettes: notes(100, 10, 200, 10),
+			strategy:  Strategy("cheapest"),
+			want:      notes(200, 1, 100, 1),
+		},
+		{
+			name:      "balanced takes from the fullest cassette",
+			amount:    lira(200),
+			cassettes: notes(100, 10, 50, 40),
+			strategy:  Balanced,
+			want:      notes(50, 4),
+		},
+		{
+			// Leaves two notes in each cassette; fewest notes would
+			// leave 100 x 0 and 50 x 4.
+			name:      "balanced evens out cassettes",
+			amount:    lira(300),
+			cassettes: notes(100, 3, 50, 4),
+			strategy:  Balanced,
+			want:      notes(100, 2, 50, 2),
+		},
+		{
+			name:      "balanced keeps the amount exact",
+			amount:    lira(60),
+			cassettes: notes(50, 30, 20, 10),
+			strategy:  Balanced,
+			want:      notes(20, 3),
+		},
+		{
+			// 20000 TRY is two million minor units; reducing by the
+			// greatest common note value keeps the search to 100 steps.
+			name:      "gcd unit",
+			amount:    lira(20000),
+			cassettes: notes(200, 100, 100, 100),
+			maxNotes:  100,
+			strategy:  FewestNotes,
+			want:      notes(200, 100),
+		},
+		{
+			name:      "other currencies are ignored",
+			amount:    lira(100),
+			cassettes: append(notes(50, 2), models.Notes{Denomination: money.New(10000, money.USD), Count: 5}),
+			strategy:  FewestNotes,
+			want:      notes(50, 2),
+		},
+		{
+			name:      "not a multiple of any note",
+			amount:    lira(125),
+			cassettes: notes(200, 10, 50, 10),
+			strategy:  FewestNotes,
+			err:       ErrCannotDispense,
+		},
+		{
+			name:      "notes in stock cannot make it",
+			amount:    lira(80),
+			cassettes: notes(50, 1, 20, 2),
+			strategy:  FewestNotes,
+			err:       ErrCannotDispense,
+		},
+		{
+			name:      "more than the cassettes hold",
+			amount:    lira(1000),
+			cassettes: notes(100, 5, 50, 5),
+			strategy:  Balanced,
+			err:       ErrCannotDispense,
+		},
+		{
+			name:      "over the default cap even in the largest note",
+			amount:    lira(1000),
+			cassettes: notes(20, 100),
+			strategy:  FewestNotes,
+			err:       ErrTooManyNotes,
+		},
+		{
+			// 50 + 10 x 20 makes it, but that is 11 notes.
+			name:      "possible only above the cap",
+			amount:    lira(250),
+			cassettes: notes(50, 1, 20, 20),
+			maxNotes:  10,
+			strategy:  FewestNotes,
+			err:       ErrTooManyNotes,
+		},
+		{
+			name:      "possible only above the cap, balanced",
+			amount:    lira(250),
+			cassettes: notes(50, 1, 20, 20),
+			maxNotes:  10,
+			strategy:  Balanced,
+			err:       ErrTooManyNotes,
+		},
+		{
+			name:      "exactly at the cap",
+			amount:    lira(250),
+			cassettes: notes(50, 1, 20, 20),
+			maxNotes:  11,
+			strategy:  FewestNotes,
+			want:      notes(50, 1, 20, 10),
+		},
+		{
+			name:     "no cassettes",
+			amount:   lira(100),
+			strategy: FewestNotes,
+			err:      ErrCannotDispense,
+		},
+		{
+			name:      "empty cassettes",
+			amount:    lira(100),
+			cassettes: notes(100, 0, 50, 0),
+			strategy:  Balanced,
+			err:       ErrCannotDispense,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Plan(tt.amount, tt.cassettes, tt.maxNotes, tt.strategy)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("Plan error = %v, want %v", err, tt.err)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("Plan = %v, want %v", got, tt.want)
+			}
+			if err == nil {
+				if total := Total(got, money.TRY); total != tt.amount {
+					t.Errorf("Plan dispenses %v, want %v", total, tt.amount)
+				}
+				if _, err := Take(tt.cassettes, got); err != nil {
+					t.Errorf("Plan dispenses notes the cassettes do not hold: %v", err)
+				}
+			}
+		})
+	}
+}
+
+func TestPlanRejectsNonPositive(t *testing.T) {
+	for _, amount := range []money.Money{lira(0), lira(-100)} {
+		_, err := Plan(amount, notes(100, 10), 0, FewestNotes)
+		if err == nil || errors.Is(err, ErrCannotDispense) || errors.Is(err, ErrTooManyNotes) {
+			t.Errorf("Plan(%v) error = %v, want an invalid amount error", amount, err)
+		}
+	}
+}
+
+func TestTakeAndReturn(t *testing.T) {
+	cassettes := notes(200, 10, 100, 5)
+
+	left, err := Take(cassettes, notes(200, 3, 100, 5))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := notes(200, 7, 100, 0); !slices.Equal(left, want) {
+		t.Errorf("Take = %v, want %v", left, want)
+	}
+	if want := notes(200, 10, 100, 5); !slices.Equal(cassettes, want) {
+		t.Errorf("Take modified its input: %v", cassettes)
+	}
+
+	if _, err := Take(cassettes, notes(100, 6)); !errors.Is(err, ErrCannotDispense) {
+		t.Errorf("Take more than held: error = %v, want %v", err, ErrCannotDispense)
+	}
+	if _, err := Take(cassettes, notes(50, 1)); !errors.Is(err, ErrCannotDispense) {
+		t.Errorf("Take unknown denomination: error = %v, want %v", err, ErrCannotDispense)
+	}
+
+	back := Return(left, notes(200, 3, 50, 2))
+	if want := notes(200, 10, 100, 0, 50, 2); !slices.Equal(back, want) {
+		t.Errorf("Return = %v, want %v", back, want)
+	}
+	if want := notes(200, 7, 100, 0); !slices.Equal(left, want) {
+		t.Errorf("Return modified its input: %v", left)
+	}
+}
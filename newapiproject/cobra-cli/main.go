@@ -1,8 +1,60 @@
 package main
 
-import "github.com/spf13/cobra"
+import (
+	"context"
+	"fmt"
+	"newapiprojet/adapter"
+	"newapiprojet/etcd"
+	"newapiprojet/handlers"
+	"newapiprojet/models"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+	clientv3 "go.etcd.io/etcd/client/v3"
+)
 
 func main() {
 	var rootCmd = &cobra.Command{Use: "myapp"}
-	rootCmd.Execute()
+
+	var endpoints []string
+	rootCmd.PersistentFlags().StringSliceVar(&endpoints, "endpoints", []string{
+		"http://etcd1:2379",
+		"http://etcd2:2378",
+		"http://etcd3:2377",
+	}, "etcd endpoints")
+
+	rootCmd.AddCommand(&cobra.Command{
+		Use:   "grant-role <username> <customer|teller|admin>",
+		Short: "Set the role of a registered user",
+		Long:  "Set the role of a registered user directly in etcd. Use it to appoint the first admin; admins can then change roles through PUT /v1/admin/users/{id}/role.",
+		Args:  cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			role := models.Role(args[1])
+			switch role {
+			case models.RoleCustomer, models.RoleTeller, models.RoleAdmin:
+			default:
+				return fmt.Errorf("unknown role %q", args[1])
+			}
+
+			client, err := etcd.NewEtcdClient(clientv3.Config{Endpoints: endpoints, DialTimeout: 5 * time.Second})
+			if err != nil {
+				return fmt.Errorf("connecting to etcd: %w", err)
+			}
+			defer client.Close()
+
+			ctx, cancel := context.WithTimeout(cmd.Context(), 10*time.Second)
+			defer cancel()
+			user, err := handlers.GrantRole(ctx, adapter.NewEtcdAdapter(client), args[0], role)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "%s (%s) is now %s\n", user.Username, user.ID, user.Role)
+			return nil
+		},
+	})
+
+	if err := rootCmd.Execute(); err != nil {
+		os.Exit(1)
+	}
 }
@@ -4,17 +4,98 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
+	"strconv"
+	"strings"
 	"sync"
 	"time"
 )
 
 type Config struct {
-	APIPort           int       `json:"api_port"`
-	MaxRequestsPerDay int       `json:"max_requests_per_day"`
-	RemainingRequests int       `json:"remaining_requests"`
-	LastReset         time.Time `json:"last_reset"`
+	APIPort           int            `json:"api_port"`
+	MaxRequestsPerDay int            `json:"max_requests_per_day"`
+	RemainingRequests int            `json:"remaining_requests"`
+	LastReset         time.Time      `json:"last_reset"`
+	RequestTimeoutMs  int            `json:"request_timeout_ms"`
+	RouteTimeoutsMs   map[string]int `json:"route_timeouts_ms"`
+	LogLevel          string         `json:"log_level"`
+	TracingExporter   string         `json:"tracing_exporter"`
+	TracingFile       string         `json:"tracing_file"`
+	// MaxTransactionAmount caps a single deposit or withdrawal; 0 disables the cap.
+	MaxTransactionAmount int `json:"max_transaction_amount"`
+	// Unversioned legacy routes report these in their Deprecation and Sunset headers.
+	LegacyDeprecatedAt time.Time `json:"legacy_deprecated_at"`
+	LegacySunsetAt     time.Time `json:"legacy_sunset_at"`
+	// ExchangeRatesFile is loaded into etcd at startup when set.
+	ExchangeRatesFile string `json:"exchange_rates_file"`
+	// Business days end at BusinessDayCutoff ("HH:MM") in BusinessTimezone;
+	// daily limits reset there. Both default to midnight UTC.
+	BusinessTimezone  string `json:"business_timezone"`
+	BusinessDayCutoff string `json:"business_day_cutoff"`
+	// WithdrawalLimits apply to accounts without limits of their own.
+	WithdrawalLimits WithdrawalLimits `json:"withdrawal_limits"`
+	// InterestTiers maps a currency code to its yearly rate table. The
+	// interest job looks for business days to accrue every
+	// InterestRunEveryMinutes; 0 disables it.
+	InterestTiers           map[string][]InterestTier `json:"interest_tiers"`
+	InterestRunEveryMinutes int                       `json:"interest_run_every_minutes"`
+	// The standing order scheduler looks for due orders every
+	// StandingOrdersRunEverySeconds; 0 disables it. Orders that could not
+	// be covered are retried after StandingOrderRetryMinutes.
+	StandingOrdersRunEverySeconds int `json:"standing_orders_run_every_seconds"`
+	StandingOrderRetryMinutes     int `json:"standing_order_retry_minutes"`
+	// Active accounts without customer activity for DormancyDays become
+	// dormant. The dormancy job checks every DormancyRunEveryMinutes; 0
+	// disables it.
+	DormancyDays            int `json:"dormancy_days"`
+	DormancyRunEveryMinutes int `json:"dormancy_run_every_minutes"`
+	// Holds expire when their etcd lease runs out; as a fallback, expired
+	// holds are also swept every HoldExpiryRunEverySeconds (0 disables it).
+	HoldExpiryRunEverySeconds int `json:"hold_expiry_run_every_seconds"`
+	// RequireTerminalAuth rejects account and card requests that do not
+	// come from a registered ATM. When false, terminals are still
+	// authenticated when they identify themselves.
+	RequireTerminalAuth bool `json:"require_terminal_auth"`
+
+	// Cash dispensing: "fewest_notes" or "balanced", and the most notes an
+	// ATM pays out at once (default 40).
+	DispenseStrategy    string `json:"dispense_strategy"`
+	MaxNotesPerDispense int    `json:"max_notes_per_dispense"`
+
+	// Terminal monitoring: a terminal is offline once it has not sent a
+	// heartbeat for terminal_heartbeat_ttl_seconds (default 90). The
+	// monitor raises alerts for offline terminals and for cassettes
+	// holding less than low_cash_thresholds, in major units by currency.
+	TerminalHeartbeatTTLSeconds    int              `json:"terminal_heartbeat_ttl_seconds"`
+	TerminalMonitorRunEverySeconds int              `json:"terminal_monitor_run_every_seconds"`
+	LowCashThresholds              map[string]int64 `json:"low_cash_thresholds"`
+
+	// The API is served over TLS when TLSCertFile and TLSKeyFile are set.
+	// Client certificates issued by a CA in TLSClientCAFile are then
+	// verified, and a terminal registered with its certificate's
+	// fingerprint need not sign its requests.
+	TLSCertFile     string `json:"tls_cert_file"`
+	TLSKeyFile      string `json:"tls_key_file"`
+	TLSClientCAFile string `json:"tls_client_ca_file"`
+}
+
+// InterestTier pays RateBps a year on the part of a balance above From
+// whole units, up to the next tier.
+type InterestTier struct {
+	From    int64 `json:"from"`
+	RateBps int64 `json:"rate_bps"`
 }
 
+// WithdrawalLimits are in whole units of the account currency; zero
+// disables a limit.
+type WithdrawalLimits struct {
+	PerTransaction int64 `json:"per_transaction"`
+	Daily          int64 `json:"daily"`
+	DailyCount     int   `json:"daily_count"`
+}
+
+// defaultRequestTimeout is used when config.json does not set request_timeout_ms.
+const defaultRequestTimeout = 5 * time.Second
+
 var (
 	instance *Config
 	once     sync.Once
@@ -73,3 +154,36 @@ func (c *Config) DecreaseRequestCount() error {
 	}
 	return fmt.Errorf("daily request limit exceeded")
 }
+
+// RouteTimeout returns the deadline for a route, keyed as "METHOD /path" using
+// the gin route pattern (e.g. "POST /account/withdrawal"). A key without an API
+// version prefix applies to every version of the route. Routes without an
+// entry in route_timeouts_ms fall back to request_timeout_ms.
+func (c *Config) RouteTimeout(method, path string) time.Duration {
+	for _, p := range []string{path, stripVersion(path)} {
+		if ms, ok := c.RouteTimeoutsMs[method+" "+p]; ok && ms > 0 {
+			return time.Duration(ms) * time.Millisecond
+		}
+	}
+	if c.RequestTimeoutMs > 0 {
+		return time.Duration(c.RequestTimeoutMs) * time.Millisecond
+	}
+	return defaultRequestTimeout
+}
+
+// stripVersion removes a leading "/v<N>" segment, so "/v1/account/deposit"
+// becomes "/account/deposit".
+func stripVersion(path string) string {
+	rest, ok := strings.CutPrefix(path, "/v")
+	if !ok {
+		return path
+	}
+	i := strings.IndexByte(rest, '/')
+	if i <= 0 {
+		return path
+	}
+	if _, err := strconv.Atoi(rest[:i]); err != nil {
+		return path
+	}
+	return rest[i:]
+}
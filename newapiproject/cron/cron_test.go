@@ -0,0 +1,98 @@
+package cron
+
+import (
+	"errors"
+	"testing"
+	"time"
+	_ "time/tzdata"
+)
+
+func TestParseErrors(t *testing.T) {
+	tests := []string{
+		"",
+		"* * * *",
+		"* * * * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * 32 * *",
+		"* * * 13 *",
+		"* * * * 8",
+		"5-1 * * * *",
+		"*/0 * * * *",
+		"*/x * * * *",
+		"a * * * *",
+		"1-b * * * *",
+		"@annually",
+	}
+	for _, expr := range tests {
+		if _, err := Parse(expr); !errors.Is(err, ErrInvalidSchedule) {
+			t.Errorf("Parse(%q) error = %v, want %v", expr, err, ErrInvalidSchedule)
+		}
+	}
+}
+
+func TestNext(t *testing.T) {
+	// 2026-10-19 is a Monday.
+	monday := time.Date(2026, 10, 19, 10, 17, 42, 0, time.UTC)
+	tests := []struct {
+		expr string
+		from time.Time
+		want time.Time
+	}{
+		{"* * * * *", monday, time.Date(2026, 10, 19, 10, 18, 0, 0, time.UTC)},
+		{"*/15 * * * *", monday, time.Date(2026, 10, 19, 10, 30, 0, 0, time.UTC)},
+		{"17 * * * *", monday, time.Date(2026, 10, 19, 11, 17, 0, 0, time.UTC)},
+		{"0 9-17/4 * * *", monday, time.Date(2026, 10, 19, 13, 0, 0, 0, time.UTC)},
+		{"30 8,20 * * *", monday, time.Date(2026, 10, 19, 20, 30, 0, 0, time.UTC)},
+		{"@hourly", monday, time.Date(2026, 10, 19, 11, 0, 0, 0, time.UTC)},
+		{"@daily", monday, time.Date(2026, 10, 20, 0, 0, 0, 0, time.UTC)},
+		{"@weekly", monday, time.Date(2026, 10, 25, 0, 0, 0, 0, time.UTC)},
+		{"@monthly", monday, time.Date(2026, 11, 1, 0, 0, 0, 0, time.UTC)},
+		{"@YEARLY", monday, time.Date(2027, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{"0 0 * * 7", monday, time.Date(2026, 10, 25, 0, 0, 0, 0, time.UTC)},
+		{"0 0 * * 1-5", time.Date(2026, 10, 23, 12, 0, 0, 0, time.UTC), time.Date(2026, 10, 26, 0, 0, 0, 0, time.UTC)},
+		{"0 0 31 * *", monday, time.Date(2026, 10, 31, 0, 0, 0, 0, time.UTC)},
+		{"0 0 31 * *", time.Date(2026, 10, 31, 0, 0, 0, 0, time.UTC), time.Date(2026, 12, 31, 0, 0, 0, 0, time.UTC)},
+		{"0 0 29 2 *", monday, time.Date(2028, 2, 29, 0, 0, 0, 0, time.UTC)},
+		// With both day fields restricted either one is enough: the 1st,
+		// or any Friday.
+		{"0 12 1 * 5", monday, time.Date(2026, 10, 23, 12, 0, 0, 0, time.UTC)},
+		{"0 12 1 * 5", time.Date(2026, 10, 30, 12, 0, 0, 0, time.UTC), time.Date(2026, 11, 1, 12, 0, 0, 0, time.UTC)},
+		// As in Vixie cron, a day field starting with "*" does not take
+		// part in the either-day rule, even with a step: Mondays only.
+		{"0 0 */10 * 1", time.Date(2026, 10, 20, 0, 0, 0, 0, time.UTC), time.Date(2026, 10, 26, 0, 0, 0, 0, time.UTC)},
+		// Exactly on a due minute, the next one is returned.
+		{"0 0 * * *", time.Date(2026, 10, 19, 0, 0, 0, 0, time.UTC), time.Date(2026, 10, 20, 0, 0, 0, 0, time.UTC)},
+		{"0 0 30 2 *", monday, time.Time{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expr+" from "+tt.from.Format(time.DateTime), func(t *testing.T) {
+			s, err := Parse(tt.expr)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := s.Next(tt.from); !got.Equal(tt.want) {
+				t.Errorf("Next = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNextInLocation(t *testing.T) {
+	istanbul, err := time.LoadLocation("Europe/Istanbul")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, err := Parse("0 9 * * *")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 07:00 UTC is already 10:00 in Istanbul.
+	from := time.Date(2026, 10, 19, 7, 0, 0, 0, time.UTC).In(istanbul)
+	want := time.Date(2026, 10, 20, 9, 0, 0, 0, istanbul)
+	got := s.Next(from)
+	if !got.Equal(want) || got.Location() != istanbul {
+		t.Errorf("Next = %v, want %v", got, want)
+	}
+}
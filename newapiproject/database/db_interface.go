@@ -2,6 +2,8 @@ package database
 
 import (
 	"context"
+	"strings"
+	"time"
 )
 
 type Database interface {
@@ -9,4 +11,53 @@ type Database interface {
 	Put(ctx context.Context, key string, value []byte) error
 	Delete(ctx context.Context, key string) error
 	Post(ctx context.Context, key string, value []byte) error
+	// List returns every key under prefix with its value.
+	List(ctx context.Context, prefix string) (map[string][]byte, error)
+	// Range returns every key from start up to, but not including, end
+	// with its value.
+	Range(ctx context.Context, start, end string) (map[string][]byte, error)
+	// Latest returns the greatest key from start up to, but not including,
+	// end with its value, or an empty key and nil if there is none.
+	Latest(ctx context.Context, start, end string) (string, []byte, error)
+	// Atomic runs fn as a serializable read-modify-write transaction. fn is
+	// re-run if a key it read changes before commit, so it must not have side
+	// effects outside tx. An error from fn aborts the transaction and is
+	// returned as is.
+	Atomic(ctx context.Context, fn func(tx Tx) error) error
+}
+
+// Tx is the view of the store inside Atomic. Writes are applied together
+// when fn returns nil.
+type Tx interface {
+	// Get returns nil when the key does not exist.
+	Get(key string) []byte
+	Put(key string, value []byte)
+	Delete(key string)
+}
+
+// Leases stores keys that delete themselves once their time to live runs
+// out. etcd.EtcdClient implements it with etcd leases.
+type Leases interface {
+	// PutWithTTL stores key until ttl has passed, rounded up to a second.
+	PutWithTTL(ctx context.Context, key string, value []byte, ttl time.Duration) error
+	// CreateWithTTL is PutWithTTL for a key that must not exist yet. It
+	// reports false, and stores nothing, if it does.
+	CreateWithTTL(ctx context.Context, key string, value []byte, ttl time.Duration) (bool, error)
+	// KeepAlive renews lease, to which key is bound, for its time to live.
+	// If lease is zero or has run out, key is stored again under a new
+	// lease with ttl. It returns the lease key is bound to.
+	KeepAlive(ctx context.Context, key string, value []byte, lease int64, ttl time.Duration) (int64, error)
+	// WatchDeletes calls fn with every key under prefix that is deleted,
+	// whether its lease ran out or it was deleted outright, until ctx is
+	// done.
+	WatchDeletes(ctx context.Context, prefix string, fn func(key string)) error
+}
+
+// KeyPrefix returns the first segment of an etcd key ("accounts" for
+// "accounts/<id>"), which is safe to use as a metric label or span attribute.
+func KeyPrefix(key string) string {
+	if i := strings.IndexByte(key, '/'); i >= 0 {
+		return key[:i]
+	}
+	return key
 }
@@ -0,0 +1,145 @@
+// Package memdb keeps a database.Database in memory, for tests.
+package memdb
+
+import (
+	"context"
+	"maps"
+	"strings"
+	"sync"
+	"time"
+
+	"newapiprojet/database"
+)
+
+// Tx is a database.Tx over a plain map. Code that only needs a transaction
+// can be tested with a Tx{} on its own.
+type Tx map[string][]byte
+
+func (t Tx) Get(key string) []byte        { return t[key] }
+func (t Tx) Put(key string, value []byte) { t[key] = value }
+func (t Tx) Delete(key string)            { delete(t, key) }
+
+// DB implements database.Database and database.Leases. Atomic runs fn on a
+// copy of the data and keeps the copy if fn succeeds, so concurrent
+// transactions are not isolated from each other. Leases never run out.
+type DB struct {
+	mu     sync.Mutex
+	data   Tx
+	leases int64
+}
+
+var (
+	_ database.Database = (*DB)(nil)
+	_ database.Leases   = (*DB)(nil)
+)
+
+// New returns an empty DB.
+func New() *DB {
+	return &DB{data: Tx{}}
+}
+
+func (d *DB) Get(_ context.Context, key string) ([]byte, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.data.Get(key), nil
+}
+
+func (d *DB) Put(_ context.Context, key string, value []byte) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.data.Put(key, value)
+	return nil
+}
+
+func (d *DB) Post(ctx context.Context, key string, value []byte) error {
+	return d.Put(ctx, key, value)
+}
+
+func (d *DB) Delete(_ context.Context, key string) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.data.Delete(key)
+	return nil
+}
+
+func (d *DB) List(_ context.Context, prefix string) (map[string][]byte, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	values := map[string][]byte{}
+	for key, value := range d.data {
+		if strings.HasPrefix(key, prefix) {
+			values[key] = value
+		}
+	}
+	return values, nil
+}
+
+func (d *DB) Range(_ context.Context, start, end string) (map[string][]byte, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	values := map[string][]byte{}
+	for key, value := range d.data {
+		if key >= start && key < end {
+			values[key] = value
+		}
+	}
+	return values, nil
+}
+
+func (d *DB) Latest(_ context.Context, start, end string) (string, []byte, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	latest := ""
+	for key := range d.data {
+		if key >= start && key < end && key > latest {
+			latest = key
+		}
+	}
+	if latest == "" {
+		return "", nil, nil
+	}
+	return latest, d.data[latest], nil
+}
+
+func (d *DB) Atomic(_ context.Context, fn func(tx database.Tx) error) error {
+	d.mu.Lock()
+	tx := maps.Clone(d.data)
+	d.mu.Unlock()
+	if err := fn(tx); err != nil {
+		return err
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.data = tx
+	return nil
+}
+
+func (d *DB) PutWithTTL(ctx context.Context, key string, value []byte, _ time.Duration) error {
+	return d.Put(ctx, key, value)
+}
+
+func (d *DB) CreateWithTTL(_ context.Context, key string, value []byte, _ time.Duration) (bool, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.data.Get(key) != nil {
+		return false, nil
+	}
+	d.data.Put(key, value)
+	return true, nil
+}
+
+func (d *DB) KeepAlive(_ context.Context, key string, value []byte, lease int64, _ time.Duration) (int64, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if lease == 0 {
+		d.leases++
+		lease = d.leases
+	}
+	d.data.Put(key, value)
+	return lease, nil
+}
+
+func (d *DB) WatchDeletes(ctx context.Context, _ string, _ func(key string)) error {
+	<-ctx.Done()
+	return ctx.Err()
+}
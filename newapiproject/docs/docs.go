@@ -15,9 +15,9 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/account/{id}": {
+        "/account/balance/{accountID}": {
             "get": {
-                "description": "Get an account by ID",
+                "description": "Get the current (ledger) balance and the available balance, which includes any unused overdraft and excludes money held by active holds. Balance inquiry fees are charged first, to active accounts only; when the account cannot cover them they are waived.",
                 "consumes": [
                     "application/json"
                 ],
@@ -27,63 +27,72 @@ const docTemplate = `{
                 "tags": [
                     "Account"
                 ],
-                "summary": "Get an account by ID",
+                "summary": "Get the account balance",
                 "parameters": [
                     {
-                        "type": "integer",
+                        "type": "string",
                         "description": "Account ID",
-                        "name": "id",
+                        "name": "accountID",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "foreign at another bank's ATM",
+                        "name": "X-ATM-Network",
+                        "in": "header"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Account found",
+                        "description": "Balance inquiry successful",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/dto.BalanceResponse"
                         }
                     },
                     "400": {
                         "description": "Bad Request",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/apperrors.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/apperrors.Problem"
                         }
                     },
                     "404": {
                         "description": "Account not found",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/apperrors.Problem"
                         }
                     }
                 }
             }
         },
-        "/balance/{accountNumber}": {
-            "get": {
-                "description": "Get the account balance",
-                "consumes": [
-                    "application/json"
-                ],
+        "/account/deleteacc/{id}": {
+            "delete": {
+                "description": "Close one of the user's accounts. Only active accounts with a zero balance can be closed. The account and its history are kept, with status \"closed\", and no money can move in or out of it afterwards. Standing orders from or to the account are cancelled.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
                     "Account"
                 ],
-                "summary": "Get the account balance",
+                "summary": "Close an account",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "Account Number",
-                        "name": "accountNumber",
+                        "type": "string",
+                        "description": "Account ID",
+                        "name": "id",
                         "in": "path",
                         "required": true
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "Balance inquiry successful",
+                    "204": {
+                        "description": "No Content",
                         "schema": {
                             "type": "string"
                         }
@@ -91,20 +100,39 @@ const docTemplate = `{
                     "400": {
                         "description": "Bad Request",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/apperrors.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/apperrors.Problem"
                         }
                     },
                     "404": {
                         "description": "Account not found",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/apperrors.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Balance is not zero or account is not active",
+                        "schema": {
+                            "$ref": "#/definitions/apperrors.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/apperrors.Problem"
                         }
                     }
                 }
             }
         },
-        "/deleteacc/{accountNumber}": {
-            "delete": {
+        "/account/deposit": {
+            "post": {
+                "description": "Deposit money into an account. Amounts in another currency are converted at the current exchange rate.",
                 "consumes": [
                     "application/json"
                 ],
@@ -114,32 +142,61 @@ const docTemplate = `{
                 "tags": [
                     "Account"
                 ],
-                "summary": "Delete an account",
+                "summary": "Deposit money into an account",
+                "parameters": [
+                    {
+                        "description": "Deposit details",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.DepositRequest"
+                        }
+                    }
+                ],
                 "responses": {
-                    "204": {
-                        "description": "No Content",
+                    "200": {
+                        "description": "Deposit successful",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/dto.TransactionResponse"
                         }
                     },
                     "400": {
                         "description": "Bad Request",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/apperrors.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/apperrors.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Account not found",
+                        "schema": {
+                            "$ref": "#/definitions/apperrors.Problem"
+                        }
+                    },
+                    "422": {
+                        "description": "No exchange rate",
+                        "schema": {
+                            "$ref": "#/definitions/apperrors.Problem"
                         }
                     },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/apperrors.Problem"
                         }
                     }
                 }
             }
         },
-        "/deleteuser/{id}": {
-            "delete": {
-                "description": "Delete a user",
+        "/account/holds": {
+            "post": {
+                "description": "Reserve an amount of the account's available balance before the final amount is known, for example before dispensing cash. The hold counts against the withdrawal limits and lowers the available balance, but the balance itself only changes when the hold is captured. Holds not captured or released expire after expires_in_seconds (15 minutes by default) and the money becomes available again.",
                 "consumes": [
                     "application/json"
                 ],
@@ -147,100 +204,122 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "User"
+                    "Account"
                 ],
-                "summary": "Delete a user",
+                "summary": "Place a hold",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "User ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "description": "Hold details",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.HoldRequest"
+                        }
                     }
                 ],
                 "responses": {
-                    "204": {
-                        "description": "No Content",
+                    "201": {
+                        "description": "Hold placed",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/dto.HoldResponse"
                         }
                     },
                     "400": {
-                        "description": "Bad Request",
+                        "description": "Bad Request or insufficient funds",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/apperrors.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/apperrors.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Account not found",
+                        "schema": {
+                            "$ref": "#/definitions/apperrors.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Account unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/apperrors.Problem"
+                        }
+                    },
+                    "422": {
+                        "description": "Withdrawal limit exceeded",
+                        "schema": {
+                            "$ref": "#/definitions/apperrors.Problem"
                         }
                     },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/apperrors.Problem"
                         }
                     }
                 }
             }
         },
-        "/deposit": {
-            "post": {
-                "description": "Deposit money into an account",
-                "consumes": [
-                    "application/json"
-                ],
+        "/account/holds/{id}": {
+            "get": {
+                "description": "Get a hold and its account's current and available balance.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
                     "Account"
                 ],
-                "summary": "Deposit money into an account",
+                "summary": "Get a hold",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "Account ID",
-                        "name": "accountID",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "type": "integer",
-                        "description": "Deposit Amount",
-                        "name": "depositAmount",
+                        "type": "string",
+                        "description": "Hold ID",
+                        "name": "id",
                         "in": "path",
                         "required": true
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Deposit successful",
+                        "description": "Hold",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/dto.HoldResponse"
                         }
                     },
                     "400": {
                         "description": "Bad Request",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/apperrors.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/apperrors.Problem"
                         }
                     },
                     "404": {
-                        "description": "Account not found",
+                        "description": "Hold not found",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/apperrors.Problem"
                         }
                     },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/apperrors.Problem"
                         }
                     }
                 }
             }
         },
-        "/login": {
+        "/account/holds/{id}/capture": {
             "post": {
-                "description": "Login to the application",
+                "description": "Take the final amount of a hold from the account as a withdrawal. The amount may be less than the hold, for example when only part of the cash was dispensed; the rest becomes available again and no longer counts against the withdrawal limits. Without an amount the whole hold is captured. Withdrawal fees are charged on the captured amount, from the available balance. A hold can only be captured once, and not after it has expired.",
                 "consumes": [
                     "application/json"
                 ],
@@ -248,110 +327,187 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "User"
+                    "Account"
                 ],
-                "summary": "Login to the application",
+                "summary": "Capture a hold",
                 "parameters": [
                     {
-                        "description": "Username",
-                        "name": "username",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "type": "string"
-                        }
+                        "type": "string",
+                        "description": "Hold ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
                     },
                     {
-                        "description": "PIN",
-                        "name": "pin",
+                        "description": "Amount to capture",
+                        "name": "input",
                         "in": "body",
-                        "required": true,
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/dto.CaptureHoldRequest"
                         }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Token",
+                        "description": "Hold captured",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/dto.HoldResponse"
                         }
                     },
                     "400": {
-                        "description": "Bad Request",
+                        "description": "Bad Request, amount above the hold or fees not covered",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/apperrors.Problem"
                         }
                     },
-                    "401": {
-                        "description": "Unauthorized",
+                    "403": {
+                        "description": "Forbidden",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/apperrors.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Hold not found",
+                        "schema": {
+                            "$ref": "#/definitions/apperrors.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Hold already closed or expired",
+                        "schema": {
+                            "$ref": "#/definitions/apperrors.Problem"
+                        }
+                    },
+                    "422": {
+                        "description": "Amount cannot be dispensed",
+                        "schema": {
+                            "$ref": "#/definitions/apperrors.Problem"
                         }
                     },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/apperrors.Problem"
                         }
                     }
                 }
             }
         },
-        "/pin-change/{id}": {
+        "/account/holds/{id}/release": {
             "post": {
-                "description": "Change the user's PIN",
-                "consumes": [
+                "description": "Cancel a hold without taking any money: the held amount becomes available again and no longer counts against the withdrawal limits.",
+                "produces": [
                     "application/json"
                 ],
+                "tags": [
+                    "Account"
+                ],
+                "summary": "Release a hold",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Hold ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Hold released",
+                        "schema": {
+                            "$ref": "#/definitions/dto.HoldResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/apperrors.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/apperrors.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Hold not found",
+                        "schema": {
+                            "$ref": "#/definitions/apperrors.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Hold already closed or expired",
+                        "schema": {
+                            "$ref": "#/definitions/apperrors.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/apperrors.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/account/limits/{accountID}": {
+            "get": {
+                "description": "Show the account's withdrawal limits and how much of them is left for the current business day",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "User"
+                    "Account"
                 ],
-                "summary": "Change the user's PIN",
+                "summary": "Get withdrawal limits",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "User ID",
-                        "name": "id",
+                        "type": "string",
+                        "description": "Account ID",
+                        "name": "accountID",
                         "in": "path",
                         "required": true
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "PIN updated successfully",
+                        "description": "Withdrawal limits",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/dto.WithdrawalLimitsResponse"
                         }
                     },
                     "400": {
                         "description": "Bad Request",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/apperrors.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/apperrors.Problem"
                         }
                     },
                     "404": {
-                        "description": "User not found",
+                        "description": "Account not found",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/apperrors.Problem"
                         }
                     },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/apperrors.Problem"
                         }
                     }
                 }
             }
         },
-        "/register": {
+        "/account/open": {
             "post": {
-                "description": "Register a new user",
+                "description": "Open an additional account for the user in the given currency, starting with a zero balance",
                 "consumes": [
                     "application/json"
                 ],
@@ -359,31 +515,45 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "User"
+                    "Account"
+                ],
+                "summary": "Open an account",
+                "parameters": [
+                    {
+                        "description": "Account currency",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.OpenAccountRequest"
+                        }
+                    }
                 ],
-                "summary": "Register a new user",
                 "responses": {
                     "201": {
-                        "description": "User created successfully"
+                        "description": "Account opened",
+                        "schema": {
+                            "$ref": "#/definitions/dto.AccountResponse"
+                        }
                     },
                     "400": {
                         "description": "Bad Request",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/apperrors.Problem"
                         }
                     },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/apperrors.Problem"
                         }
                     }
                 }
             }
         },
-        "/withdrawal": {
+        "/account/pin-change/{id}": {
             "post": {
-                "description": "Withdraw money from an account",
+                "description": "Change the user's PIN",
                 "consumes": [
                     "application/json"
                 ],
@@ -391,63 +561,3723 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Account"
+                    "User"
                 ],
-                "summary": "Withdraw money from an account",
+                "summary": "Change the user's PIN",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "Account ID",
-                        "name": "accountID",
+                        "type": "string",
+                        "description": "User ID",
+                        "name": "id",
                         "in": "path",
                         "required": true
                     },
                     {
-                        "type": "integer",
-                        "description": "Withdrawal Amount",
-                        "name": "withdrawalAmount",
-                        "in": "path",
-                        "required": true
+                        "description": "Old and New PIN",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.PinChangeRequest"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Withdrawal successful",
+                        "description": "PIN updated successfully",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/dto.MessageResponse"
                         }
                     },
                     "400": {
                         "description": "Bad Request",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/apperrors.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/apperrors.Problem"
                         }
                     },
                     "404": {
-                        "description": "Account not found",
+                        "description": "User not found",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/apperrors.Problem"
                         }
                     },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/apperrors.Problem"
                         }
                     }
                 }
             }
+        },
+        "/account/standing-orders": {
+            "get": {
+                "description": "List the user's standing orders, including paused and cancelled ones, oldest first.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Account"
+                ],
+                "summary": "List standing orders",
+                "responses": {
+                    "200": {
+                        "description": "Standing orders",
+                        "schema": {
+                            "$ref": "#/definitions/dto.StandingOrdersResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/apperrors.Problem"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Schedule a recurring transfer from one of the user's accounts. The schedule is a five-field cron expression (\"minute hour day-of-month month day-of-week\") or a macro such as @monthly, in the bank's time zone. When the source account cannot cover a run, \"retry\" tries again later up to max_retries times and \"skip\" waits for the next scheduled run.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Account"
+                ],
+                "summary": "Create a standing order",
+                "parameters": [
+                    {
+                        "description": "Standing order",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.StandingOrderRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Standing order created",
+                        "schema": {
+                            "$ref": "#/definitions/dto.StandingOrderResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/apperrors.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/apperrors.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Account not found",
+                        "schema": {
+                            "$ref": "#/definitions/apperrors.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/apperrors.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/account/standing-orders/{id}": {
+            "delete": {
+                "description": "Cancel a standing order for good. The order is kept, with status \"cancelled\", so its history stays visible.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Account"
+                ],
+                "summary": "Cancel a standing order",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Standing order ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Standing order cancelled",
+                        "schema": {
+                            "$ref": "#/definitions/dto.StandingOrderResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/apperrors.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Standing order not found",
+                        "schema": {
+                            "$ref": "#/definitions/apperrors.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/apperrors.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/account/standing-orders/{id}/pause": {
+            "post": {
+                "description": "Stop a standing order from running until it is resumed.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Account"
+                ],
+                "summary": "Pause a standing order",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Standing order ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Standing order paused",
+                        "schema": {
+                            "$ref": "#/definitions/dto.StandingOrderResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/apperrors.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Standing order not found",
+                        "schema": {
+                            "$ref": "#/definitions/apperrors.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Standing order is cancelled",
+                        "schema": {
+                            "$ref": "#/definitions/apperrors.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/apperrors.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/account/standing-orders/{id}/resume": {
+            "post": {
+                "description": "Reactivate a paused standing order. Runs missed while it was paused are not made up; it next runs at the first scheduled time from now.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Account"
+                ],
+                "summary": "Resume a standing order",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Standing order ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Standing order resumed",
+                        "schema": {
+                            "$ref": "#/definitions/dto.StandingOrderResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/apperrors.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Standing order not found",
+                        "schema": {
+                            "$ref": "#/definitions/apperrors.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Standing order is cancelled",
+                        "schema": {
+                            "$ref": "#/definitions/apperrors.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/apperrors.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/account/transfer": {
+            "post": {
+                "description": "Move money from one of the user's accounts to any account. Between accounts in different currencies the amount is converted at the current exchange rate. Fees are charged to the source account.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Account"
+                ],
+                "summary": "Transfer money between accounts",
+                "parameters": [
+                    {
+                        "description": "Transfer details",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.TransferRequest"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "foreign at another bank's ATM",
+                        "name": "X-ATM-Network",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Transfer successful",
+                        "schema": {
+                            "$ref": "#/definitions/dto.TransferResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/apperrors.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/apperrors.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Account not found",
+                        "schema": {
+                            "$ref": "#/definitions/apperrors.Problem"
+                        }
+                    },
+                    "422": {
+                        "description": "No exchange rate",
+                        "schema": {
+                            "$ref": "#/definitions/apperrors.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/apperrors.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/account/withdrawal": {
+            "post": {
+                "description": "Withdraw money from an account. Amounts in another currency are converted at the current exchange rate. The balance may go negative up to the account's overdraft limit. The account's withdrawal limits are checked against the amount in the account currency. Fees from the account currency's fee schedule are charged with the withdrawal. At an ATM the amount must be payable from the notes in its cassettes; the response lists the notes to dispense.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Account"
+                ],
+                "summary": "Withdraw money from an account",
+                "parameters": [
+                    {
+                        "description": "Withdrawal details",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.WithdrawalRequest"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "foreign at another bank's ATM",
+                        "name": "X-ATM-Network",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Withdrawal successful",
+                        "schema": {
+                            "$ref": "#/definitions/dto.TransactionResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/apperrors.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/apperrors.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Account not found",
+                        "schema": {
+                            "$ref": "#/definitions/apperrors.Problem"
+                        }
+                    },
+                    "422": {
+                        "description": "No exchange rate, withdrawal limit exceeded or amount cannot be dispensed",
+                        "schema": {
+                            "$ref": "#/definitions/apperrors.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/apperrors.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/account/{id}": {
+            "get": {
+                "description": "Get an account by ID",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Account"
+                ],
+                "summary": "Get an account by ID",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Account ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Account found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.AccountResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/apperrors.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Account not found",
+                        "schema": {
+                            "$ref": "#/definitions/apperrors.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/fees": {
+            "get": {
+                "description": "List the fee rules of every currency. Admin only.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Fees"
+                ],
+                "summary": "List fee schedules",
+                "responses": {
+                    "200": {
+                        "description": "Fee schedules",
+                        "schema": {
+                            "$ref": "#/definitions/dto.FeeSchedulesResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/apperrors.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/apperrors.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/fees/{currency}": {
+            "put": {
+                "description": "Replace the fee rules for accounts in a currency. Amounts are in that currency. Admin only.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Fees"
+                ],
+                "summary": "Replace a fee schedule",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Currency code",
+                        "name": "currency",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Fee rules",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.FeeScheduleRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Fee schedule saved",
+                        "schema": {
+                            "$ref": "#/definitions/dto.FeeScheduleView"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/apperrors.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/apperrors.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/apperrors.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/rates": {
+            "post": {
+                "description": "Store a rate for a currency pair, effective now or at effective_at. Admin only.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Exchange"
+                ],
+                "summary": "Publish an exchange rate",
+                "parameters": [
+                    {
+                        "description": "Exchange rate",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.ExchangeRateRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Exchange rate saved",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ExchangeRateView"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/apperrors.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/apperrors.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/apperrors.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/terminals": {
+            "get": {
+                "description": "List every registered terminal with whether it is online, when it last sent a heartbeat, the error states it reported, the cash in its cassettes and the currencies it is low on. Admin only.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Admin"
+                ],
+                "summary": "List ATMs",
+                "responses": {
+                    "200": {
+                        "description": "Terminals",
+                        "schema": {
+                            "$ref": "#/definitions/dto.TerminalsResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/apperrors.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/apperrors.Problem"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Register an ATM so that it can call the account routes. The terminal's shared secret is generated and returned only in this response. Admin only.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Admin"
+                ],
+                "summary": "Register an ATM",
+                "parameters": [
+                    {
+                        "description": "Terminal location",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.RegisterTerminalRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Terminal registered",
+                        "schema": {
+                            "$ref": "#/definitions/dto.RegisterTerminalResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/apperrors.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/apperrors.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/apperrors.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/terminals/{id}/decommission": {
+            "post": {
+                "description": "Take an ATM out of service. Its requests are rejected from then on; decommissioning is permanent. Admin only.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Admin"
+                ],
+                "summary": "Decommission an ATM",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Terminal ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Terminal decommissioned",
+                        "schema": {
+                            "$ref": "#/definitions/dto.TerminalResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/apperrors.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/apperrors.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Terminal not found",
+                        "schema": {
+                            "$ref": "#/definitions/apperrors.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/apperrors.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/users/{id}/role": {
+            "put": {
+                "description": "Make a user a customer, teller or admin. The change applies to the user's next request, including with tokens issued before it.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Admin"
+                ],
+                "summary": "Set a user's role",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Role",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.SetRoleRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Role changed",
+                        "schema": {
+                            "$ref": "#/definitions/dto.UserResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/apperrors.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/apperrors.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "User not found",
+                        "schema": {
+                            "$ref": "#/definitions/apperrors.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/apperrors.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/card/login": {
+            "post": {
+                "description": "Sign in at an ATM with the card number and the card holder's PIN. The token lasts five minutes, only works from the same ATM while the card can be used, and only for the card's accounts: balance, limits, withdrawals, deposits, transfers from them and holds. Three wrong PINs in a row block the card; the third is answered with PIN_LOCKED.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Card"
+                ],
+                "summary": "Sign in with a card",
+                "parameters": [
+                    {
+                        "description": "Card number and PIN",
+                        "name": "credentials",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.CardLoginRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Token generated",
+                        "schema": {
+                            "$ref": "#/definitions/dto.CardLoginResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/apperrors.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Unknown card or wrong PIN",
+                        "schema": {
+                            "$ref": "#/definitions/apperrors.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Card blocked or expired, or PIN locked",
+                        "schema": {
+                            "$ref": "#/definitions/apperrors.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/apperrors.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/rates": {
+            "get": {
+                "description": "List the exchange rate in effect for every currency pair",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Exchange"
+                ],
+                "summary": "List current exchange rates",
+                "responses": {
+                    "200": {
+                        "description": "Current rates",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ExchangeRatesResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/apperrors.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/staff/accounts/{id}/limits": {
+            "put": {
+                "description": "Replace an account's withdrawal limits. Amounts are in the account currency and zero disables a limit. Staff only.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Staff"
+                ],
+                "summary": "Set withdrawal limits",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Account ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Withdrawal limits",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.WithdrawalLimitsRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Withdrawal limits saved",
+                        "schema": {
+                            "$ref": "#/definitions/dto.WithdrawalLimitsResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/apperrors.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/apperrors.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Account not found",
+                        "schema": {
+                            "$ref": "#/definitions/apperrors.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/apperrors.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/staff/accounts/{id}/overdraft": {
+            "put": {
+                "description": "Grant, change or remove an account's overdraft. The limit is in the account currency and a zero limit removes the facility. Staff only.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Staff"
+                ],
+                "summary": "Set an account's overdraft",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Account ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Overdraft",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.OverdraftRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Overdraft saved",
+                        "schema": {
+                            "$ref": "#/definitions/dto.AccountResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/apperrors.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/apperrors.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Account not found",
+                        "schema": {
+                            "$ref": "#/definitions/apperrors.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/apperrors.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/staff/accounts/{id}/status": {
+            "put": {
+                "description": "Freeze or unfreeze an account, reactivate a dormant one, or close one with a zero balance. Frozen and dormant accounts can receive money but not pay out. Closed accounts stay closed. Staff only.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Staff"
+                ],
+                "summary": "Change an account's status",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Account ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "New status",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.AccountStatusRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Status changed",
+                        "schema": {
+                            "$ref": "#/definitions/dto.AccountResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/apperrors.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/apperrors.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Account not found",
+                        "schema": {
+                            "$ref": "#/definitions/apperrors.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Status change not allowed",
+                        "schema": {
+                            "$ref": "#/definitions/apperrors.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/apperrors.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/staff/cards": {
+            "post": {
+                "description": "Issue a debit card to a user, linked to one or more of the user's accounts that are not closed. The card number is generated and returned in full only in this response. Staff only.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Staff"
+                ],
+                "summary": "Issue a card",
+                "parameters": [
+                    {
+                        "description": "Card holder and accounts",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.IssueCardRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Card issued",
+                        "schema": {
+                            "$ref": "#/definitions/dto.IssueCardResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/apperrors.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/apperrors.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "User or account not found",
+                        "schema": {
+                            "$ref": "#/definitions/apperrors.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/apperrors.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/staff/cards/{id}/block": {
+            "post": {
+                "description": "Block a card, for example when it is reported lost or stolen, so that it can no longer be used to sign in at an ATM. Blocking is permanent; a new card has to be issued. Staff only.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Staff"
+                ],
+                "summary": "Block a card",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Card ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Reason",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.BlockCardRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Card blocked",
+                        "schema": {
+                            "$ref": "#/definitions/dto.CardResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/apperrors.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/apperrors.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Card not found",
+                        "schema": {
+                            "$ref": "#/definitions/apperrors.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/apperrors.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/staff/deposits/{id}/reversal": {
+            "post": {
+                "description": "Undo a deposit by debiting the credited amount from the account, even beyond its overdraft. The deposit record is kept and linked to the reversal. A transaction can only be reversed once. Staff only.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Staff"
+                ],
+                "summary": "Reverse a deposit",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Deposit ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Reason",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.ReversalRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Deposit reversed",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ReversalResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/apperrors.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/apperrors.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Transaction not found",
+                        "schema": {
+                            "$ref": "#/definitions/apperrors.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Already reversed",
+                        "schema": {
+                            "$ref": "#/definitions/apperrors.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/apperrors.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/staff/reports/dormancy": {
+            "get": {
+                "description": "List the reports of the dormancy job, newest first, up to 30. Each report lists the accounts one run made dormant; runs that found none store no report. Staff only.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Staff"
+                ],
+                "summary": "List dormancy reports",
+                "responses": {
+                    "200": {
+                        "description": "Dormancy reports",
+                        "schema": {
+                            "$ref": "#/definitions/dto.DormancyReportsResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/apperrors.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/apperrors.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/staff/terminals/{id}/cassettes": {
+            "get": {
+                "description": "Show how many notes of each denomination are in a terminal's cassettes. Staff only.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Staff"
+                ],
+                "summary": "Show an ATM's cash",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Terminal ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Cash in the cassettes",
+                        "schema": {
+                            "$ref": "#/definitions/dto.CassettesResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/apperrors.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/apperrors.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Terminal not found",
+                        "schema": {
+                            "$ref": "#/definitions/apperrors.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/apperrors.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/staff/terminals/{id}/loads": {
+            "post": {
+                "description": "Record notes put into a terminal's cassettes. They are added to its cash inventory. Staff only.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Staff"
+                ],
+                "summary": "Record a cassette load",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Terminal ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Notes loaded",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.CashMovementRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Load recorded",
+                        "schema": {
+                            "$ref": "#/definitions/dto.CashMovementResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/apperrors.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/apperrors.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Terminal not found",
+                        "schema": {
+                            "$ref": "#/definitions/apperrors.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/apperrors.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/staff/terminals/{id}/reconciliations": {
+            "get": {
+                "description": "List a terminal's cash reconciliation reports, newest first, up to 30. Staff only.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Staff"
+                ],
+                "summary": "List an ATM's reconciliations",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Terminal ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Reconciliation reports",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ReconciliationsResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/apperrors.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/apperrors.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/apperrors.Problem"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Compare the cash counted in a terminal with what it should hold since its previous reconciliation: the cash counted then, plus loads and accepted deposits, minus unloads and dispensed withdrawals. Withdrawals reversed because the ATM did not pay out are not counted as dispensed. Run at the end of each day; the report is stored and any difference is flagged. Staff only.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Staff"
+                ],
+                "summary": "Reconcile an ATM's cash",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Terminal ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Notes counted",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.ReconcileRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Reconciliation report",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ReconciliationResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/apperrors.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/apperrors.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Terminal not found",
+                        "schema": {
+                            "$ref": "#/definitions/apperrors.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/apperrors.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/staff/terminals/{id}/unloads": {
+            "post": {
+                "description": "Record notes taken out of a terminal's cassettes. They are removed from its cash inventory; a denomination never drops below zero, and any difference shows up in the next reconciliation. Staff only.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Staff"
+                ],
+                "summary": "Record a cassette unload",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Terminal ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Notes unloaded",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.CashMovementRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Unload recorded",
+                        "schema": {
+                            "$ref": "#/definitions/dto.CashMovementResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/apperrors.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/apperrors.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Terminal not found",
+                        "schema": {
+                            "$ref": "#/definitions/apperrors.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/apperrors.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/staff/withdrawals/{id}/reversal": {
+            "post": {
+                "description": "Undo a withdrawal, for example when the ATM failed to dispense, by crediting the debited amount and any fees charged with it back to the account. A withdrawal made on the current business day no longer counts against the daily limits. The withdrawal record is kept and linked to the reversal. A transaction can only be reversed once. Staff only.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Staff"
+                ],
+                "summary": "Reverse a withdrawal",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Withdrawal ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Reason",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.ReversalRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Withdrawal reversed",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ReversalResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/apperrors.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/apperrors.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Transaction not found",
+                        "schema": {
+                            "$ref": "#/definitions/apperrors.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Already reversed",
+                        "schema": {
+                            "$ref": "#/definitions/apperrors.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/apperrors.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/terminal/heartbeat": {
+            "post": {
+                "description": "Called by an ATM every few seconds to stay online, with the error states it is in. The terminal's online key is kept under an etcd lease; once heartbeats stop for ttl_seconds the terminal is offline.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Terminal"
+                ],
+                "summary": "Send a terminal heartbeat",
+                "parameters": [
+                    {
+                        "description": "Error states",
+                        "name": "input",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/dto.HeartbeatRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Heartbeat received",
+                        "schema": {
+                            "$ref": "#/definitions/dto.HeartbeatResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/apperrors.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Terminal authentication failed",
+                        "schema": {
+                            "$ref": "#/definitions/apperrors.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/apperrors.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/user/delete/{id}": {
+            "delete": {
+                "description": "Delete the user. All of the user's accounts must be closed first; closed accounts and their history are kept.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "User"
+                ],
+                "summary": "Delete a user",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/apperrors.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/apperrors.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "User not found",
+                        "schema": {
+                            "$ref": "#/definitions/apperrors.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "User has open accounts",
+                        "schema": {
+                            "$ref": "#/definitions/apperrors.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/apperrors.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/user/login": {
+            "post": {
+                "description": "Login user and generate token",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "User"
+                ],
+                "summary": "Login user and generate token",
+                "parameters": [
+                    {
+                        "description": "User credentials",
+                        "name": "credentials",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.LoginRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Token generated",
+                        "schema": {
+                            "$ref": "#/definitions/dto.LoginResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/apperrors.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/apperrors.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/apperrors.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/user/register": {
+            "post": {
+                "description": "Register a new user with username, first name, last name, phone number, and PIN",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "User"
+                ],
+                "summary": "Register a new user",
+                "parameters": [
+                    {
+                        "description": "User details",
+                        "name": "user",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.RegisterRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "User and Account created successfully",
+                        "schema": {
+                            "$ref": "#/definitions/dto.RegisterResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/apperrors.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Username already exists",
+                        "schema": {
+                            "$ref": "#/definitions/apperrors.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/apperrors.Problem"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "apperrors.Code": {
+            "type": "string",
+            "enum": [
+                "INVALID_REQUEST",
+                "VALIDATION_FAILED",
+                "UNAUTHORIZED",
+                "TOKEN_INVALID",
+                "INVALID_CREDENTIALS",
+                "FORBIDDEN",
+                "USER_NOT_FOUND",
+                "ACCOUNT_NOT_FOUND",
+                "ACCOUNT_UNAVAILABLE",
+                "STANDING_ORDER_NOT_FOUND",
+                "TRANSACTION_NOT_FOUND",
+                "HOLD_NOT_FOUND",
+                "CARD_NOT_FOUND",
+                "CARD_UNAVAILABLE",
+                "PIN_LOCKED",
+                "TERMINAL_NOT_FOUND",
+                "TERMINAL_UNAUTHORIZED",
+                "CANNOT_DISPENSE",
+                "USERNAME_TAKEN",
+                "CONFLICT",
+                "INVALID_PHONE_NUMBER",
+                "INVALID_PIN_FORMAT",
+                "PIN_MISMATCH",
+                "INVALID_AMOUNT",
+                "CURRENCY_MISMATCH",
+                "RATE_UNAVAILABLE",
+                "INVALID_RATE",
+                "INSUFFICIENT_FUNDS",
+                "LIMIT_EXCEEDED",
+                "RATE_LIMITED",
+                "TIMEOUT",
+                "INTERNAL_ERROR"
+            ],
+            "x-enum-varnames": [
+                "InvalidRequest",
+                "ValidationFailed",
+                "Unauthorized",
+                "TokenInvalid",
+                "InvalidCredentials",
+                "Forbidden",
+                "UserNotFound",
+                "AccountNotFound",
+                "AccountUnavailable",
+                "StandingOrderNotFound",
+                "TransactionNotFound",
+                "HoldNotFound",
+                "CardNotFound",
+                "CardUnavailable",
+                "PINLocked",
+                "TerminalNotFound",
+                "TerminalUnauthorized",
+                "CannotDispense",
+                "UsernameTaken",
+                "Conflict",
+                "InvalidPhoneNumber",
+                "InvalidPINFormat",
+                "PINMismatch",
+                "InvalidAmount",
+                "CurrencyMismatch",
+                "RateUnavailable",
+                "InvalidRate",
+                "InsufficientFunds",
+                "LimitExceeded",
+                "RateLimited",
+                "Timeout",
+                "Internal"
+            ]
+        },
+        "apperrors.FieldError": {
+            "type": "object",
+            "properties": {
+                "field": {
+                    "type": "string"
+                },
+                "message": {
+                    "type": "string"
+                },
+                "param": {
+                    "type": "string"
+                },
+                "rule": {
+                    "type": "string"
+                }
+            }
+        },
+        "apperrors.Problem": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "$ref": "#/definitions/apperrors.Code"
+                },
+                "detail": {
+                    "type": "string"
+                },
+                "errors": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/apperrors.FieldError"
+                    }
+                },
+                "instance": {
+                    "type": "string"
+                },
+                "request_id": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "integer"
+                },
+                "title": {
+                    "type": "string"
+                },
+                "type": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.AccountResponse": {
+            "type": "object",
+            "properties": {
+                "account": {
+                    "$ref": "#/definitions/dto.AccountView"
+                }
+            }
+        },
+        "dto.AccountStatusRequest": {
+            "type": "object",
+            "required": [
+                "status"
+            ],
+            "properties": {
+                "reason": {
+                    "type": "string",
+                    "maxLength": 200
+                },
+                "status": {
+                    "type": "string",
+                    "enum": [
+                        "active",
+                        "frozen",
+                        "closed"
+                    ]
+                }
+            }
+        },
+        "dto.AccountView": {
+            "type": "object",
+            "properties": {
+                "balance": {
+                    "$ref": "#/definitions/money.jsonMoney"
+                },
+                "currency": {
+                    "$ref": "#/definitions/money.Currency"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "last_activity_at": {
+                    "type": "string"
+                },
+                "overdraft": {
+                    "$ref": "#/definitions/dto.OverdraftView"
+                },
+                "status": {
+                    "$ref": "#/definitions/models.AccountStatus"
+                },
+                "status_changed_at": {
+                    "type": "string"
+                },
+                "user_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.BalanceResponse": {
+            "type": "object",
+            "properties": {
+                "accountID": {
+                    "type": "string"
+                },
+                "available_balance": {
+                    "$ref": "#/definitions/money.jsonMoney"
+                },
+                "available_balance_formatted": {
+                    "type": "string"
+                },
+                "balance": {
+                    "$ref": "#/definitions/money.jsonMoney"
+                },
+                "balance_formatted": {
+                    "type": "string"
+                },
+                "date": {
+                    "type": "string"
+                },
+                "fees": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.FeeView"
+                    }
+                },
+                "fees_waived": {
+                    "description": "the account could not cover the fee",
+                    "type": "boolean"
+                },
+                "held": {
+                    "description": "active holds, taken off the available balance",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/money.jsonMoney"
+                        }
+                    ]
+                },
+                "ledger_balance": {
+                    "$ref": "#/definitions/money.jsonMoney"
+                },
+                "message": {
+                    "type": "string"
+                },
+                "overdraft": {
+                    "$ref": "#/definitions/dto.OverdraftView"
+                },
+                "status": {
+                    "$ref": "#/definitions/models.AccountStatus"
+                }
+            }
+        },
+        "dto.BlockCardRequest": {
+            "type": "object",
+            "required": [
+                "reason"
+            ],
+            "properties": {
+                "reason": {
+                    "type": "string",
+                    "maxLength": 200
+                }
+            }
+        },
+        "dto.CaptureHoldRequest": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "$ref": "#/definitions/money.jsonMoney"
+                }
+            }
+        },
+        "dto.CardLoginRequest": {
+            "type": "object",
+            "required": [
+                "pan",
+                "pin"
+            ],
+            "properties": {
+                "pan": {
+                    "type": "string"
+                },
+                "pin": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.CardLoginResponse": {
+            "type": "object",
+            "properties": {
+                "account_ids": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "expires_at": {
+                    "type": "string"
+                },
+                "token": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.CardResponse": {
+            "type": "object",
+            "properties": {
+                "card": {
+                    "$ref": "#/definitions/dto.CardView"
+                }
+            }
+        },
+        "dto.CardView": {
+            "type": "object",
+            "properties": {
+                "account_ids": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "block_reason": {
+                    "type": "string"
+                },
+                "blocked_at": {
+                    "type": "string"
+                },
+                "expiry": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "issued_at": {
+                    "type": "string"
+                },
+                "pan": {
+                    "type": "string"
+                },
+                "status": {
+                    "$ref": "#/definitions/models.CardStatus"
+                },
+                "user_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.CashMovementRequest": {
+            "type": "object",
+            "required": [
+                "notes"
+            ],
+            "properties": {
+                "notes": {
+                    "type": "array",
+                    "maxItems": 8,
+                    "minItems": 1,
+                    "items": {
+                        "$ref": "#/definitions/dto.NotesRequest"
+                    }
+                }
+            }
+        },
+        "dto.CashMovementResponse": {
+            "type": "object",
+            "properties": {
+                "cassettes": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.NotesView"
+                    }
+                },
+                "id": {
+                    "type": "string"
+                },
+                "notes": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.NotesView"
+                    }
+                },
+                "recorded_at": {
+                    "type": "string"
+                },
+                "type": {
+                    "$ref": "#/definitions/models.CashMovementType"
+                }
+            }
+        },
+        "dto.CassettesResponse": {
+            "type": "object",
+            "properties": {
+                "cassettes": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.NotesView"
+                    }
+                },
+                "terminal_id": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.ConversionView": {
+            "type": "object",
+            "properties": {
+                "applied_rate": {
+                    "type": "string"
+                },
+                "from": {
+                    "$ref": "#/definitions/money.jsonMoney"
+                },
+                "rate": {
+                    "type": "string"
+                },
+                "spread_bps": {
+                    "type": "integer"
+                },
+                "to": {
+                    "$ref": "#/definitions/money.jsonMoney"
+                }
+            }
+        },
+        "dto.DepositRequest": {
+            "type": "object",
+            "required": [
+                "accountID"
+            ],
+            "properties": {
+                "accountID": {
+                    "type": "string"
+                },
+                "depositAmount": {
+                    "$ref": "#/definitions/money.jsonMoney"
+                }
+            }
+        },
+        "dto.DormancyReportsResponse": {
+            "type": "object",
+            "properties": {
+                "reports": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.DormancyReport"
+                    }
+                }
+            }
+        },
+        "dto.ExchangeRateRequest": {
+            "type": "object",
+            "required": [
+                "base",
+                "quote",
+                "rate"
+            ],
+            "properties": {
+                "base": {
+                    "type": "string"
+                },
+                "effective_at": {
+                    "type": "string"
+                },
+                "quote": {
+                    "type": "string"
+                },
+                "rate": {
+                    "type": "string"
+                },
+                "spread_bps": {
+                    "type": "integer",
+                    "maximum": 5000,
+                    "minimum": 0
+                }
+            }
+        },
+        "dto.ExchangeRateView": {
+            "type": "object",
+            "properties": {
+                "base": {
+                    "$ref": "#/definitions/money.Currency"
+                },
+                "effective_at": {
+                    "type": "string"
+                },
+                "quote": {
+                    "$ref": "#/definitions/money.Currency"
+                },
+                "rate": {
+                    "type": "string"
+                },
+                "spread_bps": {
+                    "type": "integer"
+                }
+            }
+        },
+        "dto.ExchangeRatesResponse": {
+            "type": "object",
+            "properties": {
+                "rates": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.ExchangeRateView"
+                    }
+                }
+            }
+        },
+        "dto.FeeRuleRequest": {
+            "type": "object",
+            "required": [
+                "name",
+                "operation"
+            ],
+            "properties": {
+                "fixed": {
+                    "$ref": "#/definitions/money.jsonMoney"
+                },
+                "foreign_only": {
+                    "type": "boolean"
+                },
+                "free_per_month": {
+                    "type": "integer",
+                    "minimum": 0
+                },
+                "max": {
+                    "$ref": "#/definitions/money.jsonMoney"
+                },
+                "name": {
+                    "type": "string",
+                    "maxLength": 50
+                },
+                "operation": {
+                    "type": "string",
+                    "enum": [
+                        "withdrawal",
+                        "balance_inquiry",
+                        "transfer"
+                    ]
+                },
+                "rate_bps": {
+                    "type": "integer",
+                    "maximum": 10000,
+                    "minimum": 0
+                }
+            }
+        },
+        "dto.FeeRuleView": {
+            "type": "object",
+            "properties": {
+                "fixed": {
+                    "$ref": "#/definitions/money.jsonMoney"
+                },
+                "foreign_only": {
+                    "type": "boolean"
+                },
+                "free_per_month": {
+                    "type": "integer"
+                },
+                "max": {
+                    "$ref": "#/definitions/money.jsonMoney"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "operation": {
+                    "$ref": "#/definitions/models.FeeOperation"
+                },
+                "rate_bps": {
+                    "type": "integer"
+                }
+            }
+        },
+        "dto.FeeScheduleRequest": {
+            "type": "object",
+            "properties": {
+                "rules": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.FeeRuleRequest"
+                    }
+                }
+            }
+        },
+        "dto.FeeScheduleView": {
+            "type": "object",
+            "properties": {
+                "currency": {
+                    "$ref": "#/definitions/money.Currency"
+                },
+                "rules": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.FeeRuleView"
+                    }
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.FeeSchedulesResponse": {
+            "type": "object",
+            "properties": {
+                "schedules": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.FeeScheduleView"
+                    }
+                }
+            }
+        },
+        "dto.FeeView": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "$ref": "#/definitions/money.jsonMoney"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "rule": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.HeartbeatRequest": {
+            "type": "object",
+            "required": [
+                "errors"
+            ],
+            "properties": {
+                "errors": {
+                    "type": "array",
+                    "maxItems": 10,
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "dto.HeartbeatResponse": {
+            "type": "object",
+            "properties": {
+                "received_at": {
+                    "type": "string"
+                },
+                "ttl_seconds": {
+                    "type": "integer"
+                }
+            }
+        },
+        "dto.HoldRequest": {
+            "type": "object",
+            "required": [
+                "accountID"
+            ],
+            "properties": {
+                "accountID": {
+                    "type": "string"
+                },
+                "amount": {
+                    "$ref": "#/definitions/money.jsonMoney"
+                },
+                "expires_in_seconds": {
+                    "type": "integer",
+                    "maximum": 604800,
+                    "minimum": 0
+                },
+                "reference": {
+                    "type": "string",
+                    "maxLength": 64
+                }
+            }
+        },
+        "dto.HoldResponse": {
+            "type": "object",
+            "properties": {
+                "available_balance": {
+                    "$ref": "#/definitions/money.jsonMoney"
+                },
+                "balance": {
+                    "$ref": "#/definitions/money.jsonMoney"
+                },
+                "fees": {
+                    "description": "Fees are the fees charged when a hold is captured.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.FeeView"
+                    }
+                },
+                "hold": {
+                    "$ref": "#/definitions/dto.HoldView"
+                },
+                "message": {
+                    "type": "string"
+                },
+                "notes": {
+                    "description": "Notes are the banknotes to dispense when a hold is captured at an\nATM.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.NotesView"
+                    }
+                }
+            }
+        },
+        "dto.HoldView": {
+            "type": "object",
+            "properties": {
+                "account_id": {
+                    "type": "string"
+                },
+                "amount": {
+                    "$ref": "#/definitions/money.jsonMoney"
+                },
+                "captured": {
+                    "$ref": "#/definitions/money.jsonMoney"
+                },
+                "closed_at": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "expires_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "reference": {
+                    "type": "string"
+                },
+                "status": {
+                    "$ref": "#/definitions/models.HoldStatus"
+                },
+                "withdrawal_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.IssueCardRequest": {
+            "type": "object",
+            "required": [
+                "account_ids",
+                "user_id"
+            ],
+            "properties": {
+                "account_ids": {
+                    "type": "array",
+                    "maxItems": 5,
+                    "minItems": 1,
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "user_id": {
+                    "type": "string"
+                },
+                "valid_years": {
+                    "type": "integer",
+                    "maximum": 10,
+                    "minimum": 0
+                }
+            }
+        },
+        "dto.IssueCardResponse": {
+            "type": "object",
+            "properties": {
+                "card": {
+                    "$ref": "#/definitions/dto.CardView"
+                },
+                "full_pan": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.LoginRequest": {
+            "type": "object",
+            "required": [
+                "pin",
+                "username"
+            ],
+            "properties": {
+                "pin": {
+                    "type": "string"
+                },
+                "username": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.LoginResponse": {
+            "type": "object",
+            "properties": {
+                "token": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.MessageResponse": {
+            "type": "object",
+            "properties": {
+                "message": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.NotesRequest": {
+            "type": "object",
+            "properties": {
+                "count": {
+                    "type": "integer",
+                    "maximum": 10000,
+                    "minimum": 0
+                },
+                "denomination": {
+                    "$ref": "#/definitions/money.jsonMoney"
+                }
+            }
+        },
+        "dto.NotesView": {
+            "type": "object",
+            "properties": {
+                "count": {
+                    "type": "integer"
+                },
+                "denomination": {
+                    "$ref": "#/definitions/money.jsonMoney"
+                }
+            }
+        },
+        "dto.OpenAccountRequest": {
+            "type": "object",
+            "required": [
+                "currency"
+            ],
+            "properties": {
+                "currency": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.OverdraftRequest": {
+            "type": "object",
+            "properties": {
+                "limit": {
+                    "$ref": "#/definitions/money.jsonMoney"
+                },
+                "rate_bps": {
+                    "type": "integer",
+                    "maximum": 10000,
+                    "minimum": 0
+                }
+            }
+        },
+        "dto.OverdraftView": {
+            "type": "object",
+            "properties": {
+                "limit": {
+                    "$ref": "#/definitions/money.jsonMoney"
+                },
+                "rate_bps": {
+                    "type": "integer"
+                },
+                "since": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.PinChangeRequest": {
+            "type": "object",
+            "required": [
+                "newPIN",
+                "oldPIN"
+            ],
+            "properties": {
+                "newPIN": {
+                    "type": "string"
+                },
+                "oldPIN": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.ReconcileRequest": {
+            "type": "object",
+            "properties": {
+                "counted": {
+                    "type": "array",
+                    "maxItems": 16,
+                    "items": {
+                        "$ref": "#/definitions/dto.NotesRequest"
+                    }
+                }
+            }
+        },
+        "dto.ReconciliationResponse": {
+            "type": "object",
+            "properties": {
+                "reconciliation": {
+                    "$ref": "#/definitions/models.Reconciliation"
+                }
+            }
+        },
+        "dto.ReconciliationsResponse": {
+            "type": "object",
+            "properties": {
+                "reconciliations": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Reconciliation"
+                    }
+                }
+            }
+        },
+        "dto.RegisterRequest": {
+            "type": "object",
+            "required": [
+                "first_name",
+                "last_name",
+                "phone_number",
+                "pin",
+                "username"
+            ],
+            "properties": {
+                "first_name": {
+                    "type": "string",
+                    "maxLength": 50
+                },
+                "language": {
+                    "type": "string",
+                    "enum": [
+                        "en",
+                        "tr"
+                    ]
+                },
+                "last_name": {
+                    "type": "string",
+                    "maxLength": 50
+                },
+                "phone_number": {
+                    "type": "string"
+                },
+                "pin": {
+                    "type": "string"
+                },
+                "username": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.RegisterResponse": {
+            "type": "object",
+            "properties": {
+                "account": {
+                    "$ref": "#/definitions/dto.AccountView"
+                },
+                "user": {
+                    "$ref": "#/definitions/dto.UserView"
+                }
+            }
+        },
+        "dto.RegisterTerminalRequest": {
+            "type": "object",
+            "required": [
+                "branch",
+                "location"
+            ],
+            "properties": {
+                "branch": {
+                    "type": "string",
+                    "maxLength": 100
+                },
+                "cert_fingerprint": {
+                    "type": "string"
+                },
+                "location": {
+                    "type": "string",
+                    "maxLength": 200
+                }
+            }
+        },
+        "dto.RegisterTerminalResponse": {
+            "type": "object",
+            "properties": {
+                "secret": {
+                    "type": "string"
+                },
+                "terminal": {
+                    "$ref": "#/definitions/dto.TerminalView"
+                }
+            }
+        },
+        "dto.RemainingLimitsView": {
+            "type": "object",
+            "properties": {
+                "daily": {
+                    "$ref": "#/definitions/money.jsonMoney"
+                },
+                "daily_count": {
+                    "type": "integer"
+                },
+                "per_transaction": {
+                    "$ref": "#/definitions/money.jsonMoney"
+                }
+            }
+        },
+        "dto.ReversalRequest": {
+            "type": "object",
+            "required": [
+                "reason"
+            ],
+            "properties": {
+                "note": {
+                    "type": "string",
+                    "maxLength": 200
+                },
+                "reason": {
+                    "type": "string",
+                    "enum": [
+                        "duplicate",
+                        "wrong_account",
+                        "wrong_amount",
+                        "dispense_failure",
+                        "fraud",
+                        "other"
+                    ]
+                }
+            }
+        },
+        "dto.ReversalResponse": {
+            "type": "object",
+            "properties": {
+                "balance": {
+                    "$ref": "#/definitions/money.jsonMoney"
+                },
+                "balance_formatted": {
+                    "type": "string"
+                },
+                "message": {
+                    "type": "string"
+                },
+                "reversal": {
+                    "$ref": "#/definitions/dto.ReversalView"
+                }
+            }
+        },
+        "dto.ReversalView": {
+            "type": "object",
+            "properties": {
+                "account_id": {
+                    "type": "string"
+                },
+                "amount": {
+                    "$ref": "#/definitions/money.jsonMoney"
+                },
+                "fees_refunded": {
+                    "$ref": "#/definitions/money.jsonMoney"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "note": {
+                    "type": "string"
+                },
+                "reason": {
+                    "$ref": "#/definitions/models.ReversalReason"
+                },
+                "reversed_at": {
+                    "type": "string"
+                },
+                "reversed_by": {
+                    "type": "string"
+                },
+                "transaction_id": {
+                    "type": "string"
+                },
+                "transaction_type": {
+                    "$ref": "#/definitions/models.ReversibleType"
+                }
+            }
+        },
+        "dto.SetRoleRequest": {
+            "type": "object",
+            "required": [
+                "role"
+            ],
+            "properties": {
+                "role": {
+                    "type": "string",
+                    "enum": [
+                        "customer",
+                        "teller",
+                        "admin"
+                    ]
+                }
+            }
+        },
+        "dto.StandingOrderRequest": {
+            "type": "object",
+            "required": [
+                "fromAccountID",
+                "schedule",
+                "toAccountID"
+            ],
+            "properties": {
+                "amount": {
+                    "$ref": "#/definitions/money.jsonMoney"
+                },
+                "fromAccountID": {
+                    "type": "string"
+                },
+                "max_retries": {
+                    "type": "integer",
+                    "maximum": 10,
+                    "minimum": 0
+                },
+                "on_insufficient_funds": {
+                    "type": "string",
+                    "enum": [
+                        "retry",
+                        "skip"
+                    ]
+                },
+                "schedule": {
+                    "type": "string"
+                },
+                "toAccountID": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.StandingOrderResponse": {
+            "type": "object",
+            "properties": {
+                "standing_order": {
+                    "$ref": "#/definitions/dto.StandingOrderView"
+                }
+            }
+        },
+        "dto.StandingOrderView": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "$ref": "#/definitions/money.jsonMoney"
+                },
+                "attempts": {
+                    "type": "integer"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "from_account_id": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "last_run": {
+                    "$ref": "#/definitions/models.StandingOrderRun"
+                },
+                "max_retries": {
+                    "type": "integer"
+                },
+                "next_run_at": {
+                    "type": "string"
+                },
+                "on_insufficient_funds": {
+                    "$ref": "#/definitions/models.InsufficientFundsPolicy"
+                },
+                "schedule": {
+                    "type": "string"
+                },
+                "status": {
+                    "$ref": "#/definitions/models.StandingOrderStatus"
+                },
+                "to_account_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.StandingOrdersResponse": {
+            "type": "object",
+            "properties": {
+                "standing_orders": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.StandingOrderView"
+                    }
+                }
+            }
+        },
+        "dto.TerminalResponse": {
+            "type": "object",
+            "properties": {
+                "terminal": {
+                    "$ref": "#/definitions/dto.TerminalView"
+                }
+            }
+        },
+        "dto.TerminalStatusView": {
+            "type": "object",
+            "properties": {
+                "branch": {
+                    "type": "string"
+                },
+                "cash": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.NotesView"
+                    }
+                },
+                "cert_fingerprint": {
+                    "type": "string"
+                },
+                "decommissioned_at": {
+                    "type": "string"
+                },
+                "errors": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "id": {
+                    "type": "string"
+                },
+                "last_seen_at": {
+                    "type": "string"
+                },
+                "location": {
+                    "type": "string"
+                },
+                "low_cash": {
+                    "description": "LowCash lists the currencies in which the cassettes hold less than\nthe alert threshold.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/money.Currency"
+                    }
+                },
+                "online": {
+                    "type": "boolean"
+                },
+                "registered_at": {
+                    "type": "string"
+                },
+                "status": {
+                    "$ref": "#/definitions/models.TerminalStatus"
+                }
+            }
+        },
+        "dto.TerminalView": {
+            "type": "object",
+            "properties": {
+                "branch": {
+                    "type": "string"
+                },
+                "cert_fingerprint": {
+                    "type": "string"
+                },
+                "decommissioned_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "location": {
+                    "type": "string"
+                },
+                "registered_at": {
+                    "type": "string"
+                },
+                "status": {
+                    "$ref": "#/definitions/models.TerminalStatus"
+                }
+            }
+        },
+        "dto.TerminalsResponse": {
+            "type": "object",
+            "properties": {
+                "terminals": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.TerminalStatusView"
+                    }
+                }
+            }
+        },
+        "dto.TransactionResponse": {
+            "type": "object",
+            "properties": {
+                "balance": {
+                    "$ref": "#/definitions/money.jsonMoney"
+                },
+                "balance_formatted": {
+                    "type": "string"
+                },
+                "conversion": {
+                    "$ref": "#/definitions/dto.ConversionView"
+                },
+                "date": {
+                    "type": "string"
+                },
+                "fees": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.FeeView"
+                    }
+                },
+                "message": {
+                    "type": "string"
+                },
+                "notes": {
+                    "description": "Notes are the banknotes to dispense, for withdrawals at an ATM.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.NotesView"
+                    }
+                },
+                "transaction_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.TransferRequest": {
+            "type": "object",
+            "required": [
+                "fromAccountID",
+                "toAccountID"
+            ],
+            "properties": {
+                "amount": {
+                    "$ref": "#/definitions/money.jsonMoney"
+                },
+                "fromAccountID": {
+                    "type": "string"
+                },
+                "toAccountID": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.TransferResponse": {
+            "type": "object",
+            "properties": {
+                "balance": {
+                    "$ref": "#/definitions/money.jsonMoney"
+                },
+                "balance_formatted": {
+                    "type": "string"
+                },
+                "conversion": {
+                    "$ref": "#/definitions/dto.ConversionView"
+                },
+                "credited": {
+                    "$ref": "#/definitions/money.jsonMoney"
+                },
+                "date": {
+                    "type": "string"
+                },
+                "debited": {
+                    "$ref": "#/definitions/money.jsonMoney"
+                },
+                "fees": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.FeeView"
+                    }
+                },
+                "message": {
+                    "type": "string"
+                },
+                "transferID": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.UserResponse": {
+            "type": "object",
+            "properties": {
+                "user": {
+                    "$ref": "#/definitions/dto.UserView"
+                }
+            }
+        },
+        "dto.UserView": {
+            "type": "object",
+            "properties": {
+                "first_name": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "language": {
+                    "type": "string"
+                },
+                "last_name": {
+                    "type": "string"
+                },
+                "phone_number": {
+                    "type": "string"
+                },
+                "role": {
+                    "$ref": "#/definitions/models.Role"
+                },
+                "username": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.WithdrawalLimitsRequest": {
+            "type": "object",
+            "properties": {
+                "daily": {
+                    "$ref": "#/definitions/money.jsonMoney"
+                },
+                "daily_count": {
+                    "type": "integer",
+                    "minimum": 0
+                },
+                "per_transaction": {
+                    "$ref": "#/definitions/money.jsonMoney"
+                }
+            }
+        },
+        "dto.WithdrawalLimitsResponse": {
+            "type": "object",
+            "properties": {
+                "accountID": {
+                    "type": "string"
+                },
+                "business_day": {
+                    "type": "string"
+                },
+                "limits": {
+                    "$ref": "#/definitions/dto.WithdrawalLimitsView"
+                },
+                "remaining": {
+                    "$ref": "#/definitions/dto.RemainingLimitsView"
+                },
+                "resets_at": {
+                    "type": "string"
+                },
+                "used": {
+                    "$ref": "#/definitions/dto.WithdrawalUsageView"
+                }
+            }
+        },
+        "dto.WithdrawalLimitsView": {
+            "type": "object",
+            "properties": {
+                "daily": {
+                    "$ref": "#/definitions/money.jsonMoney"
+                },
+                "daily_count": {
+                    "type": "integer"
+                },
+                "per_transaction": {
+                    "$ref": "#/definitions/money.jsonMoney"
+                }
+            }
+        },
+        "dto.WithdrawalRequest": {
+            "type": "object",
+            "required": [
+                "accountID"
+            ],
+            "properties": {
+                "accountID": {
+                    "type": "string"
+                },
+                "withdrawalAmount": {
+                    "$ref": "#/definitions/money.jsonMoney"
+                }
+            }
+        },
+        "dto.WithdrawalUsageView": {
+            "type": "object",
+            "properties": {
+                "count": {
+                    "type": "integer"
+                },
+                "withdrawn": {
+                    "$ref": "#/definitions/money.jsonMoney"
+                }
+            }
+        },
+        "models.AccountStatus": {
+            "type": "string",
+            "enum": [
+                "active",
+                "frozen",
+                "dormant",
+                "closed"
+            ],
+            "x-enum-varnames": [
+                "AccountActive",
+                "AccountFrozen",
+                "AccountDormant",
+                "AccountClosed"
+            ]
+        },
+        "models.CardStatus": {
+            "type": "string",
+            "enum": [
+                "active",
+                "blocked"
+            ],
+            "x-enum-varnames": [
+                "CardActive",
+                "CardBlocked"
+            ]
+        },
+        "models.CashMovementType": {
+            "type": "string",
+            "enum": [
+                "load",
+                "unload"
+            ],
+            "x-enum-varnames": [
+                "CashLoad",
+                "CashUnload"
+            ]
+        },
+        "models.DormancyReport": {
+            "type": "object",
+            "properties": {
+                "accounts": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.DormantAccount"
+                    }
+                },
+                "generated_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "inactive_since": {
+                    "type": "string"
+                },
+                "scanned": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.DormantAccount": {
+            "type": "object",
+            "properties": {
+                "account_id": {
+                    "type": "string"
+                },
+                "balance": {
+                    "$ref": "#/definitions/money.jsonMoney"
+                },
+                "last_activity_at": {
+                    "type": "string"
+                },
+                "user_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.FeeOperation": {
+            "type": "string",
+            "enum": [
+                "withdrawal",
+                "balance_inquiry",
+                "transfer"
+            ],
+            "x-enum-varnames": [
+                "FeeWithdrawal",
+                "FeeBalanceInquiry",
+                "FeeTransfer"
+            ]
+        },
+        "models.HoldStatus": {
+            "type": "string",
+            "enum": [
+                "active",
+                "captured",
+                "released",
+                "expired"
+            ],
+            "x-enum-varnames": [
+                "HoldActive",
+                "HoldCaptured",
+                "HoldReleased",
+                "HoldExpired"
+            ]
+        },
+        "models.InsufficientFundsPolicy": {
+            "type": "string",
+            "enum": [
+                "retry",
+                "skip"
+            ],
+            "x-enum-varnames": [
+                "RetryOnInsufficientFunds",
+                "SkipOnInsufficientFunds"
+            ]
+        },
+        "models.Notes": {
+            "type": "object",
+            "properties": {
+                "count": {
+                    "type": "integer"
+                },
+                "denomination": {
+                    "$ref": "#/definitions/money.jsonMoney"
+                }
+            }
+        },
+        "models.Reconciliation": {
+            "type": "object",
+            "properties": {
+                "business_day": {
+                    "description": "of To",
+                    "type": "string"
+                },
+                "counted": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Notes"
+                    }
+                },
+                "discrepancy": {
+                    "type": "boolean"
+                },
+                "from": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "lines": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.ReconciliationLine"
+                    }
+                },
+                "reconciled_by": {
+                    "type": "string"
+                },
+                "terminal_id": {
+                    "type": "string"
+                },
+                "to": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.ReconciliationLine": {
+            "type": "object",
+            "properties": {
+                "counted": {
+                    "$ref": "#/definitions/money.jsonMoney"
+                },
+                "currency": {
+                    "$ref": "#/definitions/money.Currency"
+                },
+                "deposited": {
+                    "$ref": "#/definitions/money.jsonMoney"
+                },
+                "discrepancy": {
+                    "$ref": "#/definitions/money.jsonMoney"
+                },
+                "dispensed": {
+                    "$ref": "#/definitions/money.jsonMoney"
+                },
+                "expected": {
+                    "$ref": "#/definitions/money.jsonMoney"
+                },
+                "loaded": {
+                    "$ref": "#/definitions/money.jsonMoney"
+                },
+                "opening": {
+                    "$ref": "#/definitions/money.jsonMoney"
+                },
+                "unloaded": {
+                    "$ref": "#/definitions/money.jsonMoney"
+                }
+            }
+        },
+        "models.ReversalReason": {
+            "type": "string",
+            "enum": [
+                "duplicate",
+                "wrong_account",
+                "wrong_amount",
+                "dispense_failure",
+                "fraud",
+                "other"
+            ],
+            "x-enum-comments": {
+                "ReversalDispenseFailure": "the ATM did not pay out"
+            },
+            "x-enum-varnames": [
+                "ReversalDuplicate",
+                "ReversalWrongAccount",
+                "ReversalWrongAmount",
+                "ReversalDispenseFailure",
+                "ReversalFraud",
+                "ReversalOther"
+            ]
+        },
+        "models.ReversibleType": {
+            "type": "string",
+            "enum": [
+                "deposit",
+                "withdrawal"
+            ],
+            "x-enum-varnames": [
+                "ReversibleDeposit",
+                "ReversibleWithdrawal"
+            ]
+        },
+        "models.Role": {
+            "type": "string",
+            "enum": [
+                "customer",
+                "teller",
+                "admin"
+            ],
+            "x-enum-varnames": [
+                "RoleCustomer",
+                "RoleTeller",
+                "RoleAdmin"
+            ]
+        },
+        "models.StandingOrderRun": {
+            "type": "object",
+            "properties": {
+                "at": {
+                    "type": "string"
+                },
+                "error": {
+                    "type": "string"
+                },
+                "status": {
+                    "$ref": "#/definitions/models.StandingOrderRunStatus"
+                },
+                "transfer_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.StandingOrderRunStatus": {
+            "type": "string",
+            "enum": [
+                "executed",
+                "retrying",
+                "skipped"
+            ],
+            "x-enum-varnames": [
+                "StandingOrderExecuted",
+                "StandingOrderRetrying",
+                "StandingOrderSkipped"
+            ]
+        },
+        "models.StandingOrderStatus": {
+            "type": "string",
+            "enum": [
+                "active",
+                "paused",
+                "cancelled"
+            ],
+            "x-enum-varnames": [
+                "StandingOrderActive",
+                "StandingOrderPaused",
+                "StandingOrderCancelled"
+            ]
+        },
+        "models.TerminalStatus": {
+            "type": "string",
+            "enum": [
+                "active",
+                "decommissioned"
+            ],
+            "x-enum-varnames": [
+                "TerminalActive",
+                "TerminalDecommissioned"
+            ]
+        },
+        "money.Currency": {
+            "type": "string",
+            "enum": [
+                "TRY",
+                "USD",
+                "EUR",
+                "GBP",
+                "JPY",
+                "TRY"
+            ],
+            "x-enum-varnames": [
+                "TRY",
+                "USD",
+                "EUR",
+                "GBP",
+                "JPY",
+                "DefaultCurrency"
+            ]
+        },
+        "money.jsonMoney": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "type": "string"
+                },
+                "currency": {
+                    "$ref": "#/definitions/money.Currency"
+                }
+            }
         }
     }
 }`
 
 // SwaggerInfo holds exported Swagger Info so clients can modify it
 var SwaggerInfo = &swag.Spec{
-	Version:          "",
+	Version:          "1.0",
 	Host:             "",
-	BasePath:         "",
+	BasePath:         "/v1",
 	Schemes:          []string{},
-	Title:            "",
+	Title:            "ATM Management API",
 	Description:      "",
 	InfoInstanceName: "swagger",
 	SwaggerTemplate:  docTemplate,
@@ -0,0 +1,136 @@
+package dormancy
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"newapiprojet/database"
+	"newapiprojet/ledger"
+	"newapiprojet/models"
+
+	"github.com/google/uuid"
+)
+
+// Each run that makes accounts dormant stores a report:
+//
+//	dormancy_reports/<unix nanos, zero-padded>  models.DormancyReport
+const ReportsPrefix = "dormancy_reports/"
+
+// Job makes active accounts dormant once the customer has not used them for
+// a period. Dormant accounts can still be credited, but nothing can be taken
+// from them until staff reactivate them.
+type Job struct {
+	db     database.Database
+	period time.Duration
+	logger *slog.Logger
+	now    func() time.Time
+}
+
+func NewJob(db database.Database, period time.Duration, logger *slog.Logger) *Job {
+	return &Job{db: db, period: period, logger: logger, now: time.Now}
+}
+
+// Run checks every account's last activity and stores a report of the
+// accounts it made dormant. Accounts that have never recorded activity,
+// such as those opened before activity was tracked, start their period now.
+func (j *Job) Run(ctx context.Context) error {
+	now := j.now()
+	report := models.DormancyReport{
+		ID:            uuid.New(),
+		GeneratedAt:   now,
+		InactiveSince: now.Add(-j.period),
+	}
+
+	accounts, err := j.db.List(ctx, "accounts/")
+	if err != nil {
+		return fmt.Errorf("listing accounts: %w", err)
+	}
+
+	var failed int
+	for key := range accounts {
+		id, err := uuid.Parse(strings.TrimPrefix(key, "accounts/"))
+		if err != nil {
+			continue // legacy key layout
+		}
+		report.Scanned++
+		dormant, err := j.check(ctx, id, report.InactiveSince, now)
+		if err != nil {
+			failed++
+			j.logger.ErrorContext(ctx, "dormancy check failed", "account_id", id, "error", err)
+			continue
+		}
+		if dormant != nil {
+			report.Accounts = append(report.Accounts, *dormant)
+			j.logger.InfoContext(ctx, "account made dormant", "account_id", id, "last_activity_at", dormant.LastActivityAt)
+		}
+	}
+
+	if len(report.Accounts) > 0 {
+		data, err := json.Marshal(report)
+		if err != nil {
+			return fmt.Errorf("marshaling dormancy report: %w", err)
+		}
+		if err := j.db.Put(ctx, fmt.Sprintf("%s%020d", ReportsPrefix, now.UnixNano()), data); err != nil {
+			return fmt.Errorf("storing dormancy report: %w", err)
+		}
+	}
+
+	j.logger.InfoContext(ctx, "dormancy checked", "inactive_since", report.InactiveSince, "accounts", report.Scanned, "dormant", len(report.Accounts), "failed", failed)
+	if failed > 0 {
+		return fmt.Errorf("dormancy check failed for %d of %d accounts", failed, report.Scanned)
+	}
+	return nil
+}
+
+// check makes one account dormant if it is active and was last used before
+// inactiveSince, and returns its report entry if so.
+func (j *Job) check(ctx context.Context, accountID uuid.UUID, inactiveSince, now time.Time) (*models.DormantAccount, error) {
+	var dormant *models.DormantAccount
+	err := j.db.Atomic(ctx, func(tx database.Tx) error {
+		dormant = nil
+
+		key := "accounts/" + accountID.String()
+		data := tx.Get(key)
+		if data == nil {
+			return nil // deleted since it was listed
+		}
+		var account models.Account
+		if err := json.Unmarshal(data, &account); err != nil {
+			return fmt.Errorf("unmarshaling account data: %w", err)
+		}
+		if ledger.Status(account) != models.AccountActive {
+			return nil
+		}
+
+		switch {
+		case account.LastActivityAt == nil:
+			ledger.RecordActivity(&account, now)
+		case account.LastActivityAt.Before(inactiveSince):
+			last := *account.LastActivityAt
+			reason := "no customer activity since " + last.UTC().Format(time.RFC3339)
+			if err := ledger.SetStatus(tx, &account, models.AccountDormant, reason, nil, now); err != nil {
+				return err
+			}
+			dormant = &models.DormantAccount{
+				AccountID:      account.ID,
+				UserID:         account.UserID,
+				Balance:        account.Balance,
+				LastActivityAt: last,
+			}
+		default:
+			return nil
+		}
+
+		data, err := json.Marshal(account)
+		if err != nil {
+			return fmt.Errorf("marshaling account data: %w", err)
+		}
+		tx.Put(key, data)
+		return nil
+	})
+	return dormant, err
+}
@@ -0,0 +1,183 @@
+package dto
+
+import (
+	"newapiprojet/ledger"
+	"newapiprojet/models"
+	"newapiprojet/money"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// DepositRequest is the body of POST /account/deposit.
+type DepositRequest struct {
+	AccountID     uuid.UUID   `json:"accountID" binding:"required"`
+	DepositAmount money.Money `json:"depositAmount" binding:"amount"`
+}
+
+// WithdrawalRequest is the body of POST /account/withdrawal.
+type WithdrawalRequest struct {
+	AccountID        uuid.UUID   `json:"accountID" binding:"required"`
+	WithdrawalAmount money.Money `json:"withdrawalAmount" binding:"amount"`
+}
+
+// AccountIDParam is the {accountID} path parameter.
+type AccountIDParam struct {
+	AccountID string `uri:"accountID" binding:"required,uuid"`
+}
+
+// UUID returns the validated account ID. Only call it after a successful bind.
+func (p AccountIDParam) UUID() uuid.UUID {
+	return uuid.MustParse(p.AccountID)
+}
+
+// AccountView is the public representation of an account.
+type AccountView struct {
+	ID              uuid.UUID            `json:"id"`
+	UserID          uuid.UUID            `json:"user_id"`
+	Currency        money.Currency       `json:"currency"`
+	Balance         money.Money          `json:"balance"`
+	Status          models.AccountStatus `json:"status"`
+	StatusChangedAt *time.Time           `json:"status_changed_at,omitempty"`
+	LastActivityAt  *time.Time           `json:"last_activity_at,omitempty"`
+	Overdraft       *OverdraftView       `json:"overdraft,omitempty"`
+}
+
+func NewAccountView(account models.Account) AccountView {
+	return AccountView{
+		ID:              account.ID,
+		UserID:          account.UserID,
+		Currency:        account.Balance.Currency(),
+		Balance:         account.Balance,
+		Status:          ledger.Status(account),
+		StatusChangedAt: account.StatusChangedAt,
+		LastActivityAt:  account.LastActivityAt,
+		Overdraft:       NewOverdraftView(account),
+	}
+}
+
+// AccountStatusRequest is the body of PUT /staff/accounts/{id}/status.
+// Accounts can be frozen and unfrozen, dormant accounts reactivated, and
+// accounts with a zero balance closed.
+type AccountStatusRequest struct {
+	Status string `json:"status" binding:"required,oneof=active frozen closed"`
+	Reason string `json:"reason" binding:"max=200"`
+}
+
+// OverdraftView shows an account's overdraft facility. Since is set while
+// the account is overdrawn.
+type OverdraftView struct {
+	Limit   money.Money `json:"limit"`
+	RateBps int64       `json:"rate_bps"`
+	Since   *time.Time  `json:"since,omitempty"`
+}
+
+// NewOverdraftView returns nil for accounts without an overdraft.
+func NewOverdraftView(account models.Account) *OverdraftView {
+	if account.Overdraft == nil {
+		return nil
+	}
+	return &OverdraftView{
+		Limit:   account.Overdraft.Limit,
+		RateBps: account.Overdraft.RateBps,
+		Since:   account.OverdraftSince,
+	}
+}
+
+// OverdraftRequest is the body of PUT /staff/accounts/{id}/overdraft. Limit
+// is in the account currency; a zero limit removes the facility.
+type OverdraftRequest struct {
+	Limit   money.Money `json:"limit"`
+	RateBps int64       `json:"rate_bps" binding:"gte=0,lte=10000"`
+}
+
+// AccountResponse is returned by GET /account/{id}.
+type AccountResponse struct {
+	Account AccountView `json:"account"`
+}
+
+// TransactionResponse is returned by deposits and withdrawals.
+type TransactionResponse struct {
+	Message          string          `json:"message"`
+	TransactionID    uuid.UUID       `json:"transaction_id"`
+	Balance          money.Money     `json:"balance"`
+	BalanceFormatted string          `json:"balance_formatted"`
+	Date             string          `json:"date"`
+	Conversion       *ConversionView `json:"conversion,omitempty"`
+	Fees             []FeeView       `json:"fees,omitempty"`
+	// Notes are the banknotes to dispense, for withdrawals at an ATM.
+	Notes []NotesView `json:"notes,omitempty"`
+}
+
+// OpenAccountRequest is the body of POST /account/open.
+type OpenAccountRequest struct {
+	Currency string `json:"currency" binding:"required,currency"`
+}
+
+// TransferRequest is the body of POST /account/transfer. Amount must be in
+// the currency of one of the two accounts.
+type TransferRequest struct {
+	FromAccountID uuid.UUID   `json:"fromAccountID" binding:"required"`
+	ToAccountID   uuid.UUID   `json:"toAccountID" binding:"required"`
+	Amount        money.Money `json:"amount" binding:"amount"`
+}
+
+// TransferResponse is returned by POST /account/transfer. Balance is the
+// new balance of the source account.
+type TransferResponse struct {
+	Message          string          `json:"message"`
+	TransferID       uuid.UUID       `json:"transferID"`
+	Debited          money.Money     `json:"debited"`
+	Credited         money.Money     `json:"credited"`
+	Balance          money.Money     `json:"balance"`
+	BalanceFormatted string          `json:"balance_formatted"`
+	Date             string          `json:"date"`
+	Conversion       *ConversionView `json:"conversion,omitempty"`
+	Fees             []FeeView       `json:"fees,omitempty"`
+}
+
+// ConversionView shows the rate applied to a cross-currency transaction.
+type ConversionView struct {
+	From        money.Money `json:"from"`
+	To          money.Money `json:"to"`
+	Rate        string      `json:"rate"`
+	AppliedRate string      `json:"applied_rate"`
+	SpreadBps   int64       `json:"spread_bps"`
+}
+
+// NewConversionView returns nil when no conversion took place.
+func NewConversionView(conversion *models.Conversion) *ConversionView {
+	if conversion == nil {
+		return nil
+	}
+	return &ConversionView{
+		From:        conversion.From,
+		To:          conversion.To,
+		Rate:        conversion.Rate,
+		AppliedRate: conversion.AppliedRate,
+		SpreadBps:   conversion.SpreadBps,
+	}
+}
+
+// BalanceResponse is returned by GET /account/balance/{accountID}. Balance
+// is the ledger balance; AvailableBalance adds any unused overdraft.
+type BalanceResponse struct {
+	Message                   string               `json:"message"`
+	AccountID                 uuid.UUID            `json:"accountID"`
+	Balance                   money.Money          `json:"balance"`
+	BalanceFormatted          string               `json:"balance_formatted"`
+	LedgerBalance             money.Money          `json:"ledger_balance"`
+	AvailableBalance          money.Money          `json:"available_balance"`
+	AvailableBalanceFormatted string               `json:"available_balance_formatted"`
+	Held                      money.Money          `json:"held"` // active holds, taken off the available balance
+	Status                    models.AccountStatus `json:"status"`
+	Overdraft                 *OverdraftView       `json:"overdraft,omitempty"`
+	Date                      string               `json:"date"`
+	Fees                      []FeeView            `json:"fees,omitempty"`
+	FeesWaived                bool                 `json:"fees_waived,omitempty"` // the account could not cover the fee
+}
+
+// MessageResponse carries a single localized message.
+type MessageResponse struct {
+	Message string `json:"message"`
+}
@@ -0,0 +1,77 @@
+package dto
+
+import (
+	"newapiprojet/cards"
+	"newapiprojet/models"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// IssueCardRequest is the body of POST /staff/cards. The accounts must
+// belong to the user. Cards are valid for ValidYears, 4 by default.
+type IssueCardRequest struct {
+	UserID     uuid.UUID   `json:"user_id" binding:"required"`
+	AccountIDs []uuid.UUID `json:"account_ids" binding:"required,min=1,max=5"`
+	ValidYears int         `json:"valid_years" binding:"gte=0,lte=10"`
+}
+
+// BlockCardRequest is the body of POST /staff/cards/{id}/block.
+type BlockCardRequest struct {
+	Reason string `json:"reason" binding:"required,max=200"`
+}
+
+// CardLoginRequest is the body of POST /card/login, sent by the ATM with
+// the card number it read and the PIN the holder typed.
+type CardLoginRequest struct {
+	PAN string `json:"pan" binding:"required,pan"`
+	PIN string `json:"pin" binding:"required"`
+}
+
+// CardView is the public representation of a card. The card number is
+// masked; Expiry is the "MM/YY" printed on the card.
+type CardView struct {
+	ID          uuid.UUID         `json:"id"`
+	PAN         string            `json:"pan"`
+	UserID      uuid.UUID         `json:"user_id"`
+	AccountIDs  []uuid.UUID       `json:"account_ids"`
+	Expiry      string            `json:"expiry"`
+	Status      models.CardStatus `json:"status"`
+	BlockReason string            `json:"block_reason,omitempty"`
+	BlockedAt   *time.Time        `json:"blocked_at,omitempty"`
+	IssuedAt    time.Time         `json:"issued_at"`
+}
+
+func NewCardView(card models.Card) CardView {
+	return CardView{
+		ID:          card.ID,
+		PAN:         cards.Mask(card.PAN),
+		UserID:      card.UserID,
+		AccountIDs:  card.AccountIDs,
+		Expiry:      card.ExpiresAt.Add(-time.Nanosecond).Format("01/06"),
+		Status:      card.Status,
+		BlockReason: card.BlockReason,
+		BlockedAt:   card.BlockedAt,
+		IssuedAt:    card.IssuedAt,
+	}
+}
+
+// IssueCardResponse is returned by POST /staff/cards. It is the only
+// response that carries the full card number, for printing the card.
+type IssueCardResponse struct {
+	Card CardView `json:"card"`
+	PAN  string   `json:"full_pan"`
+}
+
+// CardResponse is returned when a card is blocked.
+type CardResponse struct {
+	Card CardView `json:"card"`
+}
+
+// CardLoginResponse is returned by POST /card/login. The token only works
+// for AccountIDs and expires at ExpiresAt.
+type CardLoginResponse struct {
+	Token      string      `json:"token"`
+	ExpiresAt  time.Time   `json:"expires_at"`
+	AccountIDs []uuid.UUID `json:"account_ids"`
+}
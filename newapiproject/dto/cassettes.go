@@ -0,0 +1,100 @@
+package dto
+
+import (
+	"newapiprojet/models"
+	"newapiprojet/money"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// NotesRequest is a number of banknotes of one denomination.
+type NotesRequest struct {
+	Denomination money.Money `json:"denomination" binding:"amount"`
+	Count        int         `json:"count" binding:"gte=0,lte=10000"`
+}
+
+func toNotes(requests []NotesRequest) []models.Notes {
+	notes := make([]models.Notes, 0, len(requests))
+	for _, r := range requests {
+		notes = append(notes, models.Notes{Denomination: r.Denomination, Count: r.Count})
+	}
+	return notes
+}
+
+// NotesView is a number of banknotes of one denomination.
+type NotesView struct {
+	Denomination money.Money `json:"denomination"`
+	Count        int         `json:"count"`
+}
+
+func NewNotesViews(notes []models.Notes) []NotesView {
+	if len(notes) == 0 {
+		return nil
+	}
+	views := make([]NotesView, 0, len(notes))
+	for _, n := range notes {
+		views = append(views, NotesView{Denomination: n.Denomination, Count: n.Count})
+	}
+	return views
+}
+
+// CassettesResponse is the cash in a terminal's cassettes.
+type CassettesResponse struct {
+	TerminalID uuid.UUID   `json:"terminal_id"`
+	Cassettes  []NotesView `json:"cassettes"`
+	UpdatedAt  *time.Time  `json:"updated_at,omitempty"`
+}
+
+func NewCassettesResponse(inventory models.CashInventory) CassettesResponse {
+	resp := CassettesResponse{TerminalID: inventory.TerminalID, Cassettes: NewNotesViews(inventory.Cassettes)}
+	if resp.Cassettes == nil {
+		resp.Cassettes = []NotesView{}
+	}
+	if !inventory.UpdatedAt.IsZero() {
+		resp.UpdatedAt = &inventory.UpdatedAt
+	}
+	return resp
+}
+
+// CashMovementRequest is the body of POST /staff/terminals/{id}/loads and
+// POST /staff/terminals/{id}/unloads.
+type CashMovementRequest struct {
+	Notes []NotesRequest `json:"notes" binding:"required,min=1,max=8,dive"`
+}
+
+func (r CashMovementRequest) ToNotes() []models.Notes {
+	return toNotes(r.Notes)
+}
+
+// CashMovementResponse is returned when a load or unload is recorded, with
+// the cassettes after it.
+type CashMovementResponse struct {
+	ID         uuid.UUID               `json:"id"`
+	Type       models.CashMovementType `json:"type"`
+	Notes      []NotesView             `json:"notes"`
+	RecordedAt time.Time               `json:"recorded_at"`
+	Cassettes  []NotesView             `json:"cassettes"`
+}
+
+// ReconcileRequest is the body of POST /staff/terminals/{id}/reconciliations:
+// the notes counted in the ATM, cassettes and deposit bin together.
+type ReconcileRequest struct {
+	Counted []NotesRequest `json:"counted" binding:"max=16,dive"`
+}
+
+func (r ReconcileRequest) ToNotes() []models.Notes {
+	return toNotes(r.Counted)
+}
+
+// ReconciliationResponse is returned by POST
+// /staff/terminals/{id}/reconciliations.
+type ReconciliationResponse struct {
+	Reconciliation models.Reconciliation `json:"reconciliation"`
+}
+
+// ReconciliationsResponse is returned by GET
+// /staff/terminals/{id}/reconciliations, newest first.
+type ReconciliationsResponse struct {
+	Reconciliations []models.Reconciliation `json:"reconciliations"`
+}
@@ -0,0 +1,9 @@
+package dto
+
+import "newapiprojet/models"
+
+// DormancyReportsResponse is returned by GET /staff/reports/dormancy,
+// newest report first.
+type DormancyReportsResponse struct {
+	Reports []models.DormancyReport `json:"reports"`
+}
@@ -0,0 +1,57 @@
+package dto
+
+import (
+	"newapiprojet/models"
+	"newapiprojet/money"
+	"time"
+)
+
+// ExchangeRateRequest is the body of POST /admin/rates. EffectiveAt defaults
+// to now; a future time schedules the rate.
+type ExchangeRateRequest struct {
+	Base        string     `json:"base" binding:"required,currency"`
+	Quote       string     `json:"quote" binding:"required,currency,nefield=Base"`
+	Rate        string     `json:"rate" binding:"required"`
+	SpreadBps   int64      `json:"spread_bps" binding:"gte=0,lte=5000"`
+	EffectiveAt *time.Time `json:"effective_at"`
+}
+
+func (r ExchangeRateRequest) ToExchangeRate() models.ExchangeRate {
+	// Both codes were checked by the currency validator.
+	base, _ := money.ParseCurrency(r.Base)
+	quote, _ := money.ParseCurrency(r.Quote)
+	rate := models.ExchangeRate{
+		Base:      base,
+		Quote:     quote,
+		Rate:      r.Rate,
+		SpreadBps: r.SpreadBps,
+	}
+	if r.EffectiveAt != nil {
+		rate.EffectiveAt = *r.EffectiveAt
+	}
+	return rate
+}
+
+// ExchangeRateView is the public representation of an exchange rate.
+type ExchangeRateView struct {
+	Base        money.Currency `json:"base"`
+	Quote       money.Currency `json:"quote"`
+	Rate        string         `json:"rate"`
+	SpreadBps   int64          `json:"spread_bps"`
+	EffectiveAt time.Time      `json:"effective_at"`
+}
+
+func NewExchangeRateView(rate models.ExchangeRate) ExchangeRateView {
+	return ExchangeRateView{
+		Base:        rate.Base,
+		Quote:       rate.Quote,
+		Rate:        rate.Rate,
+		SpreadBps:   rate.SpreadBps,
+		EffectiveAt: rate.EffectiveAt,
+	}
+}
+
+// ExchangeRatesResponse is returned by GET /rates.
+type ExchangeRatesResponse struct {
+	Rates []ExchangeRateView `json:"rates"`
+}
@@ -0,0 +1,109 @@
+package dto
+
+import (
+	"newapiprojet/models"
+	"newapiprojet/money"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// FeeRuleRequest is one rule of a fee schedule; see models.FeeRule.
+type FeeRuleRequest struct {
+	Name         string       `json:"name" binding:"required,max=50"`
+	Operation    string       `json:"operation" binding:"required,oneof=withdrawal balance_inquiry transfer"`
+	ForeignOnly  bool         `json:"foreign_only"`
+	FreePerMonth int          `json:"free_per_month" binding:"gte=0"`
+	Fixed        money.Money  `json:"fixed"`
+	RateBps      int64        `json:"rate_bps" binding:"gte=0,lte=10000"`
+	Max          *money.Money `json:"max"`
+}
+
+// FeeScheduleRequest is the body of PUT /admin/fees/{currency}. It replaces
+// every rule for the currency; an empty list removes all fees.
+type FeeScheduleRequest struct {
+	Rules []FeeRuleRequest `json:"rules" binding:"dive"`
+}
+
+// CurrencyParam is the {currency} path parameter.
+type CurrencyParam struct {
+	Currency string `uri:"currency" binding:"required,currency"`
+}
+
+func (r FeeScheduleRequest) ToFeeSchedule(currency money.Currency) models.FeeSchedule {
+	schedule := models.FeeSchedule{Currency: currency, Rules: make([]models.FeeRule, 0, len(r.Rules))}
+	for _, rule := range r.Rules {
+		schedule.Rules = append(schedule.Rules, models.FeeRule{
+			Name:         rule.Name,
+			Operation:    models.FeeOperation(rule.Operation),
+			ForeignOnly:  rule.ForeignOnly,
+			FreePerMonth: rule.FreePerMonth,
+			Fixed:        rule.Fixed,
+			RateBps:      rule.RateBps,
+			Max:          rule.Max,
+		})
+	}
+	return schedule
+}
+
+// FeeRuleView is the public representation of a fee rule.
+type FeeRuleView struct {
+	Name         string              `json:"name"`
+	Operation    models.FeeOperation `json:"operation"`
+	ForeignOnly  bool                `json:"foreign_only"`
+	FreePerMonth int                 `json:"free_per_month"`
+	Fixed        money.Money         `json:"fixed"`
+	RateBps      int64               `json:"rate_bps"`
+	Max          *money.Money        `json:"max,omitempty"`
+}
+
+// FeeScheduleView is the public representation of a fee schedule.
+type FeeScheduleView struct {
+	Currency  money.Currency `json:"currency"`
+	Rules     []FeeRuleView  `json:"rules"`
+	UpdatedAt time.Time      `json:"updated_at"`
+}
+
+func NewFeeScheduleView(schedule models.FeeSchedule) FeeScheduleView {
+	view := FeeScheduleView{
+		Currency:  schedule.Currency,
+		Rules:     make([]FeeRuleView, 0, len(schedule.Rules)),
+		UpdatedAt: schedule.UpdatedAt,
+	}
+	for _, rule := range schedule.Rules {
+		view.Rules = append(view.Rules, FeeRuleView{
+			Name:         rule.Name,
+			Operation:    rule.Operation,
+			ForeignOnly:  rule.ForeignOnly,
+			FreePerMonth: rule.FreePerMonth,
+			Fixed:        rule.Fixed,
+			RateBps:      rule.RateBps,
+			Max:          rule.Max,
+		})
+	}
+	return view
+}
+
+// FeeSchedulesResponse is returned by GET /admin/fees.
+type FeeSchedulesResponse struct {
+	Schedules []FeeScheduleView `json:"schedules"`
+}
+
+// FeeView is a fee charged with an operation.
+type FeeView struct {
+	ID     uuid.UUID   `json:"id"`
+	Rule   string      `json:"rule"`
+	Amount money.Money `json:"amount"`
+}
+
+// NewFeeViews returns nil when no fee was charged.
+func NewFeeViews(fees []models.Fee) []FeeView {
+	if len(fees) == 0 {
+		return nil
+	}
+	views := make([]FeeView, len(fees))
+	for i, fee := range fees {
+		views[i] = FeeView{ID: fee.ID, Rule: fee.Rule, Amount: fee.Amount}
+	}
+	return views
+}
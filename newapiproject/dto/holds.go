@@ -0,0 +1,69 @@
+package dto
+
+import (
+	"newapiprojet/models"
+	"newapiprojet/money"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// HoldRequest is the body of POST /account/holds. Amount must be in the
+// account currency. The hold expires after ExpiresInSeconds, 15 minutes by
+// default.
+type HoldRequest struct {
+	AccountID        uuid.UUID   `json:"accountID" binding:"required"`
+	Amount           money.Money `json:"amount" binding:"amount"`
+	Reference        string      `json:"reference" binding:"max=64"`
+	ExpiresInSeconds int         `json:"expires_in_seconds" binding:"gte=0,lte=604800"`
+}
+
+// CaptureHoldRequest is the body of POST /account/holds/{id}/capture.
+// Without an amount the whole hold is captured.
+type CaptureHoldRequest struct {
+	Amount *money.Money `json:"amount" binding:"omitempty,amount"`
+}
+
+// HoldView is the public representation of a hold.
+type HoldView struct {
+	ID           uuid.UUID         `json:"id"`
+	AccountID    uuid.UUID         `json:"account_id"`
+	Amount       money.Money       `json:"amount"`
+	Reference    string            `json:"reference,omitempty"`
+	Status       models.HoldStatus `json:"status"`
+	CreatedAt    time.Time         `json:"created_at"`
+	ExpiresAt    time.Time         `json:"expires_at"`
+	Captured     *money.Money      `json:"captured,omitempty"`
+	WithdrawalID *uuid.UUID        `json:"withdrawal_id,omitempty"`
+	ClosedAt     *time.Time        `json:"closed_at,omitempty"`
+}
+
+func NewHoldView(hold models.Hold) HoldView {
+	return HoldView{
+		ID:           hold.ID,
+		AccountID:    hold.AccountID,
+		Amount:       hold.Amount,
+		Reference:    hold.Reference,
+		Status:       hold.Status,
+		CreatedAt:    hold.CreatedAt,
+		ExpiresAt:    hold.ExpiresAt,
+		Captured:     hold.Captured,
+		WithdrawalID: hold.WithdrawalID,
+		ClosedAt:     hold.ClosedAt,
+	}
+}
+
+// HoldResponse is returned when a hold is placed, looked up, captured or
+// released. Balance and AvailableBalance are the account's after the
+// change.
+type HoldResponse struct {
+	Message          string      `json:"message,omitempty"`
+	Hold             HoldView    `json:"hold"`
+	Balance          money.Money `json:"balance"`
+	AvailableBalance money.Money `json:"available_balance"`
+	// Notes are the banknotes to dispense when a hold is captured at an
+	// ATM.
+	Notes []NotesView `json:"notes,omitempty"`
+	// Fees are the fees charged when a hold is captured.
+	Fees []FeeView `json:"fees,omitempty"`
+}
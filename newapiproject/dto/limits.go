@@ -0,0 +1,84 @@
+package dto
+
+import (
+	"newapiprojet/limits"
+	"newapiprojet/models"
+	"newapiprojet/money"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// WithdrawalLimitsRequest is the body of PUT /staff/accounts/{id}/limits.
+// Amounts are in the account currency; zero disables a limit.
+type WithdrawalLimitsRequest struct {
+	PerTransaction money.Money `json:"per_transaction"`
+	Daily          money.Money `json:"daily"`
+	DailyCount     int         `json:"daily_count" binding:"gte=0"`
+}
+
+func (r WithdrawalLimitsRequest) ToWithdrawalLimits() models.WithdrawalLimits {
+	return models.WithdrawalLimits{
+		PerTransaction: r.PerTransaction,
+		Daily:          r.Daily,
+		DailyCount:     r.DailyCount,
+	}
+}
+
+// WithdrawalLimitsView shows an account's configured limits.
+type WithdrawalLimitsView struct {
+	PerTransaction money.Money `json:"per_transaction"`
+	Daily          money.Money `json:"daily"`
+	DailyCount     int         `json:"daily_count"`
+}
+
+func NewWithdrawalLimitsView(l models.WithdrawalLimits) WithdrawalLimitsView {
+	return WithdrawalLimitsView{
+		PerTransaction: l.PerTransaction,
+		Daily:          l.Daily,
+		DailyCount:     l.DailyCount,
+	}
+}
+
+// WithdrawalUsageView shows what was withdrawn on the current business day.
+type WithdrawalUsageView struct {
+	Withdrawn money.Money `json:"withdrawn"`
+	Count     int         `json:"count"`
+}
+
+// RemainingLimitsView is what may still be withdrawn today. A null field
+// means the limit is disabled.
+type RemainingLimitsView struct {
+	PerTransaction *money.Money `json:"per_transaction"`
+	Daily          *money.Money `json:"daily"`
+	DailyCount     *int         `json:"daily_count"`
+}
+
+// WithdrawalLimitsResponse is returned by GET /account/limits/{accountID}
+// and PUT /staff/accounts/{id}/limits.
+type WithdrawalLimitsResponse struct {
+	AccountID   uuid.UUID            `json:"accountID"`
+	BusinessDay string               `json:"business_day"`
+	ResetsAt    time.Time            `json:"resets_at"`
+	Limits      WithdrawalLimitsView `json:"limits"`
+	Used        WithdrawalUsageView  `json:"used"`
+	Remaining   RemainingLimitsView  `json:"remaining"`
+}
+
+func NewWithdrawalLimitsResponse(accountID uuid.UUID, r limits.Remaining) WithdrawalLimitsResponse {
+	return WithdrawalLimitsResponse{
+		AccountID:   accountID,
+		BusinessDay: r.BusinessDay,
+		ResetsAt:    r.ResetsAt,
+		Limits:      NewWithdrawalLimitsView(r.Limits),
+		Used: WithdrawalUsageView{
+			Withdrawn: r.Usage.Withdrawn,
+			Count:     r.Usage.Count,
+		},
+		Remaining: RemainingLimitsView{
+			PerTransaction: r.PerTransaction,
+			Daily:          r.Daily,
+			DailyCount:     r.DailyCount,
+		},
+	}
+}
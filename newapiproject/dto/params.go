@@ -0,0 +1,13 @@
+package dto
+
+import "github.com/google/uuid"
+
+// IDParam is the {id} path parameter used by user and account routes.
+type IDParam struct {
+	ID string `uri:"id" binding:"required,uuid"`
+}
+
+// UUID returns the validated ID. Only call it after a successful bind.
+func (p IDParam) UUID() uuid.UUID {
+	return uuid.MustParse(p.ID)
+}
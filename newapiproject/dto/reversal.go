@@ -0,0 +1,54 @@
+package dto
+
+import (
+	"newapiprojet/models"
+	"newapiprojet/money"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ReversalRequest is the body of POST /staff/deposits/{id}/reversal and
+// POST /staff/withdrawals/{id}/reversal.
+type ReversalRequest struct {
+	Reason string `json:"reason" binding:"required,oneof=duplicate wrong_account wrong_amount dispense_failure fraud other"`
+	Note   string `json:"note" binding:"max=200"`
+}
+
+// ReversalView is the public representation of a reversal.
+type ReversalView struct {
+	ID              uuid.UUID             `json:"id"`
+	TransactionType models.ReversibleType `json:"transaction_type"`
+	TransactionID   uuid.UUID             `json:"transaction_id"`
+	AccountID       uuid.UUID             `json:"account_id"`
+	Amount          money.Money           `json:"amount"`
+	FeesRefunded    *money.Money          `json:"fees_refunded,omitempty"`
+	Reason          models.ReversalReason `json:"reason"`
+	Note            string                `json:"note,omitempty"`
+	ReversedBy      uuid.UUID             `json:"reversed_by"`
+	ReversedAt      time.Time             `json:"reversed_at"`
+}
+
+func NewReversalView(r models.Reversal) ReversalView {
+	return ReversalView{
+		ID:              r.ID,
+		TransactionType: r.TransactionType,
+		TransactionID:   r.TransactionID,
+		AccountID:       r.AccountID,
+		Amount:          r.Amount,
+		FeesRefunded:    r.FeesRefunded,
+		Reason:          r.Reason,
+		Note:            r.Note,
+		ReversedBy:      r.ReversedBy,
+		ReversedAt:      r.ReversedAt,
+	}
+}
+
+// ReversalResponse is returned when a transaction is reversed. Balance is
+// the account balance after the reversal.
+type ReversalResponse struct {
+	Message          string       `json:"message"`
+	Reversal         ReversalView `json:"reversal"`
+	Balance          money.Money  `json:"balance"`
+	BalanceFormatted string       `json:"balance_formatted"`
+}
@@ -0,0 +1,78 @@
+package dto
+
+import (
+	"newapiprojet/models"
+	"newapiprojet/money"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// StandingOrderRequest is the body of POST /account/standing-orders.
+// Schedule is a five-field cron expression in the bank's time zone. Amount
+// must be in the currency of one of the two accounts.
+type StandingOrderRequest struct {
+	FromAccountID       uuid.UUID   `json:"fromAccountID" binding:"required"`
+	ToAccountID         uuid.UUID   `json:"toAccountID" binding:"required"`
+	Amount              money.Money `json:"amount" binding:"amount"`
+	Schedule            string      `json:"schedule" binding:"required,cron"`
+	OnInsufficientFunds string      `json:"on_insufficient_funds" binding:"omitempty,oneof=retry skip"`
+	MaxRetries          int         `json:"max_retries" binding:"gte=0,lte=10"`
+}
+
+// Policy returns the requested insufficient-funds policy, retry by default.
+func (r StandingOrderRequest) Policy() models.InsufficientFundsPolicy {
+	if r.OnInsufficientFunds == "" {
+		return models.RetryOnInsufficientFunds
+	}
+	return models.InsufficientFundsPolicy(r.OnInsufficientFunds)
+}
+
+// StandingOrderView is the public representation of a standing order.
+type StandingOrderView struct {
+	ID                  uuid.UUID                      `json:"id"`
+	FromAccountID       uuid.UUID                      `json:"from_account_id"`
+	ToAccountID         uuid.UUID                      `json:"to_account_id"`
+	Amount              money.Money                    `json:"amount"`
+	Schedule            string                         `json:"schedule"`
+	Status              models.StandingOrderStatus     `json:"status"`
+	OnInsufficientFunds models.InsufficientFundsPolicy `json:"on_insufficient_funds"`
+	MaxRetries          int                            `json:"max_retries"`
+	Attempts            int                            `json:"attempts"`
+	NextRunAt           *time.Time                     `json:"next_run_at,omitempty"`
+	LastRun             *models.StandingOrderRun       `json:"last_run,omitempty"`
+	CreatedAt           time.Time                      `json:"created_at"`
+}
+
+func NewStandingOrderView(order models.StandingOrder) StandingOrderView {
+	view := StandingOrderView{
+		ID:                  order.ID,
+		FromAccountID:       order.FromAccountID,
+		ToAccountID:         order.ToAccountID,
+		Amount:              order.Amount,
+		Schedule:            order.Schedule,
+		Status:              order.Status,
+		OnInsufficientFunds: order.OnInsufficientFunds,
+		MaxRetries:          order.MaxRetries,
+		Attempts:            order.Attempts,
+		LastRun:             order.LastRun,
+		CreatedAt:           order.CreatedAt,
+	}
+	// Only active orders are going to run.
+	if order.Status == models.StandingOrderActive && !order.NextRunAt.IsZero() {
+		next := order.NextRunAt
+		view.NextRunAt = &next
+	}
+	return view
+}
+
+// StandingOrderResponse is returned when a standing order is created or
+// changed.
+type StandingOrderResponse struct {
+	StandingOrder StandingOrderView `json:"standing_order"`
+}
+
+// StandingOrdersResponse is returned by GET /account/standing-orders.
+type StandingOrdersResponse struct {
+	StandingOrders []StandingOrderView `json:"standing_orders"`
+}
@@ -0,0 +1,84 @@
+package dto
+
+import (
+	"newapiprojet/models"
+	"newapiprojet/money"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// RegisterTerminalRequest is the body of POST /admin/terminals.
+// CertFingerprint is the hex SHA-256 of the terminal's client certificate,
+// if it has one.
+type RegisterTerminalRequest struct {
+	Location        string `json:"location" binding:"required,max=200"`
+	Branch          string `json:"branch" binding:"required,max=100"`
+	CertFingerprint string `json:"cert_fingerprint" binding:"omitempty,len=64,hexadecimal"`
+}
+
+// TerminalView is the public representation of a terminal, without its
+// secret.
+type TerminalView struct {
+	ID               uuid.UUID             `json:"id"`
+	Location         string                `json:"location"`
+	Branch           string                `json:"branch"`
+	Status           models.TerminalStatus `json:"status"`
+	CertFingerprint  string                `json:"cert_fingerprint,omitempty"`
+	RegisteredAt     time.Time             `json:"registered_at"`
+	DecommissionedAt *time.Time            `json:"decommissioned_at,omitempty"`
+}
+
+func NewTerminalView(t models.Terminal) TerminalView {
+	return TerminalView{
+		ID:               t.ID,
+		Location:         t.Location,
+		Branch:           t.Branch,
+		Status:           t.Status,
+		CertFingerprint:  t.CertFingerprint,
+		RegisteredAt:     t.RegisteredAt,
+		DecommissionedAt: t.DecommissionedAt,
+	}
+}
+
+// RegisterTerminalResponse is returned by POST /admin/terminals. It is the
+// only response that carries the terminal's shared secret.
+type RegisterTerminalResponse struct {
+	Terminal TerminalView `json:"terminal"`
+	Secret   string       `json:"secret"`
+}
+
+// TerminalResponse is returned when a terminal is decommissioned.
+type TerminalResponse struct {
+	Terminal TerminalView `json:"terminal"`
+}
+
+// HeartbeatRequest is the body of POST /terminal/heartbeat. Errors are the
+// error states the ATM is in, such as "cassette_jam"; empty when healthy.
+type HeartbeatRequest struct {
+	Errors []string `json:"errors" binding:"max=10,dive,required,max=64"`
+}
+
+// HeartbeatResponse tells the ATM how long it stays online without another
+// heartbeat.
+type HeartbeatResponse struct {
+	ReceivedAt time.Time `json:"received_at"`
+	TTLSeconds int       `json:"ttl_seconds"`
+}
+
+// TerminalStatusView is a terminal with its heartbeat state and cash.
+type TerminalStatusView struct {
+	TerminalView
+	Online     bool        `json:"online"`
+	LastSeenAt *time.Time  `json:"last_seen_at,omitempty"`
+	Errors     []string    `json:"errors,omitempty"`
+	Cash       []NotesView `json:"cash"`
+	// LowCash lists the currencies in which the cassettes hold less than
+	// the alert threshold.
+	LowCash []money.Currency `json:"low_cash,omitempty"`
+}
+
+// TerminalsResponse is returned by GET /admin/terminals.
+type TerminalsResponse struct {
+	Terminals []TerminalStatusView `json:"terminals"`
+}
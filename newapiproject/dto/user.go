@@ -0,0 +1,98 @@
+package dto
+
+import (
+	"newapiprojet/models"
+
+	"github.com/google/uuid"
+)
+
+// RegisterRequest is the body of POST /user/register.
+type RegisterRequest struct {
+	Username    string `json:"username" binding:"required,username"`
+	FirstName   string `json:"first_name" binding:"required,max=50"`
+	LastName    string `json:"last_name" binding:"required,max=50"`
+	PhoneNumber string `json:"phone_number" binding:"required,e164"`
+	PIN         string `json:"pin" binding:"required,pin"`
+	Language    string `json:"language,omitempty" binding:"omitempty,oneof=en tr"`
+}
+
+// ToUser builds a new storage user from the request. The caller assigns the ID.
+func (r RegisterRequest) ToUser() models.User {
+	return models.User{
+		Username:    r.Username,
+		FirstName:   r.FirstName,
+		LastName:    r.LastName,
+		PhoneNumber: r.PhoneNumber,
+		PIN:         r.PIN,
+		Language:    r.Language,
+	}
+}
+
+// RegisterResponse is returned by POST /user/register.
+type RegisterResponse struct {
+	User    UserView    `json:"user"`
+	Account AccountView `json:"account"`
+}
+
+// LoginRequest is the body of POST /user/login.
+type LoginRequest struct {
+	Username string `json:"username" binding:"required"`
+	PIN      string `json:"pin" binding:"required"`
+}
+
+// LoginResponse is returned by POST /user/login.
+type LoginResponse struct {
+	Token string `json:"token"`
+}
+
+// PinChangeRequest is the body of POST /account/pin-change/{id}.
+type PinChangeRequest struct {
+	OldPIN string `json:"oldPIN" binding:"required"`
+	NewPIN string `json:"newPIN" binding:"required,pin,nefield=OldPIN"`
+}
+
+// SetRoleRequest is the body of PUT /admin/users/{id}/role.
+type SetRoleRequest struct {
+	Role string `json:"role" binding:"required,oneof=customer teller admin"`
+}
+
+// UserResponse is returned by PUT /admin/users/{id}/role.
+type UserResponse struct {
+	User UserView `json:"user"`
+}
+
+// UserView is the public representation of a user. It never carries the PIN,
+// and the phone number is masked down to its last two digits.
+type UserView struct {
+	ID          uuid.UUID   `json:"id"`
+	Username    string      `json:"username"`
+	FirstName   string      `json:"first_name"`
+	LastName    string      `json:"last_name"`
+	PhoneNumber string      `json:"phone_number"`
+	Language    string      `json:"language,omitempty"`
+	Role        models.Role `json:"role,omitempty"`
+}
+
+func NewUserView(user models.User) UserView {
+	return UserView{
+		ID:          user.ID,
+		Username:    user.Username,
+		FirstName:   user.FirstName,
+		LastName:    user.LastName,
+		PhoneNumber: maskPhone(user.PhoneNumber),
+		Language:    user.Language,
+		Role:        user.Role,
+	}
+}
+
+func maskPhone(phone string) string {
+	if len(phone) <= 2 {
+		return phone
+	}
+	masked := make([]byte, len(phone))
+	for i := range masked {
+		masked[i] = '*'
+	}
+	copy(masked[len(phone)-2:], phone[len(phone)-2:])
+	return string(masked)
+}
@@ -2,8 +2,11 @@ package etcd
 
 import (
 	"context"
+	"fmt"
+	"time"
 
 	clientv3 "go.etcd.io/etcd/client/v3"
+	"go.etcd.io/etcd/client/v3/concurrency"
 )
 
 type EtcdClient struct {
@@ -44,6 +47,151 @@ func (e *EtcdClient) Post(ctx context.Context, key string, value []byte) error {
 	return err
 }
 
+func (e *EtcdClient) List(ctx context.Context, prefix string) (map[string][]byte, error) {
+	resp, err := e.client.Get(ctx, prefix, clientv3.WithPrefix())
+	if err != nil {
+		return nil, err
+	}
+	values := make(map[string][]byte, len(resp.Kvs))
+	for _, kv := range resp.Kvs {
+		values[string(kv.Key)] = kv.Value
+	}
+	return values, nil
+}
+
+func (e *EtcdClient) Range(ctx context.Context, start, end string) (map[string][]byte, error) {
+	resp, err := e.client.Get(ctx, start, clientv3.WithRange(end))
+	if err != nil {
+		return nil, err
+	}
+	values := make(map[string][]byte, len(resp.Kvs))
+	for _, kv := range resp.Kvs {
+		values[string(kv.Key)] = kv.Value
+	}
+	return values, nil
+}
+
+func (e *EtcdClient) Latest(ctx context.Context, start, end string) (string, []byte, error) {
+	resp, err := e.client.Get(ctx, start, clientv3.WithRange(end),
+		clientv3.WithSort(clientv3.SortByKey, clientv3.SortDescend), clientv3.WithLimit(1))
+	if err != nil {
+		return "", nil, err
+	}
+	if len(resp.Kvs) == 0 {
+		return "", nil, nil
+	}
+	return string(resp.Kvs[0].Key), resp.Kvs[0].Value, nil
+}
+
+// PutWithTTL stores key under a new lease that runs out after ttl.
+func (e *EtcdClient) PutWithTTL(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	seconds := int64((ttl + time.Second - 1) / time.Second)
+	lease, err := e.client.Grant(ctx, seconds)
+	if err != nil {
+		return fmt.Errorf("granting lease: %w", err)
+	}
+	_, err = e.client.Put(ctx, key, string(value), clientv3.WithLease(lease.ID))
+	return err
+}
+
+// CreateWithTTL stores key under a new lease that runs out after ttl, in a
+// transaction that only succeeds if key does not exist.
+func (e *EtcdClient) CreateWithTTL(ctx context.Context, key string, value []byte, ttl time.Duration) (bool, error) {
+	seconds := int64((ttl + time.Second - 1) / time.Second)
+	lease, err := e.client.Grant(ctx, seconds)
+	if err != nil {
+		return false, fmt.Errorf("granting lease: %w", err)
+	}
+	resp, err := e.client.Txn(ctx).
+		If(clientv3.Compare(clientv3.CreateRevision(key), "=", 0)).
+		Then(clientv3.OpPut(key, string(value), clientv3.WithLease(lease.ID))).
+		Commit()
+	if err != nil {
+		return false, err
+	}
+	if !resp.Succeeded {
+		// Nothing is bound to the lease; it would run out on its own.
+		e.client.Revoke(ctx, lease.ID)
+	}
+	return resp.Succeeded, nil
+}
+
+// KeepAlive renews lease for its time to live, or stores key under a new
+// lease with ttl if it is zero or has run out.
+func (e *EtcdClient) KeepAlive(ctx context.Context, key string, value []byte, lease int64, ttl time.Duration) (int64, error) {
+	if lease != 0 {
+		if _, err := e.client.KeepAliveOnce(ctx, clientv3.LeaseID(lease)); err == nil {
+			return lease, nil
+		}
+	}
+	seconds := int64((ttl + time.Second - 1) / time.Second)
+	granted, err := e.client.Grant(ctx, seconds)
+	if err != nil {
+		return 0, fmt.Errorf("granting lease: %w", err)
+	}
+	if _, err := e.client.Put(ctx, key, string(value), clientv3.WithLease(granted.ID)); err != nil {
+		return 0, err
+	}
+	return int64(granted.ID), nil
+}
+
+// WatchDeletes calls fn with every key under prefix that is deleted until
+// ctx is done. Deletes made while nothing was watching are not reported.
+func (e *EtcdClient) WatchDeletes(ctx context.Context, prefix string, fn func(key string)) error {
+	watch := e.client.Watch(clientv3.WithRequireLeader(ctx), prefix, clientv3.WithPrefix(), clientv3.WithFilterPut())
+	for resp := range watch {
+		if err := resp.Err(); err != nil {
+			return fmt.Errorf("watching %s: %w", prefix, err)
+		}
+		for _, event := range resp.Events {
+			if event.Type == clientv3.EventTypeDelete {
+				fn(string(event.Kv.Key))
+			}
+		}
+	}
+	return ctx.Err()
+}
+
+// STM runs apply in a software transactional memory transaction, retrying it
+// on conflicting writes.
+func (e *EtcdClient) STM(ctx context.Context, apply func(concurrency.STM) error) error {
+	_, err := concurrency.NewSTM(e.client, apply, concurrency.WithAbortContext(ctx))
+	return err
+}
+
+// leaderTTL is how long a crashed leader keeps its leadership.
+const leaderTTL = 15 * time.Second
+
+// Lead campaigns as candidate in the election called name and, once
+// elected, runs fn. fn's context is cancelled if leadership is lost, e.g.
+// because this process could not reach etcd for leaderTTL. Leadership is
+// given up when fn returns.
+func (e *EtcdClient) Lead(ctx context.Context, name, candidate string, fn func(ctx context.Context)) error {
+	session, err := concurrency.NewSession(e.client, concurrency.WithTTL(int(leaderTTL/time.Second)), concurrency.WithContext(ctx))
+	if err != nil {
+		return fmt.Errorf("creating election session: %w", err)
+	}
+	defer session.Close()
+
+	election := concurrency.NewElection(session, "elections/"+name)
+	if err := election.Campaign(ctx, candidate); err != nil {
+		return fmt.Errorf("campaigning for %s: %w", name, err)
+	}
+
+	leaderCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	go func() {
+		select {
+		case <-session.Done():
+			cancel()
+		case <-leaderCtx.Done():
+		}
+	}()
+
+	fn(leaderCtx)
+	return nil
+}
+
 func (e *EtcdClient) Close() error {
 	return e.client.Close()
 }
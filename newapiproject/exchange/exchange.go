@@ -0,0 +1,228 @@
+package exchange
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+
+	"newapiprojet/database"
+	"newapiprojet/models"
+	"newapiprojet/money"
+)
+
+// Rates are stored one key per pair and effective time, so history is kept
+// and a rate can be scheduled ahead of time:
+//
+//	rates/<BASE>/<QUOTE>/<effective unix nanos, zero-padded>
+const ratesPrefix = "rates/"
+
+// maxSpreadBps keeps the applied rate positive.
+const maxSpreadBps = 5000
+
+var (
+	ErrRateUnavailable = errors.New("exchange: no rate for currency pair")
+	ErrInvalidRate     = errors.New("exchange: invalid rate")
+
+	ratePattern = regexp.MustCompile(`^\d+(\.\d{1,8})?$`)
+)
+
+// Direction says which side of the spread the customer is on.
+type Direction int
+
+const (
+	// Credit converts money the customer receives; the spread lowers the result.
+	Credit Direction = iota
+	// Debit converts money the customer pays; the spread raises the result.
+	Debit
+)
+
+type Service struct {
+	db  database.Database
+	now func() time.Time
+}
+
+func NewService(db database.Database) *Service {
+	return &Service{db: db, now: time.Now}
+}
+
+func rateKey(base, quote money.Currency, at time.Time) string {
+	return fmt.Sprintf("%s%s/%s/%020d", ratesPrefix, base, quote, at.UnixNano())
+}
+
+// SetRate validates and stores rate. A zero EffectiveAt means now.
+func (s *Service) SetRate(ctx context.Context, rate models.ExchangeRate) (models.ExchangeRate, error) {
+	if !rate.Base.Valid() || !rate.Quote.Valid() || rate.Base == rate.Quote {
+		return rate, fmt.Errorf("%w: pair %s/%s", ErrInvalidRate, rate.Base, rate.Quote)
+	}
+	if _, err := parseRate(rate.Rate); err != nil {
+		return rate, err
+	}
+	if rate.SpreadBps < 0 || rate.SpreadBps > maxSpreadBps {
+		return rate, fmt.Errorf("%w: spread must be between 0 and %d bps", ErrInvalidRate, maxSpreadBps)
+	}
+	if rate.EffectiveAt.IsZero() {
+		rate.EffectiveAt = s.now()
+	}
+	rate.EffectiveAt = rate.EffectiveAt.UTC()
+
+	data, err := json.Marshal(rate)
+	if err != nil {
+		return rate, fmt.Errorf("marshaling exchange rate: %w", err)
+	}
+	if err := s.db.Put(ctx, rateKey(rate.Base, rate.Quote, rate.EffectiveAt), data); err != nil {
+		return rate, fmt.Errorf("storing exchange rate: %w", err)
+	}
+	return rate, nil
+}
+
+// Current returns the rates in effect now, one per stored pair, sorted by
+// base and quote.
+func (s *Service) Current(ctx context.Context) ([]models.ExchangeRate, error) {
+	var rates []models.ExchangeRate
+	for _, base := range money.Currencies() {
+		for _, quote := range money.Currencies() {
+			if base == quote {
+				continue
+			}
+			rate, ok, err := s.lookup(ctx, base, quote)
+			if err != nil {
+				return nil, err
+			}
+			if ok {
+				rates = append(rates, rate)
+			}
+		}
+	}
+	return rates, nil
+}
+
+// lookup returns the rate for base/quote in effect now.
+func (s *Service) lookup(ctx context.Context, base, quote money.Currency) (models.ExchangeRate, bool, error) {
+	// Keys sort by effective time, so the greatest key up to now's is the
+	// rate in effect; "\x00" makes the range include a rate effective now.
+	prefix := fmt.Sprintf("%s%s/%s/", ratesPrefix, base, quote)
+	key, data, err := s.db.Latest(ctx, prefix, rateKey(base, quote, s.now())+"\x00")
+	if err != nil {
+		return models.ExchangeRate{}, false, fmt.Errorf("reading exchange rate: %w", err)
+	}
+	if key == "" {
+		return models.ExchangeRate{}, false, nil
+	}
+
+	var rate models.ExchangeRate
+	if err := json.Unmarshal(data, &rate); err != nil {
+		return models.ExchangeRate{}, false, fmt.Errorf("unmarshaling exchange rate %s: %w", key, err)
+	}
+	return rate, true, nil
+}
+
+// Convert prices amount in currency to. A stored to/from rate is used
+// inverted when there is no from/to rate. The result is rounded against the
+// customer: down for credits and up for debits.
+func (s *Service) Convert(ctx context.Context, amount money.Money, to money.Currency, dir Direction) (models.Conversion, error) {
+	from := amount.Currency()
+	rate, ok, err := s.lookup(ctx, from, to)
+	if err != nil {
+		return models.Conversion{}, err
+	}
+	inverted := false
+	if !ok {
+		rate, ok, err = s.lookup(ctx, to, from)
+		if err != nil {
+			return models.Conversion{}, err
+		}
+		if !ok {
+			return models.Conversion{}, fmt.Errorf("%w: %s/%s", ErrRateUnavailable, from, to)
+		}
+		inverted = true
+	}
+
+	mid, err := parseRate(rate.Rate)
+	if err != nil {
+		return models.Conversion{}, err
+	}
+	if inverted {
+		mid.Inv(mid)
+	}
+
+	spread := big.NewRat(10000-rate.SpreadBps, 10000)
+	if dir == Debit {
+		spread = big.NewRat(10000+rate.SpreadBps, 10000)
+	}
+	applied := new(big.Rat).Mul(mid, spread)
+
+	// minor(to) = minor(from) * applied * 10^units(to) / 10^units(from)
+	scaled := new(big.Rat).SetInt64(amount.Minor())
+	scaled.Mul(scaled, applied)
+	scaled.Mul(scaled, new(big.Rat).SetInt(pow10(to.MinorUnits())))
+	scaled.Quo(scaled, new(big.Rat).SetInt(pow10(from.MinorUnits())))
+
+	minor := round(scaled, dir == Debit)
+	if !minor.IsInt64() {
+		return models.Conversion{}, money.ErrOverflow
+	}
+
+	return models.Conversion{
+		From:        amount,
+		To:          money.New(minor.Int64(), to),
+		Rate:        trimRate(mid.FloatString(8)),
+		AppliedRate: trimRate(applied.FloatString(8)),
+		SpreadBps:   rate.SpreadBps,
+		EffectiveAt: rate.EffectiveAt,
+	}, nil
+}
+
+// LoadFile stores every rate in a JSON array of models.ExchangeRate. Rates
+// already present under the same pair and effective time are overwritten.
+func (s *Service) LoadFile(ctx context.Context, path string) (int, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0, err
+	}
+	var rates []models.ExchangeRate
+	if err := json.Unmarshal(data, &rates); err != nil {
+		return 0, fmt.Errorf("parsing %s: %w", path, err)
+	}
+	for i, rate := range rates {
+		if _, err := s.SetRate(ctx, rate); err != nil {
+			return i, fmt.Errorf("rate %d in %s: %w", i, path, err)
+		}
+	}
+	return len(rates), nil
+}
+
+func parseRate(s string) (*big.Rat, error) {
+	if !ratePattern.MatchString(s) {
+		return nil, fmt.Errorf("%w: %q", ErrInvalidRate, s)
+	}
+	rate, ok := new(big.Rat).SetString(s)
+	if !ok || rate.Sign() <= 0 {
+		return nil, fmt.Errorf("%w: %q", ErrInvalidRate, s)
+	}
+	return rate, nil
+}
+
+// round converts r to an integer, rounding up when up is set and down
+// otherwise. r is never negative here.
+func round(r *big.Rat, up bool) *big.Int {
+	q, m := new(big.Int).QuoRem(r.Num(), r.Denom(), new(big.Int))
+	if up && m.Sign() != 0 {
+		q.Add(q, big.NewInt(1))
+	}
+	return q
+}
+
+func pow10(n int) *big.Int {
+	return new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(n)), nil)
+}
+
+func trimRate(s string) string {
+	s = strings.TrimRight(s, "0")
+	return strings.TrimSuffix(s, ".")
+}
@@ -0,0 +1,136 @@
+package exchange
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"newapiprojet/database/memdb"
+	"newapiprojet/models"
+	"newapiprojet/money"
+)
+
+func newTestService(t *testing.T, rates ...models.ExchangeRate) *Service {
+	t.Helper()
+	now := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+	s := NewService(memdb.New())
+	s.now = func() time.Time { return now }
+	for _, rate := range rates {
+		if rate.EffectiveAt.IsZero() {
+			rate.EffectiveAt = now.Add(-time.Hour)
+		}
+		if _, err := s.SetRate(context.Background(), rate); err != nil {
+			t.Fatalf("SetRate(%+v): %v", rate, err)
+		}
+	}
+	return s
+}
+
+func TestConvertRounding(t *testing.T) {
+	s := newTestService(t,
+		models.ExchangeRate{Base: money.USD, Quote: money.TRY, Rate: "34.2567", SpreadBps: 150},
+		models.ExchangeRate{Base: money.USD, Quote: money.JPY, Rate: "149.5", SpreadBps: 0},
+	)
+	tests := []struct {
+		name   string
+		amount money.Money
+		to     money.Currency
+		dir    Direction
+		want   money.Money
+	}{
+		// 1.00 USD * 34.2567 * 0.985 = 33.74284950 TRY, rounded down.
+		{"credit rounds down", money.New(100, money.USD), money.TRY, Credit, money.New(3374, money.TRY)},
+		// 1.00 USD * 34.2567 * 1.015 = 34.77055050 TRY, rounded up.
+		{"debit rounds up", money.New(100, money.USD), money.TRY, Debit, money.New(3478, money.TRY)},
+		// 100.00 TRY / 34.2567 * 0.985 = 2.87534... USD, rounded down.
+		{"inverted credit rounds down", money.New(10000, money.TRY), money.USD, Credit, money.New(287, money.USD)},
+		// 100.00 TRY / 34.2567 * 1.015 = 2.96291... USD, rounded up.
+		{"inverted debit rounds up", money.New(10000, money.TRY), money.USD, Debit, money.New(297, money.USD)},
+		// 0.01 USD * 149.5 = 1.495 JPY: no minor units to keep the half yen.
+		{"credit to zero-decimal currency", money.New(1, money.USD), money.JPY, Credit, money.New(1, money.JPY)},
+		{"debit to zero-decimal currency", money.New(1, money.USD), money.JPY, Debit, money.New(2, money.JPY)},
+		// 299 JPY / 149.5 = 2.00 USD exactly: nothing to round.
+		{"exact", money.New(299, money.JPY), money.USD, Debit, money.New(200, money.USD)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := s.Convert(context.Background(), tt.amount, tt.to, tt.dir)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got.To != tt.want {
+				t.Errorf("Convert(%v to %s) = %v, want %v", tt.amount, tt.to, got.To, tt.want)
+			}
+			if got.From != tt.amount {
+				t.Errorf("From = %v, want %v", got.From, tt.amount)
+			}
+		})
+	}
+}
+
+func TestConvertUsesRateInEffect(t *testing.T) {
+	now := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+	s := newTestService(t,
+		models.ExchangeRate{Base: money.EUR, Quote: money.TRY, Rate: "30", EffectiveAt: now.Add(-48 * time.Hour)},
+		models.ExchangeRate{Base: money.EUR, Quote: money.TRY, Rate: "35", EffectiveAt: now.Add(-time.Hour)},
+		models.ExchangeRate{Base: money.EUR, Quote: money.TRY, Rate: "40", EffectiveAt: now.Add(time.Hour)},
+	)
+	got, err := s.Convert(context.Background(), money.New(100, money.EUR), money.TRY, Credit)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := money.New(3500, money.TRY); got.To != want {
+		t.Errorf("Convert = %v, want %v at the rate in effect", got.To, want)
+	}
+	if got.Rate != "35" {
+		t.Errorf("Rate = %q, want %q", got.Rate, "35")
+	}
+}
+
+func TestConvertErrors(t *testing.T) {
+	s := newTestService(t, models.ExchangeRate{Base: money.USD, Quote: money.TRY, Rate: "34.25"})
+	if _, err := s.Convert(context.Background(), money.New(100, money.GBP), money.TRY, Credit); !errors.Is(err, ErrRateUnavailable) {
+		t.Errorf("Convert without a rate: error = %v, want %v", err, ErrRateUnavailable)
+	}
+
+	tests := []models.ExchangeRate{
+		{Base: money.USD, Quote: money.USD, Rate: "1"},
+		{Base: money.USD, Quote: money.TRY, Rate: "0"},
+		{Base: money.USD, Quote: money.TRY, Rate: "-1"},
+		{Base: money.USD, Quote: money.TRY, Rate: "1/3"},
+		{Base: money.USD, Quote: money.TRY, Rate: "34.25", SpreadBps: -1},
+		{Base: money.USD, Quote: money.TRY, Rate: "34.25", SpreadBps: maxSpreadBps + 1},
+	}
+	for _, rate := range tests {
+		if _, err := s.SetRate(context.Background(), rate); !errors.Is(err, ErrInvalidRate) {
+			t.Errorf("SetRate(%+v) error = %v, want %v", rate, err, ErrInvalidRate)
+		}
+	}
+}
+
+func TestCurrent(t *testing.T) {
+	now := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+	s := newTestService(t,
+		models.ExchangeRate{Base: money.USD, Quote: money.TRY, Rate: "34", EffectiveAt: now.Add(-48 * time.Hour)},
+		models.ExchangeRate{Base: money.USD, Quote: money.TRY, Rate: "34.5", EffectiveAt: now},
+		models.ExchangeRate{Base: money.USD, Quote: money.TRY, Rate: "35", EffectiveAt: now.Add(time.Hour)},
+		models.ExchangeRate{Base: money.EUR, Quote: money.TRY, Rate: "37", EffectiveAt: now.Add(-time.Hour)},
+		// Only scheduled, so not yet current.
+		models.ExchangeRate{Base: money.GBP, Quote: money.TRY, Rate: "44", EffectiveAt: now.Add(time.Hour)},
+	)
+	rates, err := s.Current(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, rate := range rates {
+		got = append(got, string(rate.Base)+"/"+string(rate.Quote)+" "+rate.Rate)
+	}
+	// A rate effective exactly now is in effect.
+	want := []string{"EUR/TRY 37", "USD/TRY 34.5"}
+	if !slices.Equal(got, want) {
+		t.Errorf("Current = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,248 @@
+package fees
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+	"time"
+
+	"newapiprojet/calendar"
+	"newapiprojet/database"
+	"newapiprojet/ledger"
+	"newapiprojet/models"
+	"newapiprojet/money"
+
+	"github.com/google/uuid"
+)
+
+// Fee data is stored as:
+//
+//	fee_schedules/<CURRENCY>              models.FeeSchedule
+//	fee_usage/<accountID>/<YYYY-MM>       matching operations per rule name
+//	fees/<feeID>                          models.Fee
+const (
+	schedulesPrefix = "fee_schedules/"
+	usagePrefix     = "fee_usage/"
+	feesPrefix      = "fees/"
+)
+
+var ErrInvalidSchedule = errors.New("fees: invalid fee schedule")
+
+type Service struct {
+	db  database.Database
+	cal *calendar.Calendar
+	now func() time.Time
+}
+
+func NewService(db database.Database, cal *calendar.Calendar) *Service {
+	return &Service{db: db, cal: cal, now: time.Now}
+}
+
+// Schedules returns every stored fee schedule, sorted by currency.
+func (s *Service) Schedules(ctx context.Context) ([]models.FeeSchedule, error) {
+	values, err := s.db.List(ctx, schedulesPrefix)
+	if err != nil {
+		return nil, fmt.Errorf("listing fee schedules: %w", err)
+	}
+	schedules := make([]models.FeeSchedule, 0, len(values))
+	for key, data := range values {
+		var schedule models.FeeSchedule
+		if err := json.Unmarshal(data, &schedule); err != nil {
+			return nil, fmt.Errorf("unmarshaling fee schedule %s: %w", key, err)
+		}
+		schedules = append(schedules, schedule)
+	}
+	sort.Slice(schedules, func(i, j int) bool { return schedules[i].Currency < schedules[j].Currency })
+	return schedules, nil
+}
+
+// SetSchedule validates schedule and replaces the schedule for its currency.
+func (s *Service) SetSchedule(ctx context.Context, schedule models.FeeSchedule) (models.FeeSchedule, error) {
+	if err := validate(&schedule); err != nil {
+		return schedule, err
+	}
+	schedule.UpdatedAt = s.now()
+
+	data, err := json.Marshal(schedule)
+	if err != nil {
+		return schedule, fmt.Errorf("marshaling fee schedule: %w", err)
+	}
+	if err := s.db.Put(ctx, schedulesPrefix+string(schedule.Currency), data); err != nil {
+		return schedule, fmt.Errorf("storing fee schedule: %w", err)
+	}
+	return schedule, nil
+}
+
+// validate checks schedule's rules and gives zero fixed fees the schedule
+// currency.
+func validate(schedule *models.FeeSchedule) error {
+	if !schedule.Currency.Valid() {
+		return money.ErrUnknownCurrency
+	}
+	names := make(map[string]bool, len(schedule.Rules))
+	for i := range schedule.Rules {
+		rule := &schedule.Rules[i]
+		switch {
+		case rule.Name == "" || names[rule.Name]:
+			return fmt.Errorf("%w: rule names must be unique and not empty", ErrInvalidSchedule)
+		case rule.Operation != models.FeeWithdrawal && rule.Operation != models.FeeBalanceInquiry && rule.Operation != models.FeeTransfer:
+			return fmt.Errorf("%w: rule %q has unknown operation %q", ErrInvalidSchedule, rule.Name, rule.Operation)
+		case rule.FreePerMonth < 0 || rule.RateBps < 0 || rule.RateBps > 10000:
+			return fmt.Errorf("%w: rule %q has a negative free count or a rate outside 0-10000 bps", ErrInvalidSchedule, rule.Name)
+		case rule.Fixed.IsNegative() || (rule.Max != nil && !rule.Max.IsPositive()):
+			return fmt.Errorf("%w: rule %q has a negative fixed fee or a non-positive maximum", ErrInvalidSchedule, rule.Name)
+		}
+		if rule.Fixed.IsZero() {
+			rule.Fixed = money.New(0, schedule.Currency)
+		}
+		if rule.Fixed.Currency() != schedule.Currency || (rule.Max != nil && rule.Max.Currency() != schedule.Currency) {
+			return money.ErrCurrencyMismatch
+		}
+		names[rule.Name] = true
+	}
+	return nil
+}
+
+// Operation describes what fees are charged for. Amount is in the account
+// currency and zero for balance inquiries.
+type Operation struct {
+	Type          models.FeeOperation
+	TransactionID uuid.UUID
+	Amount        money.Money
+	Foreign       bool // at another bank's ATM
+	At            time.Time
+}
+
+// Charge debits the fees for op from account, within its available balance,
+// and stores each as a models.Fee linked to op.TransactionID. Call it in the
+// same transaction as the operation so both are posted or neither is. The
+// caller stores the account.
+func (s *Service) Charge(tx database.Tx, account *models.Account, op Operation) ([]models.Fee, error) {
+	currency := account.Balance.Currency()
+	data := tx.Get(schedulesPrefix + string(currency))
+	if data == nil {
+		return nil, nil
+	}
+	var schedule models.FeeSchedule
+	if err := json.Unmarshal(data, &schedule); err != nil {
+		return nil, fmt.Errorf("unmarshaling fee schedule: %w", err)
+	}
+
+	usageKey := usagePrefix + account.ID.String() + "/" + s.cal.Day(op.At)[:7]
+	usage := map[string]int{}
+	if data := tx.Get(usageKey); data != nil {
+		if err := json.Unmarshal(data, &usage); err != nil {
+			return nil, fmt.Errorf("unmarshaling fee usage: %w", err)
+		}
+	}
+
+	var charged []models.Fee
+	matched := false
+	for _, rule := range schedule.Rules {
+		if rule.Operation != op.Type || (rule.ForeignOnly && !op.Foreign) {
+			continue
+		}
+		matched = true
+		usage[rule.Name]++
+		if usage[rule.Name] <= rule.FreePerMonth {
+			continue
+		}
+
+		amount, err := ruleAmount(rule, op.Amount)
+		if err != nil {
+			return nil, err
+		}
+		if amount.IsZero() {
+			continue
+		}
+		if err := ledger.Debit(tx, account, amount, op.At); err != nil {
+			return nil, err
+		}
+
+		fee := models.Fee{
+			ID:            uuid.New(),
+			AccountID:     account.ID,
+			Operation:     op.Type,
+			Rule:          rule.Name,
+			Amount:        amount,
+			TransactionID: op.TransactionID,
+			ChargedAt:     op.At,
+		}
+		data, err := json.Marshal(fee)
+		if err != nil {
+			return nil, fmt.Errorf("marshaling fee: %w", err)
+		}
+		tx.Put(feesPrefix+fee.ID.String(), data)
+		charged = append(charged, fee)
+	}
+
+	if matched {
+		data, err := json.Marshal(usage)
+		if err != nil {
+			return nil, fmt.Errorf("marshaling fee usage: %w", err)
+		}
+		tx.Put(usageKey, data)
+	}
+	return charged, nil
+}
+
+// ruleAmount returns rule's fee on amount: the fixed part plus RateBps of
+// amount, rounded half up, capped at Max.
+func ruleAmount(rule models.FeeRule, amount money.Money) (money.Money, error) {
+	fee := rule.Fixed
+	if rule.RateBps > 0 && !amount.IsZero() {
+		if amount.Currency() != fee.Currency() {
+			return fee, money.ErrCurrencyMismatch
+		}
+		part := new(big.Int).Mul(big.NewInt(amount.Minor()), big.NewInt(rule.RateBps))
+		part.Add(part, big.NewInt(5000))
+		part.Quo(part, big.NewInt(10000))
+		if !part.IsInt64() {
+			return fee, money.ErrOverflow
+		}
+		var err error
+		fee, err = fee.Add(money.New(part.Int64(), fee.Currency()))
+		if err != nil {
+			return fee, err
+		}
+	}
+	if rule.Max != nil {
+		if cmp, err := fee.Cmp(*rule.Max); err == nil && cmp > 0 {
+			fee = *rule.Max
+		}
+	}
+	return fee, nil
+}
+
+// Load reads the fees with the given IDs inside tx, skipping any that are
+// missing.
+func Load(tx database.Tx, ids []uuid.UUID) ([]models.Fee, error) {
+	var loaded []models.Fee
+	for _, id := range ids {
+		data := tx.Get(feesPrefix + id.String())
+		if data == nil {
+			continue
+		}
+		var fee models.Fee
+		if err := json.Unmarshal(data, &fee); err != nil {
+			return nil, fmt.Errorf("unmarshaling fee: %w", err)
+		}
+		loaded = append(loaded, fee)
+	}
+	return loaded, nil
+}
+
+// IDs returns the IDs of fees, for linking them from the operation record.
+func IDs(fees []models.Fee) []uuid.UUID {
+	if len(fees) == 0 {
+		return nil
+	}
+	ids := make([]uuid.UUID, len(fees))
+	for i, fee := range fees {
+		ids[i] = fee.ID
+	}
+	return ids
+}
@@ -1,11 +1,16 @@
 package handlers
 
 import (
-	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
+	"newapiprojet/apperrors"
+	"newapiprojet/database"
+	"newapiprojet/dto"
+	"newapiprojet/ledger"
 	"newapiprojet/models"
+	"newapiprojet/money"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -19,100 +24,223 @@ import (
 // @Accept json
 // @Produce json
 // @Param id path string true "Account ID"
-// @Success 200 {object} models.Account "Account found"
-// @Failure 404 {string} string "Account not found"
-// @Failure 400 {string} string "Bad Request"
+// @Success 200 {object} dto.AccountResponse "Account found"
+// @Failure 404 {object} apperrors.Problem "Account not found"
+// @Failure 400 {object} apperrors.Problem "Bad Request"
 // @Router /account/{id} [get]
 func (h *Handler) GetAccountByID(c *gin.Context) {
-	accountID := c.Param("id")
-	accountUUID, err := uuid.Parse(accountID)
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid account ID format"})
+	var params dto.IDParam
+	if !bindURI(c, &params) {
 		return
 	}
+	accountUUID := params.UUID()
 
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	defer cancel()
+	ctx := c.Request.Context()
 
 	resp, err := h.db.Get(ctx, "accounts/"+accountUUID.String())
-	if err != nil || resp == nil {
-		fmt.Println("Account not found or error retrieving account data:", err)
-		c.JSON(http.StatusNotFound, gin.H{"error": "Account not found"})
+	if err != nil {
+		abortWithError(c, fmt.Errorf("retrieving account data: %w", err))
+		return
+	}
+	if resp == nil {
+		abortWithError(c, apperrors.New(apperrors.AccountNotFound))
 		return
 	}
 
 	var account models.Account
 	err = json.Unmarshal(resp, &account)
 	if err != nil {
-		fmt.Println("Error unmarshaling account data:", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Unable to unmarshal account data: " + err.Error()})
+		abortWithError(c, fmt.Errorf("unmarshaling account data: %w", err))
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"account": account})
+	c.JSON(http.StatusOK, dto.AccountResponse{Account: dto.NewAccountView(account)})
 }
 
 // DeleteAccountByID godoc
-// @Summary Delete an account by ID
-// @Description Delete an account by ID
+// @Summary Close an account
+// @Description Close one of the user's accounts. Only active accounts with a zero balance can be closed. The account and its history are kept, with status "closed", and no money can move in or out of it afterwards. Standing orders from or to the account are cancelled.
 // @Tags Account
 // @Produce json
 // @Param id path string true "Account ID"
 // @Success 204 {string} string "No Content"
-// @Failure 400 {string} string "Bad Request"
-// @Failure 403 {string} string "Forbidden"
-// @Failure 404 {string} string "Account not found"
-// @Failure 500 {string} string "Internal Server Error"
-// @Router /account/{id} [delete]
+// @Failure 400 {object} apperrors.Problem "Bad Request"
+// @Failure 403 {object} apperrors.Problem "Forbidden"
+// @Failure 404 {object} apperrors.Problem "Account not found"
+// @Failure 409 {object} apperrors.Problem "Balance is not zero or account is not active"
+// @Failure 500 {object} apperrors.Problem "Internal Server Error"
+// @Router /account/deleteacc/{id} [delete]
 func (h *Handler) DeleteAccountByID(c *gin.Context) {
-	accountID := c.Param("id")
-
-	if accountID == "" {
-		c.JSON(http.StatusBadRequest, "Account ID cannot be empty")
+	var params dto.IDParam
+	if !bindURI(c, &params) {
 		return
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	defer cancel()
+	ctx := c.Request.Context()
 
 	userID, exists := c.Get("userID")
 	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Authentication error"})
+		abortWithError(c, apperrors.New(apperrors.Unauthorized))
 		return
 	}
 
 	userIDUUID, ok := userID.(uuid.UUID)
 	if !ok {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "User ID conversion error"})
+		abortWithError(c, errors.New("user ID in context is not a UUID"))
+		return
+	}
+
+	var cancelled int
+	err := h.db.Atomic(ctx, func(tx database.Tx) error {
+		cancelled = 0
+		account, err := getOwnedAccount(tx, params.UUID(), userIDUUID)
+		if err != nil {
+			return err
+		}
+		if ledger.Status(account) == models.AccountClosed {
+			return nil
+		}
+		if err := ledger.SetStatus(tx, &account, models.AccountClosed, "closed by customer", &userIDUUID, time.Now()); err != nil {
+			return statusError(err)
+		}
+		if cancelled, err = cancelAccountStandingOrders(tx, account.ID); err != nil {
+			return err
+		}
+		return putJSON(tx, accountKey(account.ID), account)
+	})
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+
+	h.logger.InfoContext(ctx, "account closed", "account_id", params.ID, "standing_orders_cancelled", cancelled)
+	c.Status(http.StatusNoContent)
+}
+
+// SetAccountStatus godoc
+// @Summary Change an account's status
+// @Description Freeze or unfreeze an account, reactivate a dormant one, or close one with a zero balance. Frozen and dormant accounts can receive money but not pay out. Closed accounts stay closed. Staff only.
+// @Tags Staff
+// @Accept json
+// @Produce json
+// @Param id path string true "Account ID"
+// @Param input body dto.AccountStatusRequest true "New status"
+// @Success 200 {object} dto.AccountResponse "Status changed"
+// @Failure 400 {object} apperrors.Problem "Bad Request"
+// @Failure 403 {object} apperrors.Problem "Forbidden"
+// @Failure 404 {object} apperrors.Problem "Account not found"
+// @Failure 409 {object} apperrors.Problem "Status change not allowed"
+// @Failure 500 {object} apperrors.Problem "Internal Server Error"
+// @Router /staff/accounts/{id}/status [put]
+func (h *Handler) SetAccountStatus(c *gin.Context) {
+	var params dto.IDParam
+	if !bindURI(c, &params) {
+		return
+	}
+
+	var input dto.AccountStatusRequest
+	if !bindJSON(c, &input) {
+		return
+	}
+
+	userID, exists := c.Get("userID")
+	if !exists {
+		abortWithError(c, apperrors.New(apperrors.Unauthorized))
 		return
 	}
 
-	resp, err := h.db.Get(ctx, "accounts/"+accountID)
-	if err != nil || resp == nil {
-		fmt.Println("Error retrieving account data:", err)
-		c.JSON(http.StatusNotFound, gin.H{"error": "Account not found"})
+	userIDUUID, ok := userID.(uuid.UUID)
+	if !ok {
+		abortWithError(c, errors.New("user ID in context is not a UUID"))
 		return
 	}
 
+	ctx := c.Request.Context()
+
 	var account models.Account
-	err = json.Unmarshal(resp, &account)
+	var from models.AccountStatus
+	var cancelled int
+	err := h.db.Atomic(ctx, func(tx database.Tx) error {
+		cancelled = 0
+		var err error
+		account, err = getAccount(tx, params.UUID())
+		if err != nil {
+			return err
+		}
+		from = ledger.Status(account)
+		if err := ledger.SetStatus(tx, &account, models.AccountStatus(input.Status), input.Reason, &userIDUUID, time.Now()); err != nil {
+			return statusError(err)
+		}
+		if from != models.AccountClosed && ledger.Status(account) == models.AccountClosed {
+			if cancelled, err = cancelAccountStandingOrders(tx, account.ID); err != nil {
+				return err
+			}
+		}
+		return putJSON(tx, accountKey(account.ID), account)
+	})
 	if err != nil {
-		fmt.Println("Error unmarshaling account data:", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Unable to unmarshal account data: " + err.Error()})
+		abortWithError(c, err)
 		return
 	}
 
-	if account.UserID != userIDUUID {
-		c.JSON(http.StatusForbidden, gin.H{"error": "You do not have access to this account"})
+	h.logger.InfoContext(ctx, "account status changed", "account_id", account.ID, "from", from, "to", ledger.Status(account), "reason", input.Reason, "changed_by", userIDUUID, "standing_orders_cancelled", cancelled)
+	c.JSON(http.StatusOK, dto.AccountResponse{Account: dto.NewAccountView(account)})
+}
+
+// OpenAccount godoc
+// @Summary Open an account
+// @Description Open an additional account for the user in the given currency, starting with a zero balance
+// @Tags Account
+// @Accept json
+// @Produce json
+// @Param input body dto.OpenAccountRequest true "Account currency"
+// @Success 201 {object} dto.AccountResponse "Account opened"
+// @Failure 400 {object} apperrors.Problem "Bad Request"
+// @Failure 500 {object} apperrors.Problem "Internal Server Error"
+// @Router /account/open [post]
+func (h *Handler) OpenAccount(c *gin.Context) {
+	var input dto.OpenAccountRequest
+
+	if !bindJSON(c, &input) {
+		return
+	}
+
+	userID, exists := c.Get("userID")
+	if !exists {
+		abortWithError(c, apperrors.New(apperrors.Unauthorized))
+		return
+	}
+
+	userIDUUID, ok := userID.(uuid.UUID)
+	if !ok {
+		abortWithError(c, errors.New("user ID in context is not a UUID"))
+		return
+	}
+
+	// Checked by the currency validator.
+	currency, _ := money.ParseCurrency(input.Currency)
+	now := time.Now()
+	account := models.Account{
+		ID:             uuid.New(),
+		UserID:         userIDUUID,
+		Balance:        money.New(0, currency),
+		Status:         models.AccountActive,
+		LastActivityAt: &now,
+	}
+
+	accountData, err := json.Marshal(account)
+	if err != nil {
+		abortWithError(c, fmt.Errorf("marshaling account data: %w", err))
 		return
 	}
 
-	err = h.db.Delete(ctx, "accounts/"+accountID)
+	ctx := c.Request.Context()
+	err = h.db.Put(ctx, accountKey(account.ID), accountData)
 	if err != nil {
-		fmt.Println("Error deleting account data:", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Unable to delete account data: " + err.Error()})
+		abortWithError(c, fmt.Errorf("storing account data: %w", err))
 		return
 	}
 
-	c.JSON(http.StatusNoContent, nil)
+	h.logger.InfoContext(ctx, "account opened", "account_id", account.ID, "currency", currency)
+	c.JSON(http.StatusCreated, dto.AccountResponse{Account: dto.NewAccountView(account)})
 }
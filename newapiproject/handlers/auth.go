@@ -3,12 +3,16 @@ package handlers
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
+	"newapiprojet/apperrors"
+	"newapiprojet/database"
+	"newapiprojet/dto"
+	"newapiprojet/metrics"
 	"newapiprojet/models"
+	"newapiprojet/money"
 	"os"
-	"regexp"
-	"strings"
 	"time"
 
 	"github.com/dgrijalva/jwt-go"
@@ -22,153 +26,177 @@ import (
 // @Tags User
 // @Accept json
 // @Produce json
-// @Param user body models.User true "User details"
-// @Success 201 {object} gin.H "User and Account created successfully"
-// @Failure 400 {string} string "Bad Request"
-// @Failure 409 {string} string "Username already exists"
-// @Failure 500 {string} string "Internal Server Error"
+// @Param user body dto.RegisterRequest true "User details"
+// @Success 201 {object} dto.RegisterResponse "User and Account created successfully"
+// @Failure 400 {object} apperrors.Problem "Bad Request"
+// @Failure 409 {object} apperrors.Problem "Username already exists"
+// @Failure 500 {object} apperrors.Problem "Internal Server Error"
 // @Router /user/register [post]
 func (h *Handler) Register(c *gin.Context) {
-	var user models.User
+	var input dto.RegisterRequest
+	ctx := c.Request.Context()
 
-	if err := c.BindJSON(&user); err != nil {
-		fmt.Println("Error binding JSON:", err)
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	if !bindJSON(c, &input) {
 		return
 	}
 
-	fmt.Printf("Received user data: %+v\n", user)
-
-	user.PhoneNumber = strings.TrimSpace(user.PhoneNumber)
-
-	matchPhone, _ := regexp.MatchString(`^\d{11}$`, user.PhoneNumber)
-	if !matchPhone {
-		fmt.Println("Invalid phone number:", user.PhoneNumber)
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Phone must be exactly 11 digits"})
-		return
-	}
-
-	matchPin, _ := regexp.MatchString(`^\d{4}$`, user.PIN)
-	if !matchPin {
-		fmt.Println("Invalid PIN:", user.PIN)
-		c.JSON(http.StatusBadRequest, gin.H{"error": "PIN must be exactly 4 digits"})
-		return
-	}
+	h.logger.DebugContext(ctx, "received registration request", "request", input)
 
+	user := input.ToUser()
 	user.ID = uuid.New()
+	user.Role = models.RoleCustomer
 
 	userData, err := json.Marshal(user)
 	if err != nil {
-		fmt.Println("Error marshaling user data:", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Unable to marshal user data: " + err.Error()})
+		abortWithError(c, fmt.Errorf("marshaling user data: %w", err))
 		return
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	defer cancel()
+	accountID := uuid.New()
+	now := time.Now()
 
-	resp, err := h.db.Get(ctx, "users/"+user.Username)
-	if err != nil {
-		fmt.Println("Error checking for existing username:", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Unable to check username: " + err.Error()})
-		return
+	account := models.Account{
+		ID:             accountID,
+		UserID:         user.ID,
+		Balance:        openingBalance,
+		Status:         models.AccountActive,
+		LastActivityAt: &now,
 	}
-	if resp != nil {
-		fmt.Println("Username already exists:", user.Username)
-		c.JSON(http.StatusConflict, gin.H{"error": "Username already exists"})
+
+	accountData, err := json.Marshal(account)
+	if err != nil {
+		abortWithError(c, fmt.Errorf("marshaling account data: %w", err))
 		return
 	}
 
-	err = h.db.Put(context.Background(), "users/"+user.Username, userData)
+	err = h.db.Atomic(ctx, func(tx database.Tx) error {
+		// users/<username> is where users were stored before the username index.
+		if tx.Get(usernameKey(user.Username)) != nil || tx.Get("users/"+user.Username) != nil {
+			return apperrors.New(apperrors.UsernameTaken)
+		}
+		tx.Put(usernameKey(user.Username), []byte(user.ID.String()))
+		tx.Put("users/"+user.ID.String(), userData)
+		tx.Put(accountKey(accountID), accountData)
+		return nil
+	})
 	if err != nil {
-		fmt.Println("Error storing user data in etcd:", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Unable to store user data in etcd: " + err.Error()})
+		h.logger.InfoContext(ctx, "registration rejected", "username", user.Username, "error", err)
+		abortWithError(c, err)
 		return
 	}
 
-	accountID := uuid.New()
+	c.JSON(http.StatusCreated, dto.RegisterResponse{
+		User:    dto.NewUserView(user),
+		Account: dto.NewAccountView(account),
+	})
 
-	account := models.Account{
-		ID:      accountID,
-		UserID:  user.ID,
-		Balance: 1000,
-	}
+	h.logger.InfoContext(ctx, "user registered", "user_id", user.ID, "account_id", accountID)
+}
 
-	accountData, err := json.Marshal(account)
+// openingBalance is credited to every newly registered account.
+var openingBalance = money.New(1000_00, money.DefaultCurrency)
+
+// usernameKey indexes user IDs by username, so users/<id> can be found at login.
+func usernameKey(username string) string {
+	return "usernames/" + username
+}
+
+// findUserByUsername looks a user up through the username index, falling
+// back to the users/<username> layout used before the index existed.
+func (h *Handler) findUserByUsername(ctx context.Context, username string) (*models.User, error) {
+	key := "users/" + username
+	id, err := h.db.Get(ctx, usernameKey(username))
 	if err != nil {
-		fmt.Println("Error marshaling account data:", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Unable to marshal account data: " + err.Error()})
-		return
+		return nil, fmt.Errorf("retrieving username index: %w", err)
+	}
+	if id != nil {
+		key = "users/" + string(id)
 	}
 
-	err = h.db.Put(context.Background(), "accounts/"+user.Username, accountData)
+	data, err := h.db.Get(ctx, key)
 	if err != nil {
-		fmt.Println("Error storing account data in etcd:", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Unable to store account data in etcd: " + err.Error()})
-		return
+		return nil, fmt.Errorf("retrieving user data: %w", err)
+	}
+	if data == nil {
+		return nil, nil
 	}
 
-	c.JSON(http.StatusCreated, gin.H{
-		"user":    user,
-		"account": account,
-	})
-
-	fmt.Println(user.ID, accountID)
+	var user models.User
+	if err := json.Unmarshal(data, &user); err != nil {
+		return nil, fmt.Errorf("unmarshaling user data: %w", err)
+	}
+	return &user, nil
 }
 
 type Claims struct {
-	UserID uuid.UUID `json:"user_id"`
+	UserID   uuid.UUID   `json:"user_id"`
+	Language string      `json:"lang,omitempty"`
+	Role     models.Role `json:"role,omitempty"`
+	// CardID and AccountIDs are set on card sessions, which may only use
+	// the card's accounts, and TerminalID on those opened at a registered
+	// ATM, which may only be used from it.
+	CardID     *uuid.UUID  `json:"card_id,omitempty"`
+	AccountIDs []uuid.UUID `json:"account_ids,omitempty"`
+	TerminalID *uuid.UUID  `json:"terminal_id,omitempty"`
 	jwt.StandardClaims
 }
 
+// signToken signs claims with the JWT secret.
+func signToken(claims *Claims) (string, error) {
+	secretKey := os.Getenv("JWT_SECRET")
+	if secretKey == "" {
+		return "", errors.New("JWT secret key is not configured")
+	}
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(secretKey))
+	if err != nil {
+		return "", fmt.Errorf("signing token: %w", err)
+	}
+	return token, nil
+}
+
 // Login godoc
 // @Summary Login user and generate token
 // @Description Login user and generate token
 // @Tags User
 // @Accept json
 // @Produce json
-// @Param credentials body models.User true "User credentials"
-// @Success 200 {string} string "Token generated"
-// @Failure 400 {string} string "Bad Request"
-// @Failure 401 {string} string "Unauthorized"
-// @Failure 500 {string} string "Internal Server Error"
+// @Param credentials body dto.LoginRequest true "User credentials"
+// @Success 200 {object} dto.LoginResponse "Token generated"
+// @Failure 400 {object} apperrors.Problem "Bad Request"
+// @Failure 401 {object} apperrors.Problem "Unauthorized"
+// @Failure 500 {object} apperrors.Problem "Internal Server Error"
 // @Router /user/login [post]
 func (h *Handler) Login(c *gin.Context) {
-	var credentials models.User
+	var credentials dto.LoginRequest
+	ctx := c.Request.Context()
 
-	if err := c.BindJSON(&credentials); err != nil {
-		fmt.Println("Error binding JSON:", err)
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	if !bindJSON(c, &credentials) {
 		return
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	defer cancel()
-
-	resp, err := h.db.Get(ctx, "users/"+credentials.Username)
-	if err != nil || resp == nil {
-		fmt.Println("Invalid credentials or error retrieving user data:", err)
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid credentials"})
+	user, err := h.findUserByUsername(ctx, credentials.Username)
+	if err != nil {
+		abortWithError(c, err)
 		return
 	}
-
-	var user models.User
-	err = json.Unmarshal(resp, &user)
-	if err != nil {
-		fmt.Println("Error unmarshaling user data:", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Unable to unmarshal user data: " + err.Error()})
+	if user == nil {
+		h.logger.WarnContext(ctx, "login for unknown user", "username", credentials.Username)
+		metrics.FailedLoginsTotal.WithLabelValues("unknown_user").Inc()
+		abortWithError(c, apperrors.New(apperrors.InvalidCredentials))
 		return
 	}
 
 	if user.PIN != credentials.PIN {
-		fmt.Println("Invalid PIN:", credentials.PIN)
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid credentials"})
+		h.logger.WarnContext(ctx, "invalid PIN", "username", credentials.Username)
+		metrics.FailedLoginsTotal.WithLabelValues("invalid_pin").Inc()
+		abortWithError(c, apperrors.New(apperrors.InvalidCredentials))
 		return
 	}
 
 	expirationTime := time.Now().Add(7 * 24 * time.Hour)
 	claims := &Claims{
-		UserID: user.ID,
+		UserID:   user.ID,
+		Language: user.Language,
 		StandardClaims: jwt.StandardClaims{
 			ExpiresAt: expirationTime.Unix(),
 			Issuer:    "example.com",
@@ -176,17 +204,11 @@ func (h *Handler) Login(c *gin.Context) {
 		},
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	secretKey := os.Getenv("JWT_SECRET")
-	if secretKey == "" {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "JWT secret key not configured"})
-		return
-	}
-	tokenString, err := token.SignedString([]byte(secretKey))
+	tokenString, err := signToken(claims)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate token"})
+		abortWithError(c, err)
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"token": tokenString})
+	c.JSON(http.StatusOK, dto.LoginResponse{Token: tokenString})
 }
@@ -1,10 +1,14 @@
 package handlers
 
 import (
-	"context"
-	"encoding/json"
-	"fmt"
+	"errors"
 	"net/http"
+	"newapiprojet/apperrors"
+	"newapiprojet/database"
+	"newapiprojet/dto"
+	"newapiprojet/fees"
+	"newapiprojet/i18n"
+	"newapiprojet/ledger"
 	"newapiprojet/models"
 	"time"
 
@@ -12,90 +16,129 @@ import (
 	"github.com/google/uuid"
 )
 
+// Balance inquiries are stored one key each:
+//
+//	balance_inquiries/<inquiryID>  models.BalanceInquiry
+const balanceInquiriesPrefix = "balance_inquiries/"
+
+func balanceInquiryKey(id uuid.UUID) string {
+	return balanceInquiriesPrefix + id.String()
+}
+
 // GetAccountBalance godoc
 // @Summary Get the account balance
-// @Description Get the account balance
+// @Description Get the current (ledger) balance and the available balance, which includes any unused overdraft and excludes money held by active holds. Balance inquiry fees are charged first, to active accounts only; when the account cannot cover them they are waived.
 // @Tags Account
 // @Accept json
 // @Produce json
 // @Param accountID path string true "Account ID"
-// @Success 200 {string} string "Balance inquiry successful"
-// @Failure 404 {string} string "Account not found"
-// @Failure 400 {string} string "Bad Request"
-// @Failure 403 {string} string "Forbidden"
-// @Router /balance/{accountID} [get]
+// @Param X-ATM-Network header string false "foreign at another bank's ATM"
+// @Success 200 {object} dto.BalanceResponse "Balance inquiry successful"
+// @Failure 404 {object} apperrors.Problem "Account not found"
+// @Failure 400 {object} apperrors.Problem "Bad Request"
+// @Failure 403 {object} apperrors.Problem "Forbidden"
+// @Router /account/balance/{accountID} [get]
 func (h Handler) GetAccountBalance(c *gin.Context) {
-	accountID := c.Param("accountID")
-
-	if accountID == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Account ID cannot be empty"})
-		return
-	}
-
-	accountUUID, err := uuid.Parse(accountID)
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid account ID format"})
+	var params dto.AccountIDParam
+	if !bindURI(c, &params) {
 		return
 	}
+	accountUUID := params.UUID()
 
 	userID, exists := c.Get("userID")
 	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Yetkilendirme hatası"})
+		abortWithError(c, apperrors.New(apperrors.Unauthorized))
 		return
 	}
 
 	userIDUUID, ok := userID.(uuid.UUID)
 	if !ok {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "User ID dönüştürme hatası"})
+		abortWithError(c, errors.New("user ID in context is not a UUID"))
 		return
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	defer cancel()
-
-	resp, err := h.db.Get(ctx, "accounts/username"+accountUUID.String())
-	if err != nil || resp == nil {
-		fmt.Println("Account not found or error retrieving account data:", err)
-		c.JSON(http.StatusNotFound, gin.H{"message": "Account not found"})
+	if err := checkCardScope(c, accountUUID); err != nil {
+		abortWithError(c, err)
 		return
 	}
 
+	ctx := c.Request.Context()
+	foreign := foreignATM(c)
+
 	var account models.Account
-	err = json.Unmarshal(resp, &account)
-	if err != nil {
-		fmt.Println("Error unmarshaling account data:", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Unable to unmarshal account data: " + err.Error()})
-		return
-	}
+	var balanceInquiry models.BalanceInquiry
+	var charged []models.Fee
+	inquire := func(waive bool) error {
+		return h.db.Atomic(ctx, func(tx database.Tx) error {
+			var err error
+			account, err = getOwnedAccount(tx, accountUUID, userIDUUID)
+			if err != nil {
+				return err
+			}
 
-	if account.UserID != userIDUUID {
-		c.JSON(http.StatusForbidden, gin.H{"error": "Bu hesaba erişim izniniz yok"})
-		return
-	}
+			balanceInquiry = models.BalanceInquiry{
+				ID:          uuid.New(),
+				AccountID:   account.ID,
+				InquiryDate: time.Now(),
+				FeesWaived:  waive,
+			}
 
-	balanceInquiry := models.BalanceInquiry{
-		ID:             uuid.New(),
-		AccountID:      account.ID,
-		CurrentBalance: account.Balance,
-		InquiryDate:    time.Now(),
+			// Accounts that cannot be debited, because they are frozen,
+			// dormant or closed, can still be looked at, free of charge.
+			charged = nil
+			if !waive && ledger.CanDebit(account) == nil {
+				charged, err = h.fees.Charge(tx, &account, fees.Operation{
+					Type:          models.FeeBalanceInquiry,
+					TransactionID: balanceInquiry.ID,
+					Foreign:       foreign,
+					At:            balanceInquiry.InquiryDate,
+				})
+				if err != nil {
+					return moneyError(err)
+				}
+			}
+			balanceInquiry.FeeIDs = fees.IDs(charged)
+			balanceInquiry.CurrentBalance = account.Balance
+			ledger.RecordActivity(&account, balanceInquiry.InquiryDate)
+
+			if err := putJSON(tx, accountKey(account.ID), account); err != nil {
+				return err
+			}
+			return putJSON(tx, balanceInquiryKey(balanceInquiry.ID), balanceInquiry)
+		})
+	}
+	err := inquire(false)
+	// A customer who cannot cover the fee still gets their balance; the
+	// inquiry is recorded with the fee waived.
+	if errors.Is(err, ledger.ErrInsufficientFunds) {
+		h.logger.InfoContext(ctx, "balance inquiry fee waived", "account_id", accountUUID, "reason", "insufficient_funds")
+		err = inquire(true)
 	}
-	inquiryData, err := json.Marshal(balanceInquiry)
 	if err != nil {
-		fmt.Println("Error marshaling balance inquiry data:", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Unable to marshal balance inquiry data: " + err.Error()})
+		abortWithError(c, err)
 		return
 	}
 
-	err = h.db.Put(ctx, "balance_inquiries/"+accountUUID.String(), inquiryData)
+	available, err := ledger.Available(account)
 	if err != nil {
-		fmt.Println("Error storing balance inquiry data in etcd:", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Unable to store balance inquiry data in etcd: " + err.Error()})
+		abortWithError(c, moneyError(err))
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{
-		"message":   "Balance inquiry successful",
-		"accountID": account.ID,
-		"balance":   account.Balance,
+	lang := i18n.FromContext(ctx)
+	c.JSON(http.StatusOK, dto.BalanceResponse{
+		Message:                   i18n.T(lang, i18n.MsgBalanceInquirySuccess),
+		AccountID:                 account.ID,
+		Balance:                   account.Balance,
+		BalanceFormatted:          i18n.FormatMoney(lang, account.Balance),
+		LedgerBalance:             account.Balance,
+		AvailableBalance:          available,
+		AvailableBalanceFormatted: i18n.FormatMoney(lang, available),
+		Held:                      ledger.Held(account),
+		Status:                    ledger.Status(account),
+		Overdraft:                 dto.NewOverdraftView(account),
+		Date:                      i18n.FormatDate(lang, balanceInquiry.InquiryDate),
+		Fees:                      dto.NewFeeViews(charged),
+		FeesWaived:                balanceInquiry.FeesWaived,
 	})
 }
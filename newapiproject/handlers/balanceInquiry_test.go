@@ -0,0 +1,77 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"newapiprojet/dto"
+	"newapiprojet/models"
+	"newapiprojet/money"
+)
+
+func TestGetAccountBalanceFees(t *testing.T) {
+	tests := []struct {
+		name        string
+		balance     money.Money
+		status      models.AccountStatus
+		wantBalance money.Money
+		wantCharged bool
+	}{
+		{"charged", lira(10), "", lira(8), true},
+		{"waived when it cannot be covered", lira(1), "", lira(1), false},
+		{"not charged to a frozen account", lira(10), models.AccountFrozen, lira(10), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h, db := newTestHandler(t)
+			_, err := h.fees.SetSchedule(context.Background(), models.FeeSchedule{
+				Currency: money.TRY,
+				Rules:    []models.FeeRule{{Name: "inquiry", Operation: models.FeeBalanceInquiry, Fixed: lira(2)}},
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			account := putAccount(t, db, tt.balance)
+			account.Status = tt.status
+			put(t, db, accountKey(account.ID), account)
+
+			r := newTestRouter(account.UserID)
+			r.GET("/account/balance/:accountID", h.GetAccountBalance)
+			w := serve(r, http.MethodGet, "/account/balance/"+account.ID.String(), "")
+			if w.Code != http.StatusOK {
+				t.Fatalf("balance = %d %s", w.Code, w.Body)
+			}
+			var resp dto.BalanceResponse
+			if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+				t.Fatal(err)
+			}
+			if got := getTestAccount(t, db, account.ID).Balance; got != tt.wantBalance || resp.Balance != tt.wantBalance {
+				t.Errorf("balance = %v, reported %v, want %v", got, resp.Balance, tt.wantBalance)
+			}
+			wantWaived := tt.status == "" && !tt.wantCharged
+			if charged := len(resp.Fees) > 0; charged != tt.wantCharged || resp.FeesWaived != wantWaived {
+				t.Errorf("fees = %v, waived %v, want charged %v, waived %v", resp.Fees, resp.FeesWaived, tt.wantCharged, wantWaived)
+			}
+
+			// Every inquiry is kept under its own ID.
+			inquiries, err := db.List(context.Background(), balanceInquiriesPrefix)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(inquiries) != 1 {
+				t.Fatalf("stored %d inquiries, want 1", len(inquiries))
+			}
+			for key, data := range inquiries {
+				var inquiry models.BalanceInquiry
+				if err := json.Unmarshal(data, &inquiry); err != nil {
+					t.Fatal(err)
+				}
+				if key != balanceInquiryKey(inquiry.ID) || inquiry.AccountID != account.ID || inquiry.FeesWaived != wantWaived || (len(inquiry.FeeIDs) > 0) != tt.wantCharged {
+					t.Errorf("%s = %+v", key, inquiry)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,387 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"newapiprojet/apperrors"
+	"newapiprojet/cards"
+	"newapiprojet/database"
+	"newapiprojet/dto"
+	"newapiprojet/i18n"
+	"newapiprojet/ledger"
+	"newapiprojet/metrics"
+	"newapiprojet/models"
+	"slices"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// Cards are stored one key each, with an index from card number to card ID
+// for ATM logins:
+//
+//	cards/<cardID>  models.Card
+//	card_pans/<pan> card ID
+const (
+	cardsPrefix    = "cards/"
+	cardPANsPrefix = "card_pans/"
+)
+
+// defaultCardValidYears is how long cards are valid when the issuer does
+// not say.
+const defaultCardValidYears = 4
+
+// maxPINAttempts wrong PINs in a row block the card.
+const maxPINAttempts = 3
+
+// cardSessionTTL is how long a card session token lasts: one visit to the
+// ATM.
+const cardSessionTTL = 5 * time.Minute
+
+func cardKey(id uuid.UUID) string {
+	return cardsPrefix + id.String()
+}
+
+func getCard(tx database.Tx, id uuid.UUID) (models.Card, error) {
+	var card models.Card
+	data := tx.Get(cardKey(id))
+	if data == nil {
+		return card, apperrors.New(apperrors.CardNotFound)
+	}
+	if err := json.Unmarshal(data, &card); err != nil {
+		return card, fmt.Errorf("unmarshaling card: %w", err)
+	}
+	return card, nil
+}
+
+// checkCardScope rejects accountID if the request comes from a card session
+// whose card is not linked to it. User sessions may use any of the user's
+// accounts.
+func checkCardScope(c *gin.Context, accountID uuid.UUID) error {
+	scope, ok := c.Get("cardAccountIDs")
+	if !ok {
+		return nil
+	}
+	if ids, _ := scope.([]uuid.UUID); !slices.Contains(ids, accountID) {
+		return apperrors.New(apperrors.Forbidden).WithDetail(i18n.MsgCardScope)
+	}
+	return nil
+}
+
+// CheckCardSession rejects card session requests from another ATM than the
+// one the session was opened at, or for a card that has since been blocked
+// or has expired. It must run after AuthenticateJWT and
+// AuthenticateTerminal.
+func (h *Handler) CheckCardSession() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, ok := c.Get("cardID")
+		if !ok {
+			c.Next()
+			return
+		}
+		cardID, ok := id.(uuid.UUID)
+		if !ok {
+			abortWithError(c, errors.New("card ID in context is not a UUID"))
+			return
+		}
+		ctx := c.Request.Context()
+
+		opened, _ := c.Value("cardTerminalID").(*uuid.UUID)
+		if at := terminalID(c); (opened == nil) != (at == nil) || opened != nil && *opened != *at {
+			h.logger.WarnContext(ctx, "card session used at another terminal", "card_id", cardID, "opened_at", opened, "terminal_id", at)
+			abortWithError(c, apperrors.New(apperrors.Forbidden).WithDetail(i18n.MsgCardSessionTerminal))
+			return
+		}
+
+		data, err := h.db.Get(ctx, cardKey(cardID))
+		if err != nil {
+			abortWithError(c, fmt.Errorf("retrieving card: %w", err))
+			return
+		}
+		if data == nil {
+			abortWithError(c, apperrors.New(apperrors.CardUnavailable))
+			return
+		}
+		var card models.Card
+		if err := json.Unmarshal(data, &card); err != nil {
+			abortWithError(c, fmt.Errorf("unmarshaling card: %w", err))
+			return
+		}
+		if card.Status == models.CardBlocked {
+			abortWithError(c, apperrors.New(apperrors.CardUnavailable).WithDetail(i18n.MsgCardBlocked))
+			return
+		}
+		if !time.Now().Before(card.ExpiresAt) {
+			abortWithError(c, apperrors.New(apperrors.CardUnavailable).WithDetail(i18n.MsgCardExpired))
+			return
+		}
+		c.Next()
+	}
+}
+
+// IssueCard godoc
+// @Summary Issue a card
+// @Description Issue a debit card to a user, linked to one or more of the user's accounts that are not closed. The card number is generated and returned in full only in this response. Staff only.
+// @Tags Staff
+// @Accept json
+// @Produce json
+// @Param input body dto.IssueCardRequest true "Card holder and accounts"
+// @Success 201 {object} dto.IssueCardResponse "Card issued"
+// @Failure 400 {object} apperrors.Problem "Bad Request"
+// @Failure 403 {object} apperrors.Problem "Forbidden"
+// @Failure 404 {object} apperrors.Problem "User or account not found"
+// @Failure 500 {object} apperrors.Problem "Internal Server Error"
+// @Router /staff/cards [post]
+func (h *Handler) IssueCard(c *gin.Context) {
+	var input dto.IssueCardRequest
+	if !bindJSON(c, &input) {
+		return
+	}
+
+	userID, exists := c.Get("userID")
+	if !exists {
+		abortWithError(c, apperrors.New(apperrors.Unauthorized))
+		return
+	}
+
+	userIDUUID, ok := userID.(uuid.UUID)
+	if !ok {
+		abortWithError(c, errors.New("user ID in context is not a UUID"))
+		return
+	}
+
+	years := input.ValidYears
+	if years == 0 {
+		years = defaultCardValidYears
+	}
+	now := time.Now().UTC()
+	card := models.Card{
+		ID:     uuid.New(),
+		UserID: input.UserID,
+		// Valid through the end of the month, years from now.
+		ExpiresAt: time.Date(now.Year()+years, now.Month()+1, 1, 0, 0, 0, 0, time.UTC),
+		Status:    models.CardActive,
+		IssuedBy:  userIDUUID,
+		IssuedAt:  now,
+	}
+	for _, id := range input.AccountIDs {
+		if !slices.Contains(card.AccountIDs, id) {
+			card.AccountIDs = append(card.AccountIDs, id)
+		}
+	}
+
+	ctx := c.Request.Context()
+
+	err := h.db.Atomic(ctx, func(tx database.Tx) error {
+		if tx.Get("users/"+card.UserID.String()) == nil {
+			return apperrors.New(apperrors.UserNotFound)
+		}
+		for _, id := range card.AccountIDs {
+			account, err := getAccount(tx, id)
+			if err != nil {
+				return err
+			}
+			if account.UserID != card.UserID || ledger.Status(account) == models.AccountClosed {
+				return apperrors.New(apperrors.InvalidRequest).WithDetail(i18n.MsgCardAccountOwner)
+			}
+		}
+
+		// Card numbers are random, so a clash with an issued card is rare.
+		for {
+			pan, err := cards.NewPAN()
+			if err != nil {
+				return err
+			}
+			if tx.Get(cardPANsPrefix+pan) == nil {
+				card.PAN = pan
+				break
+			}
+		}
+		tx.Put(cardPANsPrefix+card.PAN, []byte(card.ID.String()))
+		return putJSON(tx, cardKey(card.ID), card)
+	})
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+
+	h.logger.InfoContext(ctx, "card issued", "card_id", card.ID, "pan", cards.Mask(card.PAN), "user_id", card.UserID, "account_ids", card.AccountIDs, "issued_by", userIDUUID)
+	c.JSON(http.StatusCreated, dto.IssueCardResponse{Card: dto.NewCardView(card), PAN: card.PAN})
+}
+
+// BlockCard godoc
+// @Summary Block a card
+// @Description Block a card, for example when it is reported lost or stolen, so that it can no longer be used to sign in at an ATM. Blocking is permanent; a new card has to be issued. Staff only.
+// @Tags Staff
+// @Accept json
+// @Produce json
+// @Param id path string true "Card ID"
+// @Param input body dto.BlockCardRequest true "Reason"
+// @Success 200 {object} dto.CardResponse "Card blocked"
+// @Failure 400 {object} apperrors.Problem "Bad Request"
+// @Failure 403 {object} apperrors.Problem "Forbidden"
+// @Failure 404 {object} apperrors.Problem "Card not found"
+// @Failure 500 {object} apperrors.Problem "Internal Server Error"
+// @Router /staff/cards/{id}/block [post]
+func (h *Handler) BlockCard(c *gin.Context) {
+	var params dto.IDParam
+	if !bindURI(c, &params) {
+		return
+	}
+
+	var input dto.BlockCardRequest
+	if !bindJSON(c, &input) {
+		return
+	}
+
+	ctx := c.Request.Context()
+
+	var card models.Card
+	err := h.db.Atomic(ctx, func(tx database.Tx) error {
+		var err error
+		card, err = getCard(tx, params.UUID())
+		if err != nil || card.Status == models.CardBlocked {
+			return err
+		}
+		blockCard(&card, input.Reason, time.Now())
+		return putJSON(tx, cardKey(card.ID), card)
+	})
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+
+	h.logger.InfoContext(ctx, "card blocked", "card_id", card.ID, "pan", cards.Mask(card.PAN), "reason", card.BlockReason)
+	c.JSON(http.StatusOK, dto.CardResponse{Card: dto.NewCardView(card)})
+}
+
+func blockCard(card *models.Card, reason string, at time.Time) {
+	card.Status = models.CardBlocked
+	card.BlockReason = reason
+	card.BlockedAt = &at
+}
+
+// CardLogin godoc
+// @Summary Sign in with a card
+// @Description Sign in at an ATM with the card number and the card holder's PIN. The token lasts five minutes, only works from the same ATM while the card can be used, and only for the card's accounts: balance, limits, withdrawals, deposits, transfers from them and holds. Three wrong PINs in a row block the card; the third is answered with PIN_LOCKED.
+// @Tags Card
+// @Accept json
+// @Produce json
+// @Param credentials body dto.CardLoginRequest true "Card number and PIN"
+// @Success 200 {object} dto.CardLoginResponse "Token generated"
+// @Failure 400 {object} apperrors.Problem "Bad Request"
+// @Failure 401 {object} apperrors.Problem "Unknown card or wrong PIN"
+// @Failure 403 {object} apperrors.Problem "Card blocked or expired, or PIN locked"
+// @Failure 500 {object} apperrors.Problem "Internal Server Error"
+// @Router /card/login [post]
+func (h *Handler) CardLogin(c *gin.Context) {
+	var credentials dto.CardLoginRequest
+	if !bindJSON(c, &credentials) {
+		return
+	}
+
+	ctx := c.Request.Context()
+	masked := cards.Mask(credentials.PAN)
+
+	var card models.Card
+	var user models.User
+	var wrongPIN bool
+	err := h.db.Atomic(ctx, func(tx database.Tx) error {
+		wrongPIN = false
+
+		id := tx.Get(cardPANsPrefix + credentials.PAN)
+		if id == nil {
+			return errUnknownCard
+		}
+		cardID, err := uuid.ParseBytes(id)
+		if err != nil {
+			return fmt.Errorf("parsing card index: %w", err)
+		}
+		if card, err = getCard(tx, cardID); err != nil {
+			return err
+		}
+		now := time.Now()
+		if card.Status == models.CardBlocked {
+			return apperrors.New(apperrors.CardUnavailable).WithDetail(i18n.MsgCardBlocked)
+		}
+		if !now.Before(card.ExpiresAt) {
+			return apperrors.New(apperrors.CardUnavailable).WithDetail(i18n.MsgCardExpired)
+		}
+
+		data := tx.Get("users/" + card.UserID.String())
+		if data == nil {
+			return errUnknownCard
+		}
+		if err := json.Unmarshal(data, &user); err != nil {
+			return fmt.Errorf("unmarshaling user data: %w", err)
+		}
+
+		// The attempt count is stored either way, so a wrong PIN is
+		// reported after the transaction.
+		if user.PIN != credentials.PIN {
+			wrongPIN = true
+			card.FailedPINAttempts++
+			if card.FailedPINAttempts >= maxPINAttempts {
+				blockCard(&card, "too many wrong PINs", now)
+			}
+		} else if card.FailedPINAttempts == 0 {
+			return nil
+		} else {
+			card.FailedPINAttempts = 0
+		}
+		return putJSON(tx, cardKey(card.ID), card)
+	})
+	if errors.Is(err, errUnknownCard) {
+		h.logger.WarnContext(ctx, "login for unknown card", "pan", masked)
+		metrics.FailedLoginsTotal.WithLabelValues("unknown_card").Inc()
+		abortWithError(c, apperrors.New(apperrors.InvalidCredentials))
+		return
+	}
+	if err != nil {
+		h.logger.WarnContext(ctx, "card login rejected", "pan", masked, "error", err)
+		abortWithError(c, err)
+		return
+	}
+	if wrongPIN {
+		h.logger.WarnContext(ctx, "invalid card PIN", "pan", masked, "card_id", card.ID, "attempts", card.FailedPINAttempts, "blocked", card.Status == models.CardBlocked)
+		metrics.FailedLoginsTotal.WithLabelValues("invalid_card_pin").Inc()
+		// The PIN that blocks the card is told apart, so the ATM can keep
+		// the card and the customer knows to call the bank.
+		if card.Status == models.CardBlocked {
+			metrics.LockoutsTotal.Inc()
+			abortWithError(c, apperrors.New(apperrors.PINLocked))
+			return
+		}
+		abortWithError(c, apperrors.New(apperrors.InvalidCredentials))
+		return
+	}
+
+	expiresAt := time.Now().Add(cardSessionTTL)
+	token, err := signToken(&Claims{
+		UserID:     user.ID,
+		Language:   user.Language,
+		CardID:     &card.ID,
+		AccountIDs: card.AccountIDs,
+		TerminalID: terminalID(c),
+		StandardClaims: jwt.StandardClaims{
+			ExpiresAt: expiresAt.Unix(),
+			Issuer:    "example.com",
+			Subject:   masked,
+		},
+	})
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+
+	h.logger.InfoContext(ctx, "card session opened", "card_id", card.ID, "pan", masked, "user_id", user.ID)
+	c.JSON(http.StatusOK, dto.CardLoginResponse{Token: token, ExpiresAt: expiresAt, AccountIDs: card.AccountIDs})
+}
+
+// errUnknownCard is reported to the ATM as invalid credentials, like a
+// wrong PIN, so card numbers cannot be probed.
+var errUnknownCard = errors.New("unknown card")
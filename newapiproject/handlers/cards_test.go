@@ -0,0 +1,138 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"newapiprojet/apperrors"
+	"newapiprojet/cards"
+	"newapiprojet/database/memdb"
+	"newapiprojet/dto"
+	"newapiprojet/middlewares"
+	"newapiprojet/models"
+	"newapiprojet/terminals"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// newCardRouter stores an active card whose holder's PIN is "1234" and
+// returns a router for card logins and an account route behind the card
+// session checks. Requests come from the terminal in their terminals.IDHeader,
+// standing in for AuthenticateTerminal.
+func newCardRouter(t *testing.T) (*memdb.DB, *gin.Engine, models.Card) {
+	t.Helper()
+	t.Setenv("JWT_SECRET", "test-secret")
+	h, db := newTestHandler(t)
+
+	pan, err := cards.NewPAN()
+	if err != nil {
+		t.Fatal(err)
+	}
+	user := models.User{ID: uuid.New(), PIN: "1234"}
+	put(t, db, "users/"+user.ID.String(), user)
+	card := models.Card{ID: uuid.New(), PAN: pan, UserID: user.ID, Status: models.CardActive, ExpiresAt: time.Now().AddDate(1, 0, 0)}
+	put(t, db, cardKey(card.ID), card)
+	if err := db.Put(context.Background(), cardPANsPrefix+pan, []byte(card.ID.String())); err != nil {
+		t.Fatal(err)
+	}
+
+	r := gin.New()
+	r.Use(middlewares.NewNewapiprojetMiddlewares(testLogger).ErrorMiddleware())
+	fromTerminal := func(c *gin.Context) {
+		if id, err := uuid.Parse(c.GetHeader(terminals.IDHeader)); err == nil {
+			c.Set("terminalID", id)
+		}
+		c.Next()
+	}
+	r.POST("/card/login", fromTerminal, h.CardLogin)
+	r.GET("/account/ping", middlewares.AuthenticateJWT(), fromTerminal, h.CheckCardSession(), func(c *gin.Context) {
+		c.Status(http.StatusNoContent)
+	})
+	return db, r, card
+}
+
+// cardRequest sends a request from terminal, if it is not nil, with token,
+// if it is not empty.
+func cardRequest(r *gin.Engine, method, path, body string, terminal *uuid.UUID, token string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	if terminal != nil {
+		req.Header.Set(terminals.IDHeader, terminal.String())
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestCardLoginLocksAfterWrongPINs(t *testing.T) {
+	db, r, card := newCardRouter(t)
+	terminal := uuid.New()
+	login := func(pin string) *httptest.ResponseRecorder {
+		return cardRequest(r, http.MethodPost, "/card/login", `{"pan":"`+card.PAN+`","pin":"`+pin+`"}`, &terminal, "")
+	}
+
+	for i := 1; i < maxPINAttempts; i++ {
+		if w := login("0000"); w.Code != http.StatusUnauthorized || problemCode(t, w) != apperrors.InvalidCredentials {
+			t.Fatalf("wrong PIN %d = %d %s, want invalid credentials", i, w.Code, w.Body)
+		}
+	}
+	if w := login("0000"); w.Code != http.StatusForbidden || problemCode(t, w) != apperrors.PINLocked {
+		t.Fatalf("last wrong PIN = %d %s, want the PIN locked", w.Code, w.Body)
+	}
+	var stored models.Card
+	get(t, db, cardKey(card.ID), &stored)
+	if stored.Status != models.CardBlocked {
+		t.Errorf("card status = %s, want %s", stored.Status, models.CardBlocked)
+	}
+
+	// Once blocked, even the right PIN is refused.
+	if w := login("1234"); w.Code != http.StatusForbidden || problemCode(t, w) != apperrors.CardUnavailable {
+		t.Errorf("login with a blocked card = %d %s, want the card unavailable", w.Code, w.Body)
+	}
+}
+
+func TestCheckCardSession(t *testing.T) {
+	db, r, card := newCardRouter(t)
+	terminal := uuid.New()
+	other := uuid.New()
+
+	w := cardRequest(r, http.MethodPost, "/card/login", `{"pan":"`+card.PAN+`","pin":"1234"}`, &terminal, "")
+	if w.Code != http.StatusOK {
+		t.Fatalf("login = %d %s", w.Code, w.Body)
+	}
+	var resp dto.CardLoginResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		terminal *uuid.UUID
+		want     int
+	}{
+		{"same terminal", &terminal, http.StatusNoContent},
+		{"another terminal", &other, http.StatusForbidden},
+		{"no terminal", nil, http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		if w := cardRequest(r, http.MethodGet, "/account/ping", "", tt.terminal, resp.Token); w.Code != tt.want {
+			t.Errorf("%s = %d %s, want %d", tt.name, w.Code, w.Body, tt.want)
+		}
+	}
+
+	// A card blocked during the session cannot be used any more.
+	card.Status = models.CardBlocked
+	put(t, db, cardKey(card.ID), card)
+	if w := cardRequest(r, http.MethodGet, "/account/ping", "", &terminal, resp.Token); w.Code != http.StatusForbidden || problemCode(t, w) != apperrors.CardUnavailable {
+		t.Errorf("blocked card = %d %s, want the card unavailable", w.Code, w.Body)
+	}
+}
@@ -0,0 +1,147 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"newapiprojet/apperrors"
+	"newapiprojet/cassettes"
+	"newapiprojet/config"
+	"newapiprojet/database"
+	"newapiprojet/dto"
+	"newapiprojet/i18n"
+	"newapiprojet/metrics"
+	"newapiprojet/models"
+	"newapiprojet/money"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// Each terminal's cash is stored in one key, so a withdrawal takes its notes
+// in the same transaction as the account debit:
+//
+//	cassettes/<terminalID>  models.CashInventory
+const cassettesPrefix = "cassettes/"
+
+func cassettesKey(terminalID uuid.UUID) string {
+	return cassettesPrefix + terminalID.String()
+}
+
+// getInventory returns the cash in a terminal's cassettes, empty if none
+// has been recorded.
+func getInventory(tx database.Tx, terminalID uuid.UUID) (models.CashInventory, error) {
+	inventory := models.CashInventory{TerminalID: terminalID}
+	data := tx.Get(cassettesKey(terminalID))
+	if data == nil {
+		return inventory, nil
+	}
+	if err := json.Unmarshal(data, &inventory); err != nil {
+		return inventory, fmt.Errorf("unmarshaling cash inventory: %w", err)
+	}
+	return inventory, nil
+}
+
+// dispense plans the notes for paying out amount at the request's terminal
+// and takes them from its cassettes as part of tx. Requests that do not
+// come from a terminal pay out no notes.
+func (h *Handler) dispense(c *gin.Context, tx database.Tx, amount money.Money, at time.Time) ([]models.Notes, error) {
+	id := terminalID(c)
+	if id == nil {
+		return nil, nil
+	}
+	inventory, err := getInventory(tx, *id)
+	if err != nil {
+		return nil, err
+	}
+	conf := config.GetConfig()
+	notes, err := cassettes.Plan(amount, inventory.Cassettes, conf.MaxNotesPerDispense, cassettes.Strategy(conf.DispenseStrategy))
+	if err != nil {
+		return nil, dispenseError(err)
+	}
+	if inventory.Cassettes, err = cassettes.Take(inventory.Cassettes, notes); err != nil {
+		return nil, err
+	}
+	inventory.UpdatedAt = at
+	return notes, putJSON(tx, cassettesKey(*id), inventory)
+}
+
+// returnNotes puts notes the terminal failed to pay out back into its
+// cassettes as part of tx.
+func returnNotes(tx database.Tx, terminalID uuid.UUID, notes []models.Notes, at time.Time) error {
+	inventory, err := getInventory(tx, terminalID)
+	if err != nil {
+		return err
+	}
+	inventory.Cassettes = cassettes.Return(inventory.Cassettes, notes)
+	inventory.UpdatedAt = at
+	return putJSON(tx, cassettesKey(terminalID), inventory)
+}
+
+// dispenseError maps a failed dispense plan onto an API error.
+func dispenseError(err error) error {
+	switch {
+	case errors.Is(err, cassettes.ErrCannotDispense):
+		return apperrors.Wrap(apperrors.CannotDispense, err).WithDetail(i18n.MsgDispenseNotes)
+	case errors.Is(err, cassettes.ErrTooManyNotes):
+		return apperrors.Wrap(apperrors.CannotDispense, err).WithDetail(i18n.MsgDispenseTooManyNotes)
+	default:
+		return err
+	}
+}
+
+// checkDenominations rejects notes that list a denomination twice.
+func checkDenominations(notes []models.Notes) error {
+	for i, n := range notes {
+		for _, other := range notes[:i] {
+			if other.Denomination == n.Denomination {
+				return apperrors.New(apperrors.InvalidRequest).WithDetail(i18n.MsgCassetteDuplicate)
+			}
+		}
+	}
+	return nil
+}
+
+// countDispensed records the notes paid out in the metrics.
+func countDispensed(notes []models.Notes) {
+	for _, n := range notes {
+		metrics.NotesDispensedTotal.WithLabelValues(string(n.Denomination.Currency()), n.Denomination.Decimal()).Add(float64(n.Count))
+	}
+}
+
+// GetCassettes godoc
+// @Summary Show an ATM's cash
+// @Description Show how many notes of each denomination are in a terminal's cassettes. Staff only.
+// @Tags Staff
+// @Produce json
+// @Param id path string true "Terminal ID"
+// @Success 200 {object} dto.CassettesResponse "Cash in the cassettes"
+// @Failure 400 {object} apperrors.Problem "Bad Request"
+// @Failure 403 {object} apperrors.Problem "Forbidden"
+// @Failure 404 {object} apperrors.Problem "Terminal not found"
+// @Failure 500 {object} apperrors.Problem "Internal Server Error"
+// @Router /staff/terminals/{id}/cassettes [get]
+func (h *Handler) GetCassettes(c *gin.Context) {
+	var params dto.IDParam
+	if !bindURI(c, &params) {
+		return
+	}
+
+	var inventory models.CashInventory
+	err := h.db.Atomic(c.Request.Context(), func(tx database.Tx) error {
+		if _, err := getTerminal(tx, params.UUID()); err != nil {
+			return err
+		}
+		var err error
+		inventory, err = getInventory(tx, params.UUID())
+		return err
+	})
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.NewCassettesResponse(inventory))
+}
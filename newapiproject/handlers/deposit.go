@@ -1,9 +1,15 @@
 package handlers
 
 import (
-	"context"
-	"encoding/json"
+	"errors"
 	"net/http"
+	"newapiprojet/apperrors"
+	"newapiprojet/database"
+	"newapiprojet/dto"
+	"newapiprojet/exchange"
+	"newapiprojet/i18n"
+	"newapiprojet/ledger"
+	"newapiprojet/metrics"
 	"newapiprojet/models"
 	"time"
 
@@ -13,101 +19,107 @@ import (
 
 // Deposit godoc
 // @Summary Deposit money into an account
-// @Description Deposit money into an account
+// @Description Deposit money into an account. Amounts in another currency are converted at the current exchange rate.
 // @Tags Account
 // @Accept json
 // @Produce json
-// @Param input body struct{ AccountID uuid.UUID `json:"accountID"`; DepositAmount int `json:"depositAmount"` } true "Deposit details"
-// @Success 200 {string} string "Deposit successful"
-// @Failure 400 {string} string "Bad Request"
-// @Failure 404 {string} string "Account not found"
-// @Failure 403 {string} string "Forbidden"
-// @Failure 500 {string} string "Internal Server Error"
+// @Param input body dto.DepositRequest true "Deposit details"
+// @Success 200 {object} dto.TransactionResponse "Deposit successful"
+// @Failure 400 {object} apperrors.Problem "Bad Request"
+// @Failure 404 {object} apperrors.Problem "Account not found"
+// @Failure 403 {object} apperrors.Problem "Forbidden"
+// @Failure 422 {object} apperrors.Problem "No exchange rate"
+// @Failure 500 {object} apperrors.Problem "Internal Server Error"
 // @Router /account/deposit [post]
 func (h *Handler) Deposit(c *gin.Context) {
-	var input struct {
-		AccountID     uuid.UUID `json:"accountID"`
-		DepositAmount int       `json:"depositAmount"`
-	}
+	var input dto.DepositRequest
 
-	if err := c.BindJSON(&input); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+	if !bindJSON(c, &input) {
 		return
 	}
 
 	userID, exists := c.Get("userID")
 	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Authentication error"})
+		abortWithError(c, apperrors.New(apperrors.Unauthorized))
 		return
 	}
 
 	userIDUUID, ok := userID.(uuid.UUID)
 	if !ok {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "User ID conversion error"})
+		abortWithError(c, errors.New("user ID in context is not a UUID"))
 		return
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	defer cancel()
-
-	resp, err := h.db.Get(ctx, "accounts/"+input.AccountID.String())
-	if err != nil || resp == nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Account not found"})
+	if err := checkCardScope(c, input.AccountID); err != nil {
+		abortWithError(c, err)
 		return
 	}
 
-	var account models.Account
-	err = json.Unmarshal(resp, &account)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Unable to unmarshal account data"})
-		return
-	}
+	ctx := c.Request.Context()
 
-	if account.UserID != userIDUUID {
-		c.JSON(http.StatusForbidden, gin.H{"error": "Access denied"})
-		return
-	}
-
-	if input.DepositAmount <= 0 {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Deposit amount must be positive"})
-		return
-	}
-
-	account.Balance += input.DepositAmount
-
-	accountData, err := json.Marshal(account)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Unable to marshal account data"})
-		return
-	}
-
-	err = h.db.Put(context.Background(), "accounts/"+account.ID.String(), accountData)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Unable to update account data"})
-		return
-	}
-
-	deposit := models.Deposit{
-		ID:            uuid.New(),
-		AccountID:     account.ID,
-		DepositAmount: input.DepositAmount,
-		DepositDate:   time.Now(),
-	}
-
-	depositData, err := json.Marshal(deposit)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Unable to marshal deposit data"})
-		return
-	}
-
-	err = h.db.Put(context.Background(), "deposits/"+deposit.ID.String(), depositData)
+	var account models.Account
+	var deposit models.Deposit
+	err := h.db.Atomic(ctx, func(tx database.Tx) error {
+		var err error
+		account, err = getOwnedAccount(tx, input.AccountID, userIDUUID)
+		if err != nil {
+			return err
+		}
+		if err := ledger.CanCredit(account); err != nil {
+			return statusError(err)
+		}
+
+		deposit = models.Deposit{
+			ID:            uuid.New(),
+			AccountID:     account.ID,
+			DepositAmount: input.DepositAmount,
+			DepositDate:   time.Now(),
+			TerminalID:    terminalID(c),
+		}
+
+		credit := input.DepositAmount
+		if credit.Currency() != account.Balance.Currency() {
+			conversion, err := h.rates.Convert(ctx, credit, account.Balance.Currency(), exchange.Credit)
+			if err != nil {
+				return exchangeError(err)
+			}
+			credit = conversion.To
+			deposit.Conversion = &conversion
+		}
+
+		if err := ledger.Credit(tx, &account, credit, deposit.DepositDate); err != nil {
+			return moneyError(err)
+		}
+		ledger.RecordActivity(&account, deposit.DepositDate)
+
+		if err := recordTerminalCash(tx, deposit.TerminalID, models.TerminalCash{
+			TransactionID:   deposit.ID,
+			TransactionType: models.ReversibleDeposit,
+			Amount:          deposit.DepositAmount,
+			At:              deposit.DepositDate,
+		}); err != nil {
+			return err
+		}
+		if err := putJSON(tx, accountKey(account.ID), account); err != nil {
+			return err
+		}
+		return putJSON(tx, "deposits/"+deposit.ID.String(), deposit)
+	})
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Unable to store deposit data"})
+		abortWithError(c, err)
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{
-		"message": "Deposit successful",
-		"balance": account.Balance,
+	metrics.DepositsTotal.Inc()
+	metrics.AmountMovedTotal.WithLabelValues("deposit", string(input.DepositAmount.Currency())).Add(input.DepositAmount.Float64())
+
+	lang := i18n.FromContext(ctx)
+	c.JSON(http.StatusOK, dto.TransactionResponse{
+		Message:          i18n.T(lang, i18n.MsgDepositSuccessful),
+		TransactionID:    deposit.ID,
+		Balance:          account.Balance,
+		BalanceFormatted: i18n.FormatMoney(lang, account.Balance),
+		Date:             i18n.FormatDate(lang, deposit.DepositDate),
+		Conversion:       dto.NewConversionView(deposit.Conversion),
 	})
 }
@@ -0,0 +1,55 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"newapiprojet/dormancy"
+	"newapiprojet/dto"
+	"newapiprojet/models"
+	"sort"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxDormancyReports is how many reports GET /staff/reports/dormancy returns.
+const maxDormancyReports = 30
+
+// DormancyReports godoc
+// @Summary List dormancy reports
+// @Description List the reports of the dormancy job, newest first, up to 30. Each report lists the accounts one run made dormant; runs that found none store no report. Staff only.
+// @Tags Staff
+// @Produce json
+// @Success 200 {object} dto.DormancyReportsResponse "Dormancy reports"
+// @Failure 403 {object} apperrors.Problem "Forbidden"
+// @Failure 500 {object} apperrors.Problem "Internal Server Error"
+// @Router /staff/reports/dormancy [get]
+func (h *Handler) DormancyReports(c *gin.Context) {
+	data, err := h.db.List(c.Request.Context(), dormancy.ReportsPrefix)
+	if err != nil {
+		abortWithError(c, fmt.Errorf("listing dormancy reports: %w", err))
+		return
+	}
+
+	// Keys end in the zero-padded run time, so they sort by age.
+	keys := make([]string, 0, len(data))
+	for key := range data {
+		keys = append(keys, key)
+	}
+	sort.Sort(sort.Reverse(sort.StringSlice(keys)))
+	if len(keys) > maxDormancyReports {
+		keys = keys[:maxDormancyReports]
+	}
+
+	reports := make([]models.DormancyReport, 0, len(keys))
+	for _, key := range keys {
+		var report models.DormancyReport
+		if err := json.Unmarshal(data[key], &report); err != nil {
+			abortWithError(c, fmt.Errorf("unmarshaling dormancy report %s: %w", strings.TrimPrefix(key, dormancy.ReportsPrefix), err))
+			return
+		}
+		reports = append(reports, report)
+	}
+	c.JSON(http.StatusOK, dto.DormancyReportsResponse{Reports: reports})
+}
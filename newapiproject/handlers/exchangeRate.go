@@ -0,0 +1,60 @@
+package handlers
+
+import (
+	"net/http"
+	"newapiprojet/dto"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetExchangeRates godoc
+// @Summary List current exchange rates
+// @Description List the exchange rate in effect for every currency pair
+// @Tags Exchange
+// @Produce json
+// @Success 200 {object} dto.ExchangeRatesResponse "Current rates"
+// @Failure 500 {object} apperrors.Problem "Internal Server Error"
+// @Router /rates [get]
+func (h *Handler) GetExchangeRates(c *gin.Context) {
+	rates, err := h.rates.Current(c.Request.Context())
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+
+	views := make([]dto.ExchangeRateView, 0, len(rates))
+	for _, rate := range rates {
+		views = append(views, dto.NewExchangeRateView(rate))
+	}
+	c.JSON(http.StatusOK, dto.ExchangeRatesResponse{Rates: views})
+}
+
+// SetExchangeRate godoc
+// @Summary Publish an exchange rate
+// @Description Store a rate for a currency pair, effective now or at effective_at. Admin only.
+// @Tags Exchange
+// @Accept json
+// @Produce json
+// @Param input body dto.ExchangeRateRequest true "Exchange rate"
+// @Success 201 {object} dto.ExchangeRateView "Exchange rate saved"
+// @Failure 400 {object} apperrors.Problem "Bad Request"
+// @Failure 403 {object} apperrors.Problem "Forbidden"
+// @Failure 500 {object} apperrors.Problem "Internal Server Error"
+// @Router /admin/rates [post]
+func (h *Handler) SetExchangeRate(c *gin.Context) {
+	var input dto.ExchangeRateRequest
+
+	if !bindJSON(c, &input) {
+		return
+	}
+
+	ctx := c.Request.Context()
+	rate, err := h.rates.SetRate(ctx, input.ToExchangeRate())
+	if err != nil {
+		abortWithError(c, exchangeError(err))
+		return
+	}
+
+	h.logger.InfoContext(ctx, "exchange rate saved", "base", rate.Base, "quote", rate.Quote, "rate", rate.Rate, "effective_at", rate.EffectiveAt)
+	c.JSON(http.StatusCreated, dto.NewExchangeRateView(rate))
+}
@@ -0,0 +1,70 @@
+package handlers
+
+import (
+	"net/http"
+	"newapiprojet/dto"
+	"newapiprojet/money"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetFeeSchedules godoc
+// @Summary List fee schedules
+// @Description List the fee rules of every currency. Admin only.
+// @Tags Fees
+// @Produce json
+// @Success 200 {object} dto.FeeSchedulesResponse "Fee schedules"
+// @Failure 403 {object} apperrors.Problem "Forbidden"
+// @Failure 500 {object} apperrors.Problem "Internal Server Error"
+// @Router /admin/fees [get]
+func (h *Handler) GetFeeSchedules(c *gin.Context) {
+	schedules, err := h.fees.Schedules(c.Request.Context())
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+
+	views := make([]dto.FeeScheduleView, 0, len(schedules))
+	for _, schedule := range schedules {
+		views = append(views, dto.NewFeeScheduleView(schedule))
+	}
+	c.JSON(http.StatusOK, dto.FeeSchedulesResponse{Schedules: views})
+}
+
+// SetFeeSchedule godoc
+// @Summary Replace a fee schedule
+// @Description Replace the fee rules for accounts in a currency. Amounts are in that currency. Admin only.
+// @Tags Fees
+// @Accept json
+// @Produce json
+// @Param currency path string true "Currency code"
+// @Param input body dto.FeeScheduleRequest true "Fee rules"
+// @Success 200 {object} dto.FeeScheduleView "Fee schedule saved"
+// @Failure 400 {object} apperrors.Problem "Bad Request"
+// @Failure 403 {object} apperrors.Problem "Forbidden"
+// @Failure 500 {object} apperrors.Problem "Internal Server Error"
+// @Router /admin/fees/{currency} [put]
+func (h *Handler) SetFeeSchedule(c *gin.Context) {
+	var params dto.CurrencyParam
+	if !bindURI(c, &params) {
+		return
+	}
+
+	var input dto.FeeScheduleRequest
+	if !bindJSON(c, &input) {
+		return
+	}
+
+	// The code was checked by the currency validator.
+	currency, _ := money.ParseCurrency(params.Currency)
+
+	ctx := c.Request.Context()
+	schedule, err := h.fees.SetSchedule(ctx, input.ToFeeSchedule(currency))
+	if err != nil {
+		abortWithError(c, feeError(err))
+		return
+	}
+
+	h.logger.InfoContext(ctx, "fee schedule saved", "currency", schedule.Currency, "rules", len(schedule.Rules))
+	c.JSON(http.StatusOK, dto.NewFeeScheduleView(schedule))
+}
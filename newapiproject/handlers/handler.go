@@ -1,13 +1,149 @@
 package handlers
 
 import (
+	"errors"
+	"log/slog"
+	"newapiprojet/apperrors"
+	"newapiprojet/calendar"
 	"newapiprojet/database"
+	"newapiprojet/exchange"
+	"newapiprojet/fees"
+	"newapiprojet/i18n"
+	"newapiprojet/ledger"
+	"newapiprojet/limits"
+	"newapiprojet/money"
+	"newapiprojet/validation"
+	"strings"
+
+	"github.com/gin-gonic/gin"
 )
 
 type Handler struct {
-	db database.Database
+	db     database.Database
+	leases database.Leases
+	cal    *calendar.Calendar
+	rates  *exchange.Service
+	limits *limits.Service
+	fees   *fees.Service
+	logger *slog.Logger
+}
+
+// NewHandler returns a Handler whose daily limits and schedules follow
+// cal's business days. limitDefaults apply to accounts without withdrawal
+// limits of their own. Holds expire and terminal nonces are forgotten
+// through leases; with nil leases holds only expire when ExpireHolds runs,
+// and nonces are kept.
+func NewHandler(db database.Database, leases database.Leases, cal *calendar.Calendar, limitDefaults limits.Defaults, logger *slog.Logger) *Handler {
+	return &Handler{
+		db:     db,
+		leases: leases,
+		cal:    cal,
+		rates:  exchange.NewService(db),
+		limits: limits.NewService(cal, limitDefaults),
+		fees:   fees.NewService(db, cal),
+		logger: logger,
+	}
+}
+
+// atmNetworkHeader is sent by ATMs; "foreign" means another bank's ATM,
+// where fee rules marked foreign_only apply.
+const atmNetworkHeader = "X-ATM-Network"
+
+func foreignATM(c *gin.Context) bool {
+	return strings.EqualFold(c.GetHeader(atmNetworkHeader), "foreign")
+}
+
+// abortWithError stops the request and leaves err for
+// middlewares.ErrorMiddleware to render as problem+json.
+func abortWithError(c *gin.Context, err error) {
+	_ = c.Error(err)
+	c.Abort()
+}
+
+// moneyError maps failed money arithmetic and ledger postings onto API
+// errors.
+func moneyError(err error) error {
+	switch {
+	case errors.Is(err, ledger.ErrInsufficientFunds):
+		return apperrors.Wrap(apperrors.InsufficientFunds, err)
+	case errors.Is(err, money.ErrCurrencyMismatch):
+		return apperrors.Wrap(apperrors.CurrencyMismatch, err)
+	case errors.Is(err, money.ErrOverflow):
+		return apperrors.Wrap(apperrors.InvalidAmount, err)
+	}
+	return err
+}
+
+// exchangeError maps failed currency conversions onto API errors.
+func exchangeError(err error) error {
+	switch {
+	case errors.Is(err, exchange.ErrRateUnavailable):
+		return apperrors.Wrap(apperrors.RateUnavailable, err)
+	case errors.Is(err, exchange.ErrInvalidRate):
+		return apperrors.Wrap(apperrors.InvalidRate, err)
+	}
+	return moneyError(err)
+}
+
+// feeError maps rejected fee schedules onto API errors.
+func feeError(err error) error {
+	if errors.Is(err, fees.ErrInvalidSchedule) {
+		return apperrors.Wrap(apperrors.InvalidRequest, err).WithDetail(i18n.MsgFeeScheduleInvalid)
+	}
+	if errors.Is(err, money.ErrUnknownCurrency) {
+		return apperrors.Wrap(apperrors.InvalidRequest, err)
+	}
+	return moneyError(err)
+}
+
+// limitError maps rejected withdrawal limits onto API errors.
+func limitError(err error) error {
+	switch {
+	case errors.Is(err, limits.ErrPerTransaction):
+		return apperrors.Wrap(apperrors.LimitExceeded, err).WithDetail(i18n.MsgLimitPerTransaction)
+	case errors.Is(err, limits.ErrDaily):
+		return apperrors.Wrap(apperrors.LimitExceeded, err).WithDetail(i18n.MsgLimitDaily)
+	case errors.Is(err, limits.ErrDailyCount):
+		return apperrors.Wrap(apperrors.LimitExceeded, err).WithDetail(i18n.MsgLimitDailyCount)
+	case errors.Is(err, limits.ErrInvalidLimit):
+		return apperrors.Wrap(apperrors.InvalidAmount, err).WithDetail(i18n.MsgLimitNegative)
+	}
+	return moneyError(err)
+}
+
+// statusError maps operations refused by an account's status, and refused
+// status changes, onto API errors.
+func statusError(err error) error {
+	switch {
+	case errors.Is(err, ledger.ErrAccountFrozen):
+		return apperrors.Wrap(apperrors.AccountUnavailable, err).WithDetail(i18n.MsgAccountFrozen)
+	case errors.Is(err, ledger.ErrAccountDormant):
+		return apperrors.Wrap(apperrors.AccountUnavailable, err).WithDetail(i18n.MsgAccountDormant)
+	case errors.Is(err, ledger.ErrAccountClosed):
+		return apperrors.Wrap(apperrors.AccountUnavailable, err).WithDetail(i18n.MsgAccountClosed)
+	case errors.Is(err, ledger.ErrInvalidTransition):
+		return apperrors.Wrap(apperrors.Conflict, err).WithDetail(i18n.MsgAccountTransition)
+	case errors.Is(err, ledger.ErrBalanceNotZero):
+		return apperrors.Wrap(apperrors.Conflict, err).WithDetail(i18n.MsgAccountBalanceNotZero)
+	}
+	return err
+}
+
+// bindJSON decodes and validates the request body into obj. On failure it
+// aborts with the validation errors and returns false.
+func bindJSON(c *gin.Context, obj any) bool {
+	if err := c.ShouldBindJSON(obj); err != nil {
+		abortWithError(c, validation.FromBindError(err))
+		return false
+	}
+	return true
 }
 
-func NewHandler(db database.Database) *Handler {
-	return &Handler{db: db}
+// bindURI is bindJSON for path parameters.
+func bindURI(c *gin.Context, obj any) bool {
+	if err := c.ShouldBindUri(obj); err != nil {
+		abortWithError(c, validation.FromBindError(err))
+		return false
+	}
+	return true
 }
@@ -0,0 +1,157 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"newapiprojet/apperrors"
+	"newapiprojet/calendar"
+	"newapiprojet/database/memdb"
+	"newapiprojet/limits"
+	"newapiprojet/middlewares"
+	"newapiprojet/models"
+	"newapiprojet/money"
+	"newapiprojet/validation"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// TestMain runs the tests in a scratch directory holding a copy of
+// config/config.json, which config.GetConfig reads and may rewrite.
+func TestMain(m *testing.M) {
+	os.Exit(runTests(m))
+}
+
+func runTests(m *testing.M) int {
+	data, err := os.ReadFile(filepath.Join("..", "config", "config.json"))
+	if err != nil {
+		panic(err)
+	}
+	var conf map[string]any
+	if err := json.Unmarshal(data, &conf); err != nil {
+		panic(err)
+	}
+	conf["last_reset"] = time.Now()
+	if data, err = json.Marshal(conf); err != nil {
+		panic(err)
+	}
+
+	dir, err := os.MkdirTemp("", "handlers")
+	if err != nil {
+		panic(err)
+	}
+	defer os.RemoveAll(dir)
+	if err := os.Mkdir(filepath.Join(dir, "config"), 0o755); err != nil {
+		panic(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "config", "config.json"), data, 0o644); err != nil {
+		panic(err)
+	}
+	if err := os.Chdir(dir); err != nil {
+		panic(err)
+	}
+
+	gin.SetMode(gin.TestMode)
+	if err := validation.Register(0); err != nil {
+		panic(err)
+	}
+	return m.Run()
+}
+
+var testLogger = slog.New(slog.NewTextHandler(io.Discard, nil))
+
+func newTestHandler(t *testing.T) (*Handler, *memdb.DB) {
+	t.Helper()
+	cal, err := calendar.New("Europe/Istanbul", "00:00")
+	if err != nil {
+		t.Fatal(err)
+	}
+	db := memdb.New()
+	return NewHandler(db, db, cal, limits.Defaults{}, testLogger), db
+}
+
+// newTestRouter returns a router whose requests are made by userID, with
+// errors rendered as in production.
+func newTestRouter(userID uuid.UUID) *gin.Engine {
+	r := gin.New()
+	r.Use(middlewares.NewNewapiprojetMiddlewares(testLogger).ErrorMiddleware())
+	r.Use(func(c *gin.Context) {
+		c.Set("userID", userID)
+		c.Next()
+	})
+	return r
+}
+
+func serve(r *gin.Engine, method, path, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func put(t *testing.T, db *memdb.DB, key string, v any) {
+	t.Helper()
+	data, err := json.Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Put(context.Background(), key, data); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// get reads key into v and reports whether it was there.
+func get(t *testing.T, db *memdb.DB, key string, v any) bool {
+	t.Helper()
+	data, err := db.Get(context.Background(), key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if data == nil {
+		return false
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		t.Fatal(err)
+	}
+	return true
+}
+
+func putAccount(t *testing.T, db *memdb.DB, balance money.Money) models.Account {
+	t.Helper()
+	account := models.Account{ID: uuid.New(), UserID: uuid.New(), Balance: balance}
+	put(t, db, accountKey(account.ID), account)
+	return account
+}
+
+func getTestAccount(t *testing.T, db *memdb.DB, id uuid.UUID) models.Account {
+	t.Helper()
+	var account models.Account
+	if !get(t, db, accountKey(id), &account) {
+		t.Fatalf("account %s not stored", id)
+	}
+	return account
+}
+
+// problemCode returns the code of the problem+json body in w.
+func problemCode(t *testing.T, w *httptest.ResponseRecorder) apperrors.Code {
+	t.Helper()
+	var problem apperrors.Problem
+	if err := json.Unmarshal(w.Body.Bytes(), &problem); err != nil {
+		t.Fatalf("body %q: %v", w.Body, err)
+	}
+	return problem.Code
+}
+
+func lira(major int64) money.Money {
+	return money.New(major*100, money.TRY)
+}
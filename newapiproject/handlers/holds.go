@@ -0,0 +1,596 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"newapiprojet/apperrors"
+	"newapiprojet/database"
+	"newapiprojet/dto"
+	"newapiprojet/fees"
+	"newapiprojet/i18n"
+	"newapiprojet/ledger"
+	"newapiprojet/metrics"
+	"newapiprojet/models"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// Holds are stored one key each. While a hold is active, a key under
+// hold_leases/ is bound to an etcd lease that runs out when the hold
+// expires; its deletion is what expires the hold:
+//
+//	holds/<holdID>        models.Hold
+//	hold_leases/<holdID>  the hold's account ID
+const (
+	holdsPrefix      = "holds/"
+	holdLeasesPrefix = "hold_leases/"
+)
+
+// defaultHoldTTL applies when a hold request does not set an expiry.
+const defaultHoldTTL = 15 * time.Minute
+
+// watchRetryDelay is how long to wait before watching hold leases again
+// after the watch failed.
+const watchRetryDelay = 5 * time.Second
+
+func holdKey(id uuid.UUID) string {
+	return holdsPrefix + id.String()
+}
+
+func holdLeaseKey(id uuid.UUID) string {
+	return holdLeasesPrefix + id.String()
+}
+
+// getHold reads a hold, or returns nil if there is none.
+func getHold(tx database.Tx, id uuid.UUID) (*models.Hold, error) {
+	data := tx.Get(holdKey(id))
+	if data == nil {
+		return nil, nil
+	}
+	var hold models.Hold
+	if err := json.Unmarshal(data, &hold); err != nil {
+		return nil, fmt.Errorf("unmarshaling hold: %w", err)
+	}
+	return &hold, nil
+}
+
+// getOwnedHold reads a hold that must belong to userID.
+func getOwnedHold(tx database.Tx, id, userID uuid.UUID) (models.Hold, error) {
+	hold, err := getHold(tx, id)
+	if err != nil {
+		return models.Hold{}, err
+	}
+	if hold == nil {
+		return models.Hold{}, apperrors.New(apperrors.HoldNotFound)
+	}
+	if hold.UserID != userID {
+		return *hold, apperrors.New(apperrors.Forbidden).WithDetail(i18n.MsgAccountAccessDenied)
+	}
+	return *hold, nil
+}
+
+// getActiveHold is getOwnedHold for holds that can still be captured or
+// released at now.
+func getActiveHold(tx database.Tx, id, userID uuid.UUID, now time.Time) (models.Hold, error) {
+	hold, err := getOwnedHold(tx, id, userID)
+	if err != nil {
+		return hold, err
+	}
+	if hold.Status != models.HoldActive || !now.Before(hold.ExpiresAt) {
+		return hold, apperrors.New(apperrors.Conflict).WithDetail(i18n.MsgHoldState)
+	}
+	return hold, nil
+}
+
+// newHoldResponse renders hold with account's balances after a change.
+func newHoldResponse(message string, hold models.Hold, account models.Account) (dto.HoldResponse, error) {
+	available, err := ledger.Available(account)
+	if err != nil {
+		return dto.HoldResponse{}, moneyError(err)
+	}
+	return dto.HoldResponse{
+		Message:          message,
+		Hold:             dto.NewHoldView(hold),
+		Balance:          account.Balance,
+		AvailableBalance: available,
+	}, nil
+}
+
+// PlaceHold godoc
+// @Summary Place a hold
+// @Description Reserve an amount of the account's available balance before the final amount is known, for example before dispensing cash. The hold counts against the withdrawal limits and lowers the available balance, but the balance itself only changes when the hold is captured. Holds not captured or released expire after expires_in_seconds (15 minutes by default) and the money becomes available again.
+// @Tags Account
+// @Accept json
+// @Produce json
+// @Param input body dto.HoldRequest true "Hold details"
+// @Success 201 {object} dto.HoldResponse "Hold placed"
+// @Failure 400 {object} apperrors.Problem "Bad Request or insufficient funds"
+// @Failure 403 {object} apperrors.Problem "Forbidden"
+// @Failure 404 {object} apperrors.Problem "Account not found"
+// @Failure 409 {object} apperrors.Problem "Account unavailable"
+// @Failure 422 {object} apperrors.Problem "Withdrawal limit exceeded"
+// @Failure 500 {object} apperrors.Problem "Internal Server Error"
+// @Router /account/holds [post]
+func (h *Handler) PlaceHold(c *gin.Context) {
+	var input dto.HoldRequest
+	if !bindJSON(c, &input) {
+		return
+	}
+
+	userID, exists := c.Get("userID")
+	if !exists {
+		abortWithError(c, apperrors.New(apperrors.Unauthorized))
+		return
+	}
+
+	userIDUUID, ok := userID.(uuid.UUID)
+	if !ok {
+		abortWithError(c, errors.New("user ID in context is not a UUID"))
+		return
+	}
+
+	ttl := defaultHoldTTL
+	if input.ExpiresInSeconds > 0 {
+		ttl = time.Duration(input.ExpiresInSeconds) * time.Second
+	}
+	now := time.Now()
+	hold := models.Hold{
+		ID:        uuid.New(),
+		AccountID: input.AccountID,
+		UserID:    userIDUUID,
+		Amount:    input.Amount,
+		Reference: input.Reference,
+		Status:    models.HoldActive,
+		CreatedAt: now,
+		ExpiresAt: now.Add(ttl),
+	}
+
+	if err := checkCardScope(c, input.AccountID); err != nil {
+		abortWithError(c, err)
+		return
+	}
+
+	ctx := c.Request.Context()
+
+	var account models.Account
+	err := h.db.Atomic(ctx, func(tx database.Tx) error {
+		var err error
+		account, err = getOwnedAccount(tx, input.AccountID, userIDUUID)
+		if err != nil {
+			return err
+		}
+		if err := ledger.CanDebit(account); err != nil {
+			return statusError(err)
+		}
+		if hold.Amount.Currency() != account.Balance.Currency() {
+			return apperrors.New(apperrors.CurrencyMismatch).WithDetail(i18n.MsgHoldCurrency)
+		}
+		if err := h.limits.Reserve(tx, account, hold.Amount); err != nil {
+			return limitError(err)
+		}
+		if err := ledger.Hold(&account, hold.Amount); err != nil {
+			return moneyError(err)
+		}
+		ledger.RecordActivity(&account, now)
+
+		if err := putJSON(tx, accountKey(account.ID), account); err != nil {
+			return err
+		}
+		return putJSON(tx, holdKey(hold.ID), hold)
+	})
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+
+	// Without the lease the hold still expires when ExpireHolds next runs.
+	if h.leases != nil {
+		if err := h.leases.PutWithTTL(ctx, holdLeaseKey(hold.ID), []byte(account.ID.String()), ttl); err != nil {
+			h.logger.WarnContext(ctx, "hold lease not stored", "hold_id", hold.ID, "error", err)
+		}
+	}
+
+	metrics.HoldsTotal.WithLabelValues("placed").Inc()
+	h.logger.InfoContext(ctx, "hold placed", "hold_id", hold.ID, "account_id", account.ID, "amount", hold.Amount, "expires_at", hold.ExpiresAt)
+
+	resp, err := newHoldResponse(i18n.T(i18n.FromContext(ctx), i18n.MsgHoldPlaced), hold, account)
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, resp)
+}
+
+// GetHold godoc
+// @Summary Get a hold
+// @Description Get a hold and its account's current and available balance.
+// @Tags Account
+// @Produce json
+// @Param id path string true "Hold ID"
+// @Success 200 {object} dto.HoldResponse "Hold"
+// @Failure 400 {object} apperrors.Problem "Bad Request"
+// @Failure 403 {object} apperrors.Problem "Forbidden"
+// @Failure 404 {object} apperrors.Problem "Hold not found"
+// @Failure 500 {object} apperrors.Problem "Internal Server Error"
+// @Router /account/holds/{id} [get]
+func (h *Handler) GetHold(c *gin.Context) {
+	var params dto.IDParam
+	if !bindURI(c, &params) {
+		return
+	}
+
+	userID, exists := c.Get("userID")
+	if !exists {
+		abortWithError(c, apperrors.New(apperrors.Unauthorized))
+		return
+	}
+
+	userIDUUID, ok := userID.(uuid.UUID)
+	if !ok {
+		abortWithError(c, errors.New("user ID in context is not a UUID"))
+		return
+	}
+
+	var hold models.Hold
+	var account models.Account
+	err := h.db.Atomic(c.Request.Context(), func(tx database.Tx) error {
+		var err error
+		if hold, err = getOwnedHold(tx, params.UUID(), userIDUUID); err != nil {
+			return err
+		}
+		if err := checkCardScope(c, hold.AccountID); err != nil {
+			return err
+		}
+		account, err = getAccount(tx, hold.AccountID)
+		return err
+	})
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+
+	resp, err := newHoldResponse("", hold, account)
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// CaptureHold godoc
+// @Summary Capture a hold
+// @Description Take the final amount of a hold from the account as a withdrawal. The amount may be less than the hold, for example when only part of the cash was dispensed; the rest becomes available again and no longer counts against the withdrawal limits. Without an amount the whole hold is captured. Withdrawal fees are charged on the captured amount, from the available balance. A hold can only be captured once, and not after it has expired.
+// @Tags Account
+// @Accept json
+// @Produce json
+// @Param id path string true "Hold ID"
+// @Param input body dto.CaptureHoldRequest false "Amount to capture"
+// @Success 200 {object} dto.HoldResponse "Hold captured"
+// @Failure 400 {object} apperrors.Problem "Bad Request, amount above the hold or fees not covered"
+// @Failure 403 {object} apperrors.Problem "Forbidden"
+// @Failure 404 {object} apperrors.Problem "Hold not found"
+// @Failure 409 {object} apperrors.Problem "Hold already closed or expired"
+// @Failure 422 {object} apperrors.Problem "Amount cannot be dispensed"
+// @Failure 500 {object} apperrors.Problem "Internal Server Error"
+// @Router /account/holds/{id}/capture [post]
+func (h *Handler) CaptureHold(c *gin.Context) {
+	var params dto.IDParam
+	if !bindURI(c, &params) {
+		return
+	}
+
+	var input dto.CaptureHoldRequest
+	if c.Request.ContentLength != 0 && !bindJSON(c, &input) {
+		return
+	}
+
+	userID, exists := c.Get("userID")
+	if !exists {
+		abortWithError(c, apperrors.New(apperrors.Unauthorized))
+		return
+	}
+
+	userIDUUID, ok := userID.(uuid.UUID)
+	if !ok {
+		abortWithError(c, errors.New("user ID in context is not a UUID"))
+		return
+	}
+
+	ctx := c.Request.Context()
+	foreign := foreignATM(c)
+
+	var hold models.Hold
+	var account models.Account
+	var notes []models.Notes
+	var charged []models.Fee
+	err := h.db.Atomic(ctx, func(tx database.Tx) error {
+		now := time.Now()
+		var err error
+		hold, err = getActiveHold(tx, params.UUID(), userIDUUID, now)
+		if err != nil {
+			return err
+		}
+		if err := checkCardScope(c, hold.AccountID); err != nil {
+			return err
+		}
+		account, err = getAccount(tx, hold.AccountID)
+		if err != nil {
+			return err
+		}
+
+		captured := hold.Amount
+		if input.Amount != nil {
+			captured = *input.Amount
+		}
+		if captured.Currency() != hold.Amount.Currency() {
+			return apperrors.New(apperrors.CurrencyMismatch).WithDetail(i18n.MsgHoldCurrency)
+		}
+		rest, err := hold.Amount.Sub(captured)
+		if err != nil {
+			return moneyError(err)
+		}
+		if rest.IsNegative() {
+			return apperrors.New(apperrors.InvalidAmount).WithDetail(i18n.MsgHoldCaptureExceeds)
+		}
+
+		// The held money was set aside for this, so the capture is posted
+		// even if the balance has since been charged below it.
+		if err := ledger.Unhold(&account, hold.Amount); err != nil {
+			return err
+		}
+		if err := ledger.Charge(tx, &account, captured, now); err != nil {
+			return moneyError(err)
+		}
+		if rest.IsPositive() {
+			if err := h.limits.Reduce(tx, account, rest, hold.CreatedAt); err != nil {
+				return limitError(err)
+			}
+		}
+
+		withdrawal := models.Withdrawal{
+			ID:               uuid.New(),
+			AccountID:        account.ID,
+			WithdrawalAmount: captured,
+			WithdrawalDate:   now,
+			HoldID:           &hold.ID,
+			TerminalID:       terminalID(c),
+		}
+		// Fees were not part of the hold, so they need available money of
+		// their own.
+		charged, err = h.fees.Charge(tx, &account, fees.Operation{
+			Type:          models.FeeWithdrawal,
+			TransactionID: withdrawal.ID,
+			Amount:        captured,
+			Foreign:       foreign,
+			At:            now,
+		})
+		if err != nil {
+			return moneyError(err)
+		}
+		withdrawal.FeeIDs = fees.IDs(charged)
+
+		notes, err = h.dispense(c, tx, captured, now)
+		if err != nil {
+			return err
+		}
+		withdrawal.Notes = notes
+		hold.Status = models.HoldCaptured
+		hold.Captured = &captured
+		hold.WithdrawalID = &withdrawal.ID
+		hold.ClosedAt = &now
+		ledger.RecordActivity(&account, now)
+
+		tx.Delete(holdLeaseKey(hold.ID))
+		if err := recordTerminalCash(tx, withdrawal.TerminalID, models.TerminalCash{
+			TransactionID:   withdrawal.ID,
+			TransactionType: models.ReversibleWithdrawal,
+			Amount:          withdrawal.WithdrawalAmount,
+			At:              withdrawal.WithdrawalDate,
+		}); err != nil {
+			return err
+		}
+		if err := putJSON(tx, accountKey(account.ID), account); err != nil {
+			return err
+		}
+		if err := putJSON(tx, "withdrawals/"+withdrawal.ID.String(), withdrawal); err != nil {
+			return err
+		}
+		return putJSON(tx, holdKey(hold.ID), hold)
+	})
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+
+	metrics.HoldsTotal.WithLabelValues(string(models.HoldCaptured)).Inc()
+	metrics.WithdrawalsTotal.Inc()
+	countDispensed(notes)
+	metrics.AmountMovedTotal.WithLabelValues("withdrawal", string(hold.Captured.Currency())).Add(hold.Captured.Float64())
+	h.logger.InfoContext(ctx, "hold captured", "hold_id", hold.ID, "account_id", account.ID, "amount", hold.Amount, "captured", *hold.Captured, "withdrawal_id", *hold.WithdrawalID)
+
+	resp, err := newHoldResponse(i18n.T(i18n.FromContext(ctx), i18n.MsgHoldCaptured), hold, account)
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+	resp.Notes = dto.NewNotesViews(notes)
+	resp.Fees = dto.NewFeeViews(charged)
+	c.JSON(http.StatusOK, resp)
+}
+
+// ReleaseHold godoc
+// @Summary Release a hold
+// @Description Cancel a hold without taking any money: the held amount becomes available again and no longer counts against the withdrawal limits.
+// @Tags Account
+// @Produce json
+// @Param id path string true "Hold ID"
+// @Success 200 {object} dto.HoldResponse "Hold released"
+// @Failure 400 {object} apperrors.Problem "Bad Request"
+// @Failure 403 {object} apperrors.Problem "Forbidden"
+// @Failure 404 {object} apperrors.Problem "Hold not found"
+// @Failure 409 {object} apperrors.Problem "Hold already closed or expired"
+// @Failure 500 {object} apperrors.Problem "Internal Server Error"
+// @Router /account/holds/{id}/release [post]
+func (h *Handler) ReleaseHold(c *gin.Context) {
+	var params dto.IDParam
+	if !bindURI(c, &params) {
+		return
+	}
+
+	userID, exists := c.Get("userID")
+	if !exists {
+		abortWithError(c, apperrors.New(apperrors.Unauthorized))
+		return
+	}
+
+	userIDUUID, ok := userID.(uuid.UUID)
+	if !ok {
+		abortWithError(c, errors.New("user ID in context is not a UUID"))
+		return
+	}
+
+	ctx := c.Request.Context()
+
+	var hold models.Hold
+	var account models.Account
+	err := h.db.Atomic(ctx, func(tx database.Tx) error {
+		now := time.Now()
+		var err error
+		hold, err = getActiveHold(tx, params.UUID(), userIDUUID, now)
+		if err != nil {
+			return err
+		}
+		if err := checkCardScope(c, hold.AccountID); err != nil {
+			return err
+		}
+		account, err = h.closeHold(tx, &hold, models.HoldReleased, now)
+		return err
+	})
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+
+	metrics.HoldsTotal.WithLabelValues(string(models.HoldReleased)).Inc()
+	h.logger.InfoContext(ctx, "hold released", "hold_id", hold.ID, "account_id", account.ID, "amount", hold.Amount)
+
+	resp, err := newHoldResponse(i18n.T(i18n.FromContext(ctx), i18n.MsgHoldReleased), hold, account)
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// closeHold frees an active hold's money and limit usage without capturing
+// it, and stores the hold and its account.
+func (h *Handler) closeHold(tx database.Tx, hold *models.Hold, status models.HoldStatus, at time.Time) (models.Account, error) {
+	account, err := getAccount(tx, hold.AccountID)
+	if err != nil {
+		return account, err
+	}
+	if err := ledger.Unhold(&account, hold.Amount); err != nil {
+		return account, err
+	}
+	if err := h.limits.Release(tx, account, hold.Amount, hold.CreatedAt); err != nil {
+		return account, limitError(err)
+	}
+	hold.Status = status
+	hold.ClosedAt = &at
+
+	tx.Delete(holdLeaseKey(hold.ID))
+	if err := putJSON(tx, accountKey(account.ID), account); err != nil {
+		return account, err
+	}
+	return account, putJSON(tx, holdKey(hold.ID), *hold)
+}
+
+// WatchHoldLeases expires holds as soon as their leases run out, until ctx
+// is done. Every replica can run it: expiring a hold twice is a no-op.
+func (h *Handler) WatchHoldLeases(ctx context.Context) {
+	if h.leases == nil {
+		return
+	}
+	for ctx.Err() == nil {
+		err := h.leases.WatchDeletes(ctx, holdLeasesPrefix, func(key string) {
+			id, err := uuid.Parse(strings.TrimPrefix(key, holdLeasesPrefix))
+			if err != nil {
+				return
+			}
+			if err := h.expireHold(ctx, id, time.Now()); err != nil {
+				h.logger.ErrorContext(ctx, "hold expiry failed", "hold_id", id, "error", err)
+			}
+		})
+		if err != nil && ctx.Err() == nil {
+			h.logger.Error("watching hold leases failed", "error", err)
+			select {
+			case <-ctx.Done():
+			case <-time.After(watchRetryDelay):
+			}
+		}
+	}
+}
+
+// ExpireHolds expires every active hold past its expiry. Leases normally
+// expire holds on time; this catches holds whose lease could not be stored
+// or ran out while no replica was watching.
+func (h *Handler) ExpireHolds(ctx context.Context) error {
+	data, err := h.db.List(ctx, holdsPrefix)
+	if err != nil {
+		return fmt.Errorf("listing holds: %w", err)
+	}
+
+	now := time.Now()
+	var failed int
+	for key, value := range data {
+		var hold models.Hold
+		if err := json.Unmarshal(value, &hold); err != nil {
+			return fmt.Errorf("unmarshaling hold %s: %w", strings.TrimPrefix(key, holdsPrefix), err)
+		}
+		if hold.Status != models.HoldActive || now.Before(hold.ExpiresAt) {
+			continue
+		}
+		if err := h.expireHold(ctx, hold.ID, now); err != nil {
+			failed++
+			h.logger.ErrorContext(ctx, "hold expiry failed", "hold_id", hold.ID, "error", err)
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("expiring %d holds failed", failed)
+	}
+	return nil
+}
+
+// expireHold expires a hold if it is still active. Its lease key is also
+// deleted when the hold is captured or released, so the watch reports
+// holds that are already closed.
+func (h *Handler) expireHold(ctx context.Context, id uuid.UUID, now time.Time) error {
+	var hold *models.Hold
+	var expired bool
+	err := h.db.Atomic(ctx, func(tx database.Tx) error {
+		expired = false
+		var err error
+		hold, err = getHold(tx, id)
+		if err != nil || hold == nil || hold.Status != models.HoldActive {
+			return err
+		}
+		if _, err := h.closeHold(tx, hold, models.HoldExpired, now); err != nil {
+			return err
+		}
+		expired = true
+		return nil
+	})
+	if err != nil || !expired {
+		return err
+	}
+
+	metrics.HoldsTotal.WithLabelValues(string(models.HoldExpired)).Inc()
+	h.logger.InfoContext(ctx, "hold expired", "hold_id", hold.ID, "account_id", hold.AccountID, "amount", hold.Amount, "expires_at", hold.ExpiresAt)
+	return nil
+}
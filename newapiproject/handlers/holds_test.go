@@ -0,0 +1,201 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"newapiprojet/apperrors"
+	"newapiprojet/database/memdb"
+	"newapiprojet/dto"
+	"newapiprojet/ledger"
+	"newapiprojet/models"
+	"newapiprojet/money"
+
+	"github.com/gin-gonic/gin"
+)
+
+// holdTest is an account with a router for its owner's hold requests.
+type holdTest struct {
+	h       *Handler
+	db      *memdb.DB
+	r       *gin.Engine
+	account models.Account
+}
+
+func newHoldTest(t *testing.T, balance money.Money) holdTest {
+	t.Helper()
+	h, db := newTestHandler(t)
+	account := putAccount(t, db, balance)
+	r := newTestRouter(account.UserID)
+	r.POST("/account/holds", h.PlaceHold)
+	r.POST("/account/holds/:id/capture", h.CaptureHold)
+	r.POST("/account/holds/:id/release", h.ReleaseHold)
+	r.POST("/staff/withdrawals/:id/reversal", h.ReverseWithdrawal)
+	return holdTest{h: h, db: db, r: r, account: account}
+}
+
+// place holds amount, in whole lira, and returns the response.
+func (ht holdTest) place(t *testing.T, amount string) dto.HoldResponse {
+	t.Helper()
+	w := serve(ht.r, http.MethodPost, "/account/holds", `{"accountID":"`+ht.account.ID.String()+`","amount":{"amount":"`+amount+`","currency":"TRY"}}`)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("place hold = %d %s", w.Code, w.Body)
+	}
+	return decodeHoldResponse(t, w.Body.Bytes())
+}
+
+func decodeHoldResponse(t *testing.T, body []byte) dto.HoldResponse {
+	t.Helper()
+	var resp dto.HoldResponse
+	if err := json.Unmarshal(body, &resp); err != nil {
+		t.Fatal(err)
+	}
+	return resp
+}
+
+// checkBalances compares the stored account's balance and available
+// balance.
+func (ht holdTest) checkBalances(t *testing.T, balance, available money.Money) {
+	t.Helper()
+	account := getTestAccount(t, ht.db, ht.account.ID)
+	got, err := ledger.Available(account)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if account.Balance != balance || got != available {
+		t.Errorf("balance %v, available %v, want %v and %v", account.Balance, got, balance, available)
+	}
+}
+
+func TestCaptureHoldChargesFees(t *testing.T) {
+	ht := newHoldTest(t, lira(100))
+	_, err := ht.h.fees.SetSchedule(context.Background(), models.FeeSchedule{
+		Currency: money.TRY,
+		Rules:    []models.FeeRule{{Name: "withdrawal", Operation: models.FeeWithdrawal, Fixed: lira(3)}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	hold := ht.place(t, "50")
+	ht.checkBalances(t, lira(100), lira(50))
+
+	// Only part of the hold is taken; the rest is available again.
+	w := serve(ht.r, http.MethodPost, "/account/holds/"+hold.Hold.ID.String()+"/capture", `{"amount":{"amount":"40","currency":"TRY"}}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("capture = %d %s", w.Code, w.Body)
+	}
+	resp := decodeHoldResponse(t, w.Body.Bytes())
+	if resp.Hold.Status != models.HoldCaptured || resp.Hold.Captured == nil || *resp.Hold.Captured != lira(40) || len(resp.Fees) != 1 {
+		t.Fatalf("capture = %+v, want 40 captured with one fee", resp)
+	}
+	ht.checkBalances(t, lira(57), lira(57))
+
+	var withdrawal models.Withdrawal
+	if !get(t, ht.db, "withdrawals/"+resp.Hold.WithdrawalID.String(), &withdrawal) || len(withdrawal.FeeIDs) != 1 {
+		t.Fatalf("withdrawal = %+v, want it linked to the fee", withdrawal)
+	}
+
+	// Reversing the captured withdrawal refunds the fee too.
+	w = serve(ht.r, http.MethodPost, "/staff/withdrawals/"+withdrawal.ID.String()+"/reversal", `{"reason":"dispense_failure"}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("reversal = %d %s", w.Code, w.Body)
+	}
+	ht.checkBalances(t, lira(100), lira(100))
+
+	w = serve(ht.r, http.MethodPost, "/account/holds/"+hold.Hold.ID.String()+"/capture", "")
+	if w.Code != http.StatusConflict || problemCode(t, w) != apperrors.Conflict {
+		t.Errorf("second capture = %d %s, want a conflict", w.Code, w.Body)
+	}
+}
+
+func TestCaptureHoldFeesNotCovered(t *testing.T) {
+	ht := newHoldTest(t, lira(50))
+	_, err := ht.h.fees.SetSchedule(context.Background(), models.FeeSchedule{
+		Currency: money.TRY,
+		Rules:    []models.FeeRule{{Name: "withdrawal", Operation: models.FeeWithdrawal, Fixed: lira(3)}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	hold := ht.place(t, "50")
+	w := serve(ht.r, http.MethodPost, "/account/holds/"+hold.Hold.ID.String()+"/capture", "")
+	if w.Code != http.StatusBadRequest || problemCode(t, w) != apperrors.InsufficientFunds {
+		t.Fatalf("capture = %d %s, want insufficient funds", w.Code, w.Body)
+	}
+	// Nothing was posted and the hold can still be captured in part.
+	ht.checkBalances(t, lira(50), lira(0))
+	w = serve(ht.r, http.MethodPost, "/account/holds/"+hold.Hold.ID.String()+"/capture", `{"amount":{"amount":"47","currency":"TRY"}}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("partial capture = %d %s", w.Code, w.Body)
+	}
+	ht.checkBalances(t, lira(0), lira(0))
+}
+
+func TestReleaseHold(t *testing.T) {
+	ht := newHoldTest(t, lira(100))
+	hold := ht.place(t, "80")
+	if w := serve(ht.r, http.MethodPost, "/account/holds", `{"accountID":"`+ht.account.ID.String()+`","amount":{"amount":"30","currency":"TRY"}}`); w.Code != http.StatusBadRequest || problemCode(t, w) != apperrors.InsufficientFunds {
+		t.Errorf("hold beyond the available balance = %d %s", w.Code, w.Body)
+	}
+
+	w := serve(ht.r, http.MethodPost, "/account/holds/"+hold.Hold.ID.String()+"/release", "")
+	if w.Code != http.StatusOK {
+		t.Fatalf("release = %d %s", w.Code, w.Body)
+	}
+	if resp := decodeHoldResponse(t, w.Body.Bytes()); resp.Hold.Status != models.HoldReleased {
+		t.Errorf("status = %s, want %s", resp.Hold.Status, models.HoldReleased)
+	}
+	ht.checkBalances(t, lira(100), lira(100))
+
+	for _, action := range []string{"release", "capture"} {
+		w := serve(ht.r, http.MethodPost, "/account/holds/"+hold.Hold.ID.String()+"/"+action, "")
+		if w.Code != http.StatusConflict {
+			t.Errorf("%s after release = %d %s, want a conflict", action, w.Code, w.Body)
+		}
+	}
+}
+
+func TestExpireHolds(t *testing.T) {
+	ht := newHoldTest(t, lira(100))
+	expired := ht.place(t, "30")
+	active := ht.place(t, "20")
+	ht.checkBalances(t, lira(100), lira(50))
+
+	var hold models.Hold
+	get(t, ht.db, holdKey(expired.Hold.ID), &hold)
+	hold.ExpiresAt = time.Now().Add(-time.Second)
+	put(t, ht.db, holdKey(hold.ID), hold)
+
+	// A hold past its expiry cannot be captured, even before it is expired.
+	w := serve(ht.r, http.MethodPost, "/account/holds/"+hold.ID.String()+"/capture", "")
+	if w.Code != http.StatusConflict {
+		t.Errorf("capture of an expired hold = %d %s, want a conflict", w.Code, w.Body)
+	}
+
+	if err := ht.h.ExpireHolds(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	ht.checkBalances(t, lira(100), lira(80))
+	get(t, ht.db, holdKey(expired.Hold.ID), &hold)
+	if hold.Status != models.HoldExpired || hold.ClosedAt == nil {
+		t.Errorf("expired hold = %+v", hold)
+	}
+	get(t, ht.db, holdKey(active.Hold.ID), &hold)
+	if hold.Status != models.HoldActive {
+		t.Errorf("active hold status = %s", hold.Status)
+	}
+
+	// Expiring again, as when a lease runs out after the job, changes nothing.
+	if err := ht.h.expireHold(context.Background(), expired.Hold.ID, time.Now()); err != nil {
+		t.Fatal(err)
+	}
+	ht.checkBalances(t, lira(100), lira(80))
+	if data, _ := ht.db.Get(context.Background(), holdLeaseKey(expired.Hold.ID)); data != nil {
+		t.Error("lease key of the expired hold still stored")
+	}
+}
@@ -0,0 +1,115 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"newapiprojet/apperrors"
+	"newapiprojet/database"
+	"newapiprojet/dto"
+	"newapiprojet/limits"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// GetWithdrawalLimits godoc
+// @Summary Get withdrawal limits
+// @Description Show the account's withdrawal limits and how much of them is left for the current business day
+// @Tags Account
+// @Produce json
+// @Param accountID path string true "Account ID"
+// @Success 200 {object} dto.WithdrawalLimitsResponse "Withdrawal limits"
+// @Failure 400 {object} apperrors.Problem "Bad Request"
+// @Failure 403 {object} apperrors.Problem "Forbidden"
+// @Failure 404 {object} apperrors.Problem "Account not found"
+// @Failure 500 {object} apperrors.Problem "Internal Server Error"
+// @Router /account/limits/{accountID} [get]
+func (h *Handler) GetWithdrawalLimits(c *gin.Context) {
+	var params dto.AccountIDParam
+	if !bindURI(c, &params) {
+		return
+	}
+
+	userID, exists := c.Get("userID")
+	if !exists {
+		abortWithError(c, apperrors.New(apperrors.Unauthorized))
+		return
+	}
+
+	userIDUUID, ok := userID.(uuid.UUID)
+	if !ok {
+		abortWithError(c, errors.New("user ID in context is not a UUID"))
+		return
+	}
+
+	if err := checkCardScope(c, params.UUID()); err != nil {
+		abortWithError(c, err)
+		return
+	}
+
+	ctx := c.Request.Context()
+
+	var remaining limits.Remaining
+	err := h.db.Atomic(ctx, func(tx database.Tx) error {
+		account, err := getOwnedAccount(tx, params.UUID(), userIDUUID)
+		if err != nil {
+			return err
+		}
+		remaining, err = h.limits.Remaining(tx, account)
+		return err
+	})
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.NewWithdrawalLimitsResponse(params.UUID(), remaining))
+}
+
+// SetWithdrawalLimits godoc
+// @Summary Set withdrawal limits
+// @Description Replace an account's withdrawal limits. Amounts are in the account currency and zero disables a limit. Staff only.
+// @Tags Staff
+// @Accept json
+// @Produce json
+// @Param id path string true "Account ID"
+// @Param input body dto.WithdrawalLimitsRequest true "Withdrawal limits"
+// @Success 200 {object} dto.WithdrawalLimitsResponse "Withdrawal limits saved"
+// @Failure 400 {object} apperrors.Problem "Bad Request"
+// @Failure 403 {object} apperrors.Problem "Forbidden"
+// @Failure 404 {object} apperrors.Problem "Account not found"
+// @Failure 500 {object} apperrors.Problem "Internal Server Error"
+// @Router /staff/accounts/{id}/limits [put]
+func (h *Handler) SetWithdrawalLimits(c *gin.Context) {
+	var params dto.IDParam
+	if !bindURI(c, &params) {
+		return
+	}
+
+	var input dto.WithdrawalLimitsRequest
+	if !bindJSON(c, &input) {
+		return
+	}
+
+	ctx := c.Request.Context()
+
+	var remaining limits.Remaining
+	err := h.db.Atomic(ctx, func(tx database.Tx) error {
+		account, err := getAccount(tx, params.UUID())
+		if err != nil {
+			return err
+		}
+		if err := h.limits.Set(tx, account, input.ToWithdrawalLimits()); err != nil {
+			return limitError(err)
+		}
+		remaining, err = h.limits.Remaining(tx, account)
+		return err
+	})
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+
+	h.logger.InfoContext(ctx, "withdrawal limits saved", "account_id", params.UUID(), "limits", remaining.Limits)
+	c.JSON(http.StatusOK, dto.NewWithdrawalLimitsResponse(params.UUID(), remaining))
+}
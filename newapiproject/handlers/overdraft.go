@@ -0,0 +1,71 @@
+package handlers
+
+import (
+	"net/http"
+	"newapiprojet/apperrors"
+	"newapiprojet/database"
+	"newapiprojet/dto"
+	"newapiprojet/i18n"
+	"newapiprojet/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SetOverdraft godoc
+// @Summary Set an account's overdraft
+// @Description Grant, change or remove an account's overdraft. The limit is in the account currency and a zero limit removes the facility. Staff only.
+// @Tags Staff
+// @Accept json
+// @Produce json
+// @Param id path string true "Account ID"
+// @Param input body dto.OverdraftRequest true "Overdraft"
+// @Success 200 {object} dto.AccountResponse "Overdraft saved"
+// @Failure 400 {object} apperrors.Problem "Bad Request"
+// @Failure 403 {object} apperrors.Problem "Forbidden"
+// @Failure 404 {object} apperrors.Problem "Account not found"
+// @Failure 500 {object} apperrors.Problem "Internal Server Error"
+// @Router /staff/accounts/{id}/overdraft [put]
+func (h *Handler) SetOverdraft(c *gin.Context) {
+	var params dto.IDParam
+	if !bindURI(c, &params) {
+		return
+	}
+
+	var input dto.OverdraftRequest
+	if !bindJSON(c, &input) {
+		return
+	}
+
+	if input.Limit.IsNegative() {
+		abortWithError(c, apperrors.New(apperrors.InvalidAmount).WithDetail(i18n.MsgLimitNegative))
+		return
+	}
+
+	ctx := c.Request.Context()
+
+	var account models.Account
+	err := h.db.Atomic(ctx, func(tx database.Tx) error {
+		var err error
+		account, err = getAccount(tx, params.UUID())
+		if err != nil {
+			return err
+		}
+
+		if input.Limit.IsZero() {
+			account.Overdraft = nil
+		} else {
+			if input.Limit.Currency() != account.Balance.Currency() {
+				return apperrors.New(apperrors.CurrencyMismatch)
+			}
+			account.Overdraft = &models.Overdraft{Limit: input.Limit, RateBps: input.RateBps}
+		}
+		return putJSON(tx, accountKey(account.ID), account)
+	})
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+
+	h.logger.InfoContext(ctx, "overdraft saved", "account_id", account.ID, "overdraft", account.Overdraft)
+	c.JSON(http.StatusOK, dto.AccountResponse{Account: dto.NewAccountView(account)})
+}
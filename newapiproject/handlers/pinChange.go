@@ -1,13 +1,14 @@
 package handlers
 
 import (
-	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
+	"newapiprojet/apperrors"
+	"newapiprojet/dto"
+	"newapiprojet/i18n"
 	"newapiprojet/models"
-	"regexp"
-	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
@@ -20,88 +21,79 @@ import (
 // @Accept json
 // @Produce json
 // @Param id path string true "User ID"
-// @Param input body struct{OldPIN string `json:"oldPIN";NewPIN string `json:"newPIN"`} true "Old and New PIN"
-// @Success 200 {string} string "PIN updated successfully"
-// @Failure 400 {string} string "Bad Request"
-// @Failure 404 {string} string "User not found"
-// @Failure 403 {string} string "Forbidden"
-// @Failure 500 {string} string "Internal Server Error"
-// @Router /pin-change/{id} [post]
+// @Param input body dto.PinChangeRequest true "Old and New PIN"
+// @Success 200 {object} dto.MessageResponse "PIN updated successfully"
+// @Failure 400 {object} apperrors.Problem "Bad Request"
+// @Failure 404 {object} apperrors.Problem "User not found"
+// @Failure 403 {object} apperrors.Problem "Forbidden"
+// @Failure 500 {object} apperrors.Problem "Internal Server Error"
+// @Router /account/pin-change/{id} [post]
 func (h *Handler) PinChange(c *gin.Context) {
-	var input struct {
-		OldPIN string `json:"oldPIN"`
-		NewPIN string `json:"newPIN"`
+	var input dto.PinChangeRequest
+	var params dto.IDParam
+	if !bindURI(c, &params) {
+		return
 	}
-	idParam := c.Param("id")
+	ctx := c.Request.Context()
 
 	userID, exists := c.Get("userID")
 	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Yetkilendirme hatası"})
+		abortWithError(c, apperrors.New(apperrors.Unauthorized))
 		return
 	}
 
 	userIDUUID, ok := userID.(uuid.UUID)
 	if !ok {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "User ID dönüştürme hatası"})
+		abortWithError(c, errors.New("user ID in context is not a UUID"))
 		return
 	}
 	userIDString := userIDUUID.String()
 
-	fmt.Printf("idParam: %s, userIDString: %s\n", idParam, userIDString)
+	h.logger.DebugContext(ctx, "pin change requested", "path_user_id", params.ID, "user_id", userIDString)
 
-	if idParam != userIDString {
-		c.JSON(http.StatusForbidden, gin.H{"error": "Bu hesaba erişim izniniz yok"})
+	if params.UUID() != userIDUUID {
+		abortWithError(c, apperrors.New(apperrors.Forbidden).WithDetail(i18n.MsgAccountAccessDenied))
 		return
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	defer cancel()
-
 	data, err := h.db.Get(ctx, "users/"+userIDString)
-	if err != nil || data == nil {
-		fmt.Println("User not found or error retrieving user data:", err)
-		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+	if err != nil {
+		abortWithError(c, fmt.Errorf("retrieving user data: %w", err))
+		return
+	}
+	if data == nil {
+		abortWithError(c, apperrors.New(apperrors.UserNotFound))
 		return
 	}
 
 	var user models.User
 	err = json.Unmarshal(data, &user)
 	if err != nil {
-		fmt.Println("Error unmarshaling user data:", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Unable to unmarshal user data: " + err.Error()})
-		return
-	}
-
-	if err := c.BindJSON(&input); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		abortWithError(c, fmt.Errorf("unmarshaling user data: %w", err))
 		return
 	}
 
-	matchPin, _ := regexp.MatchString(`^\d{4}$`, input.NewPIN)
-	if !matchPin {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "PIN must be exactly 4 digits"})
+	if !bindJSON(c, &input) {
 		return
 	}
 
 	if input.OldPIN != user.PIN {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "OldPIN does not match the current PIN"})
+		abortWithError(c, apperrors.New(apperrors.PINMismatch))
 		return
 	}
 
 	user.PIN = input.NewPIN
 	userData, err := json.Marshal(user)
 	if err != nil {
-		fmt.Println("Error marshaling user data:", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Unable to marshal user data: " + err.Error()})
+		abortWithError(c, fmt.Errorf("marshaling user data: %w", err))
 		return
 	}
 
 	err = h.db.Put(ctx, "users/"+userIDString, userData)
 	if err != nil {
-		fmt.Println("Error updating user data in etcd:", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Unable to update user data in etcd: " + err.Error()})
+		abortWithError(c, fmt.Errorf("updating user data in etcd: %w", err))
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "PIN updated successfully"})
+	c.JSON(http.StatusOK, dto.MessageResponse{Message: i18n.T(i18n.FromContext(ctx), i18n.MsgPINUpdated)})
 }
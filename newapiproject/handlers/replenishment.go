@@ -0,0 +1,482 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"newapiprojet/apperrors"
+	"newapiprojet/database"
+	"newapiprojet/dto"
+	"newapiprojet/metrics"
+	"newapiprojet/models"
+	"newapiprojet/money"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// Loads, unloads, reconciliations and the withdrawals and deposits made at
+// each terminal are kept per terminal, keyed by time:
+//
+//	cash_movements/<terminalID>/<unix nanos, zero-padded>        models.CashMovement
+//	reconciliations/<terminalID>/<unix nanos, zero-padded>       models.Reconciliation
+//	terminal_tx/<terminalID>/<unix nanos, zero-padded>/<txID>    models.TerminalCash
+//
+// The key of each terminal's latest reconciliation is kept under
+//
+//	last_reconciliations/<terminalID>
+const (
+	cashMovementsPrefix       = "cash_movements/"
+	reconciliationsPrefix     = "reconciliations/"
+	terminalTxPrefix          = "terminal_tx/"
+	lastReconciliationsPrefix = "last_reconciliations/"
+)
+
+// maxReconciliations is how many reports GET
+// /staff/terminals/{id}/reconciliations returns.
+const maxReconciliations = 30
+
+func timeKey(prefix string, terminalID uuid.UUID, at time.Time) string {
+	return fmt.Sprintf("%s%s/%020d", prefix, terminalID, at.UnixNano())
+}
+
+func terminalTxKey(terminalID uuid.UUID, at time.Time, transactionID uuid.UUID) string {
+	return timeKey(terminalTxPrefix, terminalID, at) + "/" + transactionID.String()
+}
+
+// rangeByTime returns the keys under prefix for terminalID timed after from
+// and up to to.
+func (h *Handler) rangeByTime(ctx context.Context, prefix string, terminalID uuid.UUID, from, to time.Time) (map[string][]byte, error) {
+	return h.db.Range(ctx, timeKey(prefix, terminalID, from.Add(time.Nanosecond)), timeKey(prefix, terminalID, to.Add(time.Nanosecond)))
+}
+
+// recordTerminalCash indexes, as part of tx, a withdrawal or deposit made at
+// terminalID. Transactions not made at a terminal are not indexed.
+func recordTerminalCash(tx database.Tx, terminalID *uuid.UUID, cash models.TerminalCash) error {
+	if terminalID == nil {
+		return nil
+	}
+	return putJSON(tx, terminalTxKey(*terminalID, cash.At, cash.TransactionID), cash)
+}
+
+// markDispenseFailed flags, as part of tx, a terminal's withdrawal as not
+// paid out.
+func markDispenseFailed(tx database.Tx, terminalID uuid.UUID, withdrawal models.Withdrawal) error {
+	key := terminalTxKey(terminalID, withdrawal.WithdrawalDate, withdrawal.ID)
+	cash := models.TerminalCash{
+		TransactionID:   withdrawal.ID,
+		TransactionType: models.ReversibleWithdrawal,
+		Amount:          withdrawal.WithdrawalAmount,
+		At:              withdrawal.WithdrawalDate,
+	}
+	if data := tx.Get(key); data != nil {
+		if err := json.Unmarshal(data, &cash); err != nil {
+			return fmt.Errorf("unmarshaling terminal transaction: %w", err)
+		}
+	}
+	cash.DispenseFailed = true
+	return putJSON(tx, key, cash)
+}
+
+// LoadCash godoc
+// @Summary Record a cassette load
+// @Description Record notes put into a terminal's cassettes. They are added to its cash inventory. Staff only.
+// @Tags Staff
+// @Accept json
+// @Produce json
+// @Param id path string true "Terminal ID"
+// @Param input body dto.CashMovementRequest true "Notes loaded"
+// @Success 201 {object} dto.CashMovementResponse "Load recorded"
+// @Failure 400 {object} apperrors.Problem "Bad Request"
+// @Failure 403 {object} apperrors.Problem "Forbidden"
+// @Failure 404 {object} apperrors.Problem "Terminal not found"
+// @Failure 500 {object} apperrors.Problem "Internal Server Error"
+// @Router /staff/terminals/{id}/loads [post]
+func (h *Handler) LoadCash(c *gin.Context) {
+	h.recordCashMovement(c, models.CashLoad)
+}
+
+// UnloadCash godoc
+// @Summary Record a cassette unload
+// @Description Record notes taken out of a terminal's cassettes. They are removed from its cash inventory; a denomination never drops below zero, and any difference shows up in the next reconciliation. Staff only.
+// @Tags Staff
+// @Accept json
+// @Produce json
+// @Param id path string true "Terminal ID"
+// @Param input body dto.CashMovementRequest true "Notes unloaded"
+// @Success 201 {object} dto.CashMovementResponse "Unload recorded"
+// @Failure 400 {object} apperrors.Problem "Bad Request"
+// @Failure 403 {object} apperrors.Problem "Forbidden"
+// @Failure 404 {object} apperrors.Problem "Terminal not found"
+// @Failure 500 {object} apperrors.Problem "Internal Server Error"
+// @Router /staff/terminals/{id}/unloads [post]
+func (h *Handler) UnloadCash(c *gin.Context) {
+	h.recordCashMovement(c, models.CashUnload)
+}
+
+func (h *Handler) recordCashMovement(c *gin.Context, kind models.CashMovementType) {
+	var params dto.IDParam
+	if !bindURI(c, &params) {
+		return
+	}
+
+	var input dto.CashMovementRequest
+	if !bindJSON(c, &input) {
+		return
+	}
+
+	userID, exists := c.Get("userID")
+	if !exists {
+		abortWithError(c, apperrors.New(apperrors.Unauthorized))
+		return
+	}
+
+	userIDUUID, ok := userID.(uuid.UUID)
+	if !ok {
+		abortWithError(c, errors.New("user ID in context is not a UUID"))
+		return
+	}
+
+	movement := models.CashMovement{
+		ID:         uuid.New(),
+		TerminalID: params.UUID(),
+		Type:       kind,
+		Notes:      input.ToNotes(),
+		RecordedBy: userIDUUID,
+		RecordedAt: time.Now().UTC(),
+	}
+	if err := checkDenominations(movement.Notes); err != nil {
+		abortWithError(c, err)
+		return
+	}
+
+	ctx := c.Request.Context()
+
+	var inventory models.CashInventory
+	err := h.db.Atomic(ctx, func(tx database.Tx) error {
+		if _, err := getTerminal(tx, movement.TerminalID); err != nil {
+			return err
+		}
+		var err error
+		inventory, err = getInventory(tx, movement.TerminalID)
+		if err != nil {
+			return err
+		}
+		for _, n := range movement.Notes {
+			i := slices.IndexFunc(inventory.Cassettes, func(c models.Notes) bool { return c.Denomination == n.Denomination })
+			if i < 0 {
+				if kind == models.CashUnload {
+					continue
+				}
+				inventory.Cassettes = append(inventory.Cassettes, models.Notes{Denomination: n.Denomination})
+				i = len(inventory.Cassettes) - 1
+			}
+			if kind == models.CashLoad {
+				inventory.Cassettes[i].Count += n.Count
+			} else {
+				inventory.Cassettes[i].Count = max(inventory.Cassettes[i].Count-n.Count, 0)
+			}
+		}
+		inventory.UpdatedAt = movement.RecordedAt
+
+		if err := putJSON(tx, cassettesKey(movement.TerminalID), inventory); err != nil {
+			return err
+		}
+		return putJSON(tx, timeKey(cashMovementsPrefix, movement.TerminalID, movement.RecordedAt), movement)
+	})
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+
+	h.logger.InfoContext(ctx, "terminal cash "+string(kind)+" recorded", "terminal_id", movement.TerminalID, "movement_id", movement.ID, "notes", movement.Notes, "recorded_by", userIDUUID)
+	c.JSON(http.StatusCreated, dto.CashMovementResponse{
+		ID:         movement.ID,
+		Type:       movement.Type,
+		Notes:      dto.NewNotesViews(movement.Notes),
+		RecordedAt: movement.RecordedAt,
+		Cassettes:  dto.NewCassettesResponse(inventory).Cassettes,
+	})
+}
+
+// ReconcileTerminal godoc
+// @Summary Reconcile an ATM's cash
+// @Description Compare the cash counted in a terminal with what it should hold since its previous reconciliation: the cash counted then, plus loads and accepted deposits, minus unloads and dispensed withdrawals. Withdrawals reversed because the ATM did not pay out are not counted as dispensed. Run at the end of each day; the report is stored and any difference is flagged. Staff only.
+// @Tags Staff
+// @Accept json
+// @Produce json
+// @Param id path string true "Terminal ID"
+// @Param input body dto.ReconcileRequest true "Notes counted"
+// @Success 201 {object} dto.ReconciliationResponse "Reconciliation report"
+// @Failure 400 {object} apperrors.Problem "Bad Request"
+// @Failure 403 {object} apperrors.Problem "Forbidden"
+// @Failure 404 {object} apperrors.Problem "Terminal not found"
+// @Failure 500 {object} apperrors.Problem "Internal Server Error"
+// @Router /staff/terminals/{id}/reconciliations [post]
+func (h *Handler) ReconcileTerminal(c *gin.Context) {
+	var params dto.IDParam
+	if !bindURI(c, &params) {
+		return
+	}
+
+	var input dto.ReconcileRequest
+	if !bindJSON(c, &input) {
+		return
+	}
+
+	userID, exists := c.Get("userID")
+	if !exists {
+		abortWithError(c, apperrors.New(apperrors.Unauthorized))
+		return
+	}
+
+	userIDUUID, ok := userID.(uuid.UUID)
+	if !ok {
+		abortWithError(c, errors.New("user ID in context is not a UUID"))
+		return
+	}
+
+	counted := input.ToNotes()
+	if err := checkDenominations(counted); err != nil {
+		abortWithError(c, err)
+		return
+	}
+
+	ctx := c.Request.Context()
+
+	// The previous report is read and the new one written in one
+	// transaction, so two counts posted together cannot both open from the
+	// same report: the second is re-run and opens from the first.
+	var report models.Reconciliation
+	err := h.db.Atomic(ctx, func(tx database.Tx) error {
+		terminal, err := getTerminal(tx, params.UUID())
+		if err != nil {
+			return err
+		}
+		previous, err := h.previousReconciliation(ctx, tx, terminal.ID)
+		if err != nil {
+			return err
+		}
+
+		now := time.Now().UTC()
+		report = models.Reconciliation{
+			ID:           uuid.New(),
+			TerminalID:   terminal.ID,
+			BusinessDay:  h.cal.Day(now),
+			From:         terminal.RegisteredAt,
+			To:           now,
+			Counted:      counted,
+			ReconciledBy: userIDUUID,
+		}
+		if report.Lines, err = h.reconcile(ctx, &report, previous); err != nil {
+			return err
+		}
+		for _, line := range report.Lines {
+			if !line.Discrepancy.IsZero() {
+				report.Discrepancy = true
+			}
+		}
+
+		key := timeKey(reconciliationsPrefix, terminal.ID, report.To)
+		tx.Put(lastReconciliationKey(terminal.ID), []byte(key))
+		return putJSON(tx, key, report)
+	})
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+
+	if report.Discrepancy {
+		metrics.ReconciliationDiscrepanciesTotal.Inc()
+		h.logger.WarnContext(ctx, "terminal cash discrepancy", "terminal_id", report.TerminalID, "reconciliation_id", report.ID, "lines", report.Lines)
+	} else {
+		h.logger.InfoContext(ctx, "terminal cash reconciled", "terminal_id", report.TerminalID, "reconciliation_id", report.ID)
+	}
+	c.JSON(http.StatusCreated, dto.ReconciliationResponse{Reconciliation: report})
+}
+
+// reconcile totals, by currency, the terminal's cash movements,
+// withdrawals and deposits after the previous reconciliation, which also
+// gives the opening cash and moves report.From to its end.
+func (h *Handler) reconcile(ctx context.Context, report *models.Reconciliation, previous *models.Reconciliation) ([]models.ReconciliationLine, error) {
+	type totals struct{ opening, loaded, unloaded, dispensed, deposited, counted int64 }
+	byCurrency := map[money.Currency]*totals{}
+	add := func(amount money.Money, field func(*totals) *int64) {
+		t, ok := byCurrency[amount.Currency()]
+		if !ok {
+			t = &totals{}
+			byCurrency[amount.Currency()] = t
+		}
+		*field(t) += amount.Minor()
+	}
+	addNotes := func(notes []models.Notes, field func(*totals) *int64) {
+		for _, n := range notes {
+			add(money.New(n.Denomination.Minor()*int64(n.Count), n.Denomination.Currency()), field)
+		}
+	}
+	if previous != nil {
+		report.From = previous.To
+		addNotes(previous.Counted, func(t *totals) *int64 { return &t.opening })
+	}
+	addNotes(report.Counted, func(t *totals) *int64 { return &t.counted })
+
+	movements, err := h.rangeByTime(ctx, cashMovementsPrefix, report.TerminalID, report.From, report.To)
+	if err != nil {
+		return nil, fmt.Errorf("listing cash movements: %w", err)
+	}
+	for key, data := range movements {
+		var movement models.CashMovement
+		if err := json.Unmarshal(data, &movement); err != nil {
+			return nil, fmt.Errorf("unmarshaling cash movement %s: %w", key, err)
+		}
+		if movement.Type == models.CashLoad {
+			addNotes(movement.Notes, func(t *totals) *int64 { return &t.loaded })
+		} else {
+			addNotes(movement.Notes, func(t *totals) *int64 { return &t.unloaded })
+		}
+	}
+
+	transactions, err := h.rangeByTime(ctx, terminalTxPrefix, report.TerminalID, report.From, report.To)
+	if err != nil {
+		return nil, fmt.Errorf("listing terminal transactions: %w", err)
+	}
+	for key, data := range transactions {
+		var cash models.TerminalCash
+		if err := json.Unmarshal(data, &cash); err != nil {
+			return nil, fmt.Errorf("unmarshaling terminal transaction %s: %w", key, err)
+		}
+		switch {
+		case cash.TransactionType == models.ReversibleDeposit:
+			add(cash.Amount, func(t *totals) *int64 { return &t.deposited })
+		case !cash.DispenseFailed:
+			add(cash.Amount, func(t *totals) *int64 { return &t.dispensed })
+		}
+	}
+
+	currencies := make([]string, 0, len(byCurrency))
+	for currency := range byCurrency {
+		currencies = append(currencies, string(currency))
+	}
+	sort.Strings(currencies)
+
+	lines := make([]models.ReconciliationLine, 0, len(currencies))
+	for _, code := range currencies {
+		currency := money.Currency(code)
+		t := byCurrency[currency]
+		expected := t.opening + t.loaded - t.unloaded - t.dispensed + t.deposited
+		lines = append(lines, models.ReconciliationLine{
+			Currency:    currency,
+			Opening:     money.New(t.opening, currency),
+			Loaded:      money.New(t.loaded, currency),
+			Unloaded:    money.New(t.unloaded, currency),
+			Dispensed:   money.New(t.dispensed, currency),
+			Deposited:   money.New(t.deposited, currency),
+			Expected:    money.New(expected, currency),
+			Counted:     money.New(t.counted, currency),
+			Discrepancy: money.New(t.counted-expected, currency),
+		})
+	}
+	return lines, nil
+}
+
+// reconciliations returns a terminal's stored reconciliations, newest first.
+func (h *Handler) reconciliations(ctx context.Context, terminalID uuid.UUID, limit int) ([]models.Reconciliation, error) {
+	prefix := reconciliationsPrefix + terminalID.String() + "/"
+	data, err := h.db.List(ctx, prefix)
+	if err != nil {
+		return nil, fmt.Errorf("listing reconciliations: %w", err)
+	}
+
+	// Keys end in the zero-padded time, so they sort by age.
+	keys := make([]string, 0, len(data))
+	for key := range data {
+		keys = append(keys, key)
+	}
+	sort.Sort(sort.Reverse(sort.StringSlice(keys)))
+	if len(keys) > limit {
+		keys = keys[:limit]
+	}
+
+	reports := make([]models.Reconciliation, 0, len(keys))
+	for _, key := range keys {
+		var report models.Reconciliation
+		if err := json.Unmarshal(data[key], &report); err != nil {
+			return nil, fmt.Errorf("unmarshaling reconciliation %s: %w", strings.TrimPrefix(key, prefix), err)
+		}
+		reports = append(reports, report)
+	}
+	return reports, nil
+}
+
+func lastReconciliationKey(terminalID uuid.UUID) string {
+	return lastReconciliationsPrefix + terminalID.String()
+}
+
+// previousReconciliation reads, as part of tx, the terminal's latest
+// reconciliation, or returns nil if it has none.
+func (h *Handler) previousReconciliation(ctx context.Context, tx database.Tx, terminalID uuid.UUID) (*models.Reconciliation, error) {
+	key := tx.Get(lastReconciliationKey(terminalID))
+	if key == nil {
+		// Reports stored before last_reconciliations/ was kept.
+		return h.latestReconciliation(ctx, terminalID)
+	}
+	data := tx.Get(string(key))
+	if data == nil {
+		return nil, fmt.Errorf("reconciliation %s not found", key)
+	}
+	var report models.Reconciliation
+	if err := json.Unmarshal(data, &report); err != nil {
+		return nil, fmt.Errorf("unmarshaling reconciliation: %w", err)
+	}
+	return &report, nil
+}
+
+// latestReconciliation returns the terminal's newest stored reconciliation,
+// or nil if it has none.
+func (h *Handler) latestReconciliation(ctx context.Context, terminalID uuid.UUID) (*models.Reconciliation, error) {
+	prefix := reconciliationsPrefix + terminalID.String() + "/"
+	// '0' is the byte after '/', so the range ends after the prefix.
+	_, data, err := h.db.Latest(ctx, prefix, strings.TrimSuffix(prefix, "/")+"0")
+	if err != nil {
+		return nil, fmt.Errorf("reading latest reconciliation: %w", err)
+	}
+	if data == nil {
+		return nil, nil
+	}
+	var report models.Reconciliation
+	if err := json.Unmarshal(data, &report); err != nil {
+		return nil, fmt.Errorf("unmarshaling reconciliation: %w", err)
+	}
+	return &report, nil
+}
+
+// TerminalReconciliations godoc
+// @Summary List an ATM's reconciliations
+// @Description List a terminal's cash reconciliation reports, newest first, up to 30. Staff only.
+// @Tags Staff
+// @Produce json
+// @Param id path string true "Terminal ID"
+// @Success 200 {object} dto.ReconciliationsResponse "Reconciliation reports"
+// @Failure 400 {object} apperrors.Problem "Bad Request"
+// @Failure 403 {object} apperrors.Problem "Forbidden"
+// @Failure 500 {object} apperrors.Problem "Internal Server Error"
+// @Router /staff/terminals/{id}/reconciliations [get]
+func (h *Handler) TerminalReconciliations(c *gin.Context) {
+	var params dto.IDParam
+	if !bindURI(c, &params) {
+		return
+	}
+
+	reports, err := h.reconciliations(c.Request.Context(), params.UUID(), maxReconciliations)
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, dto.ReconciliationsResponse{Reconciliations: reports})
+}
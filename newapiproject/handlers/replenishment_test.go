@@ -0,0 +1,144 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"slices"
+	"testing"
+	"time"
+
+	"newapiprojet/database/memdb"
+	"newapiprojet/models"
+	"newapiprojet/money"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+func newReconcileTest(t *testing.T) (*memdb.DB, *gin.Engine, models.Terminal) {
+	t.Helper()
+	h, db := newTestHandler(t)
+	terminal := models.Terminal{ID: uuid.New(), Status: models.TerminalActive, RegisteredAt: time.Now().UTC().Add(-time.Hour)}
+	put(t, db, terminalKey(terminal.ID), terminal)
+
+	r := newTestRouter(uuid.New())
+	r.POST("/staff/terminals/:id/loads", h.LoadCash)
+	r.POST("/staff/terminals/:id/unloads", h.UnloadCash)
+	r.POST("/staff/terminals/:id/reconciliations", h.ReconcileTerminal)
+	r.GET("/staff/terminals/:id/reconciliations", h.TerminalReconciliations)
+	return db, r, terminal
+}
+
+// putTerminalCash indexes a withdrawal or deposit made at terminal at at.
+func putTerminalCash(t *testing.T, db *memdb.DB, terminal models.Terminal, kind models.ReversibleType, amount money.Money, at time.Time, dispenseFailed bool) {
+	t.Helper()
+	cash := models.TerminalCash{TransactionID: uuid.New(), TransactionType: kind, Amount: amount, At: at, DispenseFailed: dispenseFailed}
+	put(t, db, terminalTxKey(terminal.ID, at, cash.TransactionID), cash)
+}
+
+func reconcileTerminal(t *testing.T, r *gin.Engine, terminal models.Terminal, counted string) models.Reconciliation {
+	t.Helper()
+	w := serve(r, http.MethodPost, "/staff/terminals/"+terminal.ID.String()+"/reconciliations", `{"counted":`+counted+`}`)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("reconcile = %d %s", w.Code, w.Body)
+	}
+	var resp struct {
+		Reconciliation models.Reconciliation `json:"reconciliation"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	return resp.Reconciliation
+}
+
+// line builds a reconciliation line from whole units.
+func line(currency money.Currency, opening, loaded, unloaded, dispensed, deposited, counted int64) models.ReconciliationLine {
+	m := func(major int64) money.Money { return money.New(major*100, currency) }
+	expected := opening + loaded - unloaded - dispensed + deposited
+	return models.ReconciliationLine{
+		Currency:    currency,
+		Opening:     m(opening),
+		Loaded:      m(loaded),
+		Unloaded:    m(unloaded),
+		Dispensed:   m(dispensed),
+		Deposited:   m(deposited),
+		Expected:    m(expected),
+		Counted:     m(counted),
+		Discrepancy: m(counted - expected),
+	}
+}
+
+func TestReconcileTerminal(t *testing.T) {
+	db, r, terminal := newReconcileTest(t)
+	path := "/staff/terminals/" + terminal.ID.String()
+
+	if w := serve(r, http.MethodPost, path+"/loads", `{"notes":[{"denomination":{"amount":"200","currency":"TRY"},"count":10},{"denomination":{"amount":"50","currency":"TRY"},"count":20}]}`); w.Code != http.StatusCreated {
+		t.Fatalf("load = %d %s", w.Code, w.Body)
+	}
+	if w := serve(r, http.MethodPost, path+"/unloads", `{"notes":[{"denomination":{"amount":"50","currency":"TRY"},"count":4}]}`); w.Code != http.StatusCreated {
+		t.Fatalf("unload = %d %s", w.Code, w.Body)
+	}
+	now := time.Now().UTC()
+	putTerminalCash(t, db, terminal, models.ReversibleWithdrawal, lira(400), now.Add(-10*time.Minute), false)
+	// Reversed because the ATM did not pay it out.
+	putTerminalCash(t, db, terminal, models.ReversibleWithdrawal, lira(100), now.Add(-9*time.Minute), true)
+	putTerminalCash(t, db, terminal, models.ReversibleDeposit, lira(150), now.Add(-8*time.Minute), false)
+	putTerminalCash(t, db, terminal, models.ReversibleDeposit, money.New(2000, money.USD), now.Add(-7*time.Minute), false)
+	// Before the terminal was registered, so outside the first period.
+	putTerminalCash(t, db, terminal, models.ReversibleWithdrawal, lira(1000), terminal.RegisteredAt.Add(-time.Minute), false)
+
+	// 3000 loaded - 200 unloaded - 400 dispensed + 150 deposited; the USD
+	// deposit was not counted.
+	first := reconcileTerminal(t, r, terminal, `[{"denomination":{"amount":"200","currency":"TRY"},"count":12},{"denomination":{"amount":"50","currency":"TRY"},"count":3}]`)
+	want := []models.ReconciliationLine{
+		line(money.TRY, 0, 3000, 200, 400, 150, 2550),
+		line(money.USD, 0, 0, 0, 0, 20, 0),
+	}
+	if !slices.Equal(first.Lines, want) || !first.Discrepancy || !first.From.Equal(terminal.RegisteredAt) {
+		t.Fatalf("first reconciliation = %+v, want lines %+v with a discrepancy", first, want)
+	}
+
+	// The next period opens with the cash counted and only covers what
+	// happened since.
+	putTerminalCash(t, db, terminal, models.ReversibleWithdrawal, lira(200), time.Now().UTC(), false)
+	second := reconcileTerminal(t, r, terminal, `[{"denomination":{"amount":"200","currency":"TRY"},"count":11},{"denomination":{"amount":"50","currency":"TRY"},"count":2}]`)
+	want = []models.ReconciliationLine{line(money.TRY, 2550, 0, 0, 200, 0, 2300)}
+	if !slices.Equal(second.Lines, want) || !second.Discrepancy || !second.From.Equal(first.To) {
+		t.Fatalf("second reconciliation = %+v, want lines %+v from %v", second, want, first.To)
+	}
+
+	key, err := db.Get(context.Background(), lastReconciliationKey(terminal.ID))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := timeKey(reconciliationsPrefix, terminal.ID, second.To); string(key) != want {
+		t.Fatalf("last reconciliation = %q, want %q", key, want)
+	}
+
+	// Without the last_reconciliations/ key the latest report is found by
+	// its key.
+	if err := db.Delete(context.Background(), lastReconciliationKey(terminal.ID)); err != nil {
+		t.Fatal(err)
+	}
+	third := reconcileTerminal(t, r, terminal, `[{"denomination":{"amount":"200","currency":"TRY"},"count":11},{"denomination":{"amount":"50","currency":"TRY"},"count":2}]`)
+	want = []models.ReconciliationLine{line(money.TRY, 2300, 0, 0, 0, 0, 2300)}
+	if !slices.Equal(third.Lines, want) || third.Discrepancy || !third.From.Equal(second.To) {
+		t.Fatalf("third reconciliation = %+v, want lines %+v from %v", third, want, second.To)
+	}
+
+	w := serve(r, http.MethodGet, path+"/reconciliations", "")
+	var list struct {
+		Reconciliations []models.Reconciliation `json:"reconciliations"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &list); err != nil {
+		t.Fatal(err)
+	}
+	var ids []uuid.UUID
+	for _, report := range list.Reconciliations {
+		ids = append(ids, report.ID)
+	}
+	if want := []uuid.UUID{third.ID, second.ID, first.ID}; !slices.Equal(ids, want) {
+		t.Errorf("reconciliations = %v, want %v", ids, want)
+	}
+}
@@ -0,0 +1,246 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"newapiprojet/apperrors"
+	"newapiprojet/database"
+	"newapiprojet/dto"
+	"newapiprojet/fees"
+	"newapiprojet/i18n"
+	"newapiprojet/ledger"
+	"newapiprojet/metrics"
+	"newapiprojet/models"
+	"newapiprojet/money"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// Reversals are stored one key each; the reversed deposit or withdrawal
+// keeps its own key and gets a reversal_id:
+//
+//	reversals/<reversalID>  models.Reversal
+const reversalsPrefix = "reversals/"
+
+// ReverseDeposit godoc
+// @Summary Reverse a deposit
+// @Description Undo a deposit by debiting the credited amount from the account, even beyond its overdraft. The deposit record is kept and linked to the reversal. A transaction can only be reversed once. Staff only.
+// @Tags Staff
+// @Accept json
+// @Produce json
+// @Param id path string true "Deposit ID"
+// @Param input body dto.ReversalRequest true "Reason"
+// @Success 200 {object} dto.ReversalResponse "Deposit reversed"
+// @Failure 400 {object} apperrors.Problem "Bad Request"
+// @Failure 403 {object} apperrors.Problem "Forbidden"
+// @Failure 404 {object} apperrors.Problem "Transaction not found"
+// @Failure 409 {object} apperrors.Problem "Already reversed"
+// @Failure 500 {object} apperrors.Problem "Internal Server Error"
+// @Router /staff/deposits/{id}/reversal [post]
+func (h *Handler) ReverseDeposit(c *gin.Context) {
+	h.reverse(c, models.ReversibleDeposit, h.reverseDeposit)
+}
+
+// ReverseWithdrawal godoc
+// @Summary Reverse a withdrawal
+// @Description Undo a withdrawal, for example when the ATM failed to dispense, by crediting the debited amount and any fees charged with it back to the account. A withdrawal made on the current business day no longer counts against the daily limits. The withdrawal record is kept and linked to the reversal. A transaction can only be reversed once. Staff only.
+// @Tags Staff
+// @Accept json
+// @Produce json
+// @Param id path string true "Withdrawal ID"
+// @Param input body dto.ReversalRequest true "Reason"
+// @Success 200 {object} dto.ReversalResponse "Withdrawal reversed"
+// @Failure 400 {object} apperrors.Problem "Bad Request"
+// @Failure 403 {object} apperrors.Problem "Forbidden"
+// @Failure 404 {object} apperrors.Problem "Transaction not found"
+// @Failure 409 {object} apperrors.Problem "Already reversed"
+// @Failure 500 {object} apperrors.Problem "Internal Server Error"
+// @Router /staff/withdrawals/{id}/reversal [post]
+func (h *Handler) ReverseWithdrawal(c *gin.Context) {
+	h.reverse(c, models.ReversibleWithdrawal, h.reverseWithdrawal)
+}
+
+// reverser posts the compensating entries for the transaction
+// reversal.TransactionID, links it to reversal and returns the account.
+// The caller stores the account and the reversal.
+type reverser func(tx database.Tx, reversal *models.Reversal) (models.Account, error)
+
+func (h *Handler) reverse(c *gin.Context, kind models.ReversibleType, post reverser) {
+	var params dto.IDParam
+	if !bindURI(c, &params) {
+		return
+	}
+
+	var input dto.ReversalRequest
+	if !bindJSON(c, &input) {
+		return
+	}
+
+	userID, exists := c.Get("userID")
+	if !exists {
+		abortWithError(c, apperrors.New(apperrors.Unauthorized))
+		return
+	}
+
+	userIDUUID, ok := userID.(uuid.UUID)
+	if !ok {
+		abortWithError(c, errors.New("user ID in context is not a UUID"))
+		return
+	}
+
+	ctx := c.Request.Context()
+
+	var reversal models.Reversal
+	var account models.Account
+	err := h.db.Atomic(ctx, func(tx database.Tx) error {
+		reversal = models.Reversal{
+			ID:              uuid.New(),
+			TransactionType: kind,
+			TransactionID:   params.UUID(),
+			Reason:          models.ReversalReason(input.Reason),
+			Note:            input.Note,
+			ReversedBy:      userIDUUID,
+			ReversedAt:      time.Now(),
+		}
+
+		var err error
+		account, err = post(tx, &reversal)
+		if err != nil {
+			return err
+		}
+		if err := putJSON(tx, accountKey(account.ID), account); err != nil {
+			return err
+		}
+		return putJSON(tx, reversalsPrefix+reversal.ID.String(), reversal)
+	})
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+
+	metrics.ReversalsTotal.WithLabelValues(string(kind), string(reversal.Reason)).Inc()
+	h.logger.InfoContext(ctx, "transaction reversed", "reversal_id", reversal.ID, "transaction_type", kind, "transaction_id", reversal.TransactionID, "account_id", account.ID, "reason", reversal.Reason, "reversed_by", userIDUUID)
+
+	lang := i18n.FromContext(ctx)
+	c.JSON(http.StatusOK, dto.ReversalResponse{
+		Message:          i18n.T(lang, i18n.MsgReversalSuccessful),
+		Reversal:         dto.NewReversalView(reversal),
+		Balance:          account.Balance,
+		BalanceFormatted: i18n.FormatMoney(lang, account.Balance),
+	})
+}
+
+// getTransaction reads the deposit or withdrawal stored under key into v.
+func getTransaction(tx database.Tx, key string, v any) error {
+	data := tx.Get(key)
+	if data == nil {
+		return apperrors.New(apperrors.TransactionNotFound)
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("unmarshaling %s: %w", database.KeyPrefix(key), err)
+	}
+	return nil
+}
+
+var errAlreadyReversed = apperrors.New(apperrors.Conflict).WithDetail(i18n.MsgAlreadyReversed)
+
+func (h *Handler) reverseDeposit(tx database.Tx, reversal *models.Reversal) (models.Account, error) {
+	key := "deposits/" + reversal.TransactionID.String()
+	var deposit models.Deposit
+	if err := getTransaction(tx, key, &deposit); err != nil {
+		return models.Account{}, err
+	}
+	if deposit.ReversalID != nil {
+		return models.Account{}, errAlreadyReversed
+	}
+	account, err := getAccount(tx, deposit.AccountID)
+	if err != nil {
+		return account, err
+	}
+	if err := ledger.CanCredit(account); err != nil {
+		return account, statusError(err)
+	}
+
+	credited := deposit.DepositAmount
+	if deposit.Conversion != nil {
+		credited = deposit.Conversion.To
+	}
+	// The money may already have been spent; the reversal is posted anyway.
+	if err := ledger.Charge(tx, &account, credited, reversal.ReversedAt); err != nil {
+		return account, moneyError(err)
+	}
+
+	reversal.AccountID = account.ID
+	reversal.Amount = credited
+	deposit.ReversalID = &reversal.ID
+	return account, putJSON(tx, key, deposit)
+}
+
+func (h *Handler) reverseWithdrawal(tx database.Tx, reversal *models.Reversal) (models.Account, error) {
+	key := "withdrawals/" + reversal.TransactionID.String()
+	var withdrawal models.Withdrawal
+	if err := getTransaction(tx, key, &withdrawal); err != nil {
+		return models.Account{}, err
+	}
+	if withdrawal.ReversalID != nil {
+		return models.Account{}, errAlreadyReversed
+	}
+	account, err := getAccount(tx, withdrawal.AccountID)
+	if err != nil {
+		return account, err
+	}
+	if err := ledger.CanCredit(account); err != nil {
+		return account, statusError(err)
+	}
+
+	debited := withdrawal.WithdrawalAmount
+	if withdrawal.Conversion != nil {
+		debited = withdrawal.Conversion.To
+	}
+	if err := ledger.Credit(tx, &account, debited, reversal.ReversedAt); err != nil {
+		return account, moneyError(err)
+	}
+	if err := h.limits.Release(tx, account, debited, withdrawal.WithdrawalDate); err != nil {
+		return account, limitError(err)
+	}
+
+	charged, err := fees.Load(tx, withdrawal.FeeIDs)
+	if err != nil {
+		return account, err
+	}
+	if len(charged) > 0 {
+		refunded := money.New(0, account.Balance.Currency())
+		for _, fee := range charged {
+			if refunded, err = refunded.Add(fee.Amount); err != nil {
+				return account, moneyError(err)
+			}
+		}
+		if err := ledger.Credit(tx, &account, refunded, reversal.ReversedAt); err != nil {
+			return account, moneyError(err)
+		}
+		reversal.RefundedFeeIDs = fees.IDs(charged)
+		reversal.FeesRefunded = &refunded
+	}
+
+	// Notes the ATM did not pay out are still in its cassettes, and must not
+	// count as dispensed when it is reconciled.
+	if reversal.Reason == models.ReversalDispenseFailure && withdrawal.TerminalID != nil {
+		if len(withdrawal.Notes) > 0 {
+			if err := returnNotes(tx, *withdrawal.TerminalID, withdrawal.Notes, reversal.ReversedAt); err != nil {
+				return account, err
+			}
+		}
+		if err := markDispenseFailed(tx, *withdrawal.TerminalID, withdrawal); err != nil {
+			return account, err
+		}
+	}
+
+	reversal.AccountID = account.ID
+	reversal.Amount = debited
+	withdrawal.ReversalID = &reversal.ID
+	return account, putJSON(tx, key, withdrawal)
+}
@@ -0,0 +1,123 @@
+package handlers
+
+import (
+	"net/http"
+	"slices"
+	"testing"
+	"time"
+
+	"newapiprojet/apperrors"
+	"newapiprojet/models"
+
+	"github.com/google/uuid"
+)
+
+func TestReverseDepositOnce(t *testing.T) {
+	h, db := newTestHandler(t)
+	r := newTestRouter(uuid.New())
+	r.POST("/staff/deposits/:id/reversal", h.ReverseDeposit)
+
+	account := putAccount(t, db, lira(100))
+	deposit := models.Deposit{ID: uuid.New(), AccountID: account.ID, DepositAmount: lira(30), DepositDate: time.Now()}
+	put(t, db, "deposits/"+deposit.ID.String(), deposit)
+
+	path := "/staff/deposits/" + deposit.ID.String() + "/reversal"
+	if w := serve(r, http.MethodPost, path, `{"reason":"wrong_account"}`); w.Code != http.StatusOK {
+		t.Fatalf("first reversal = %d %s", w.Code, w.Body)
+	}
+	if got := getTestAccount(t, db, account.ID).Balance; got != lira(70) {
+		t.Errorf("balance = %v, want %v", got, lira(70))
+	}
+	get(t, db, "deposits/"+deposit.ID.String(), &deposit)
+	if deposit.ReversalID == nil {
+		t.Fatal("deposit not linked to its reversal")
+	}
+	var reversal models.Reversal
+	if !get(t, db, reversalsPrefix+deposit.ReversalID.String(), &reversal) || reversal.Amount != lira(30) {
+		t.Errorf("reversal = %+v, want one of %v", reversal, lira(30))
+	}
+
+	w := serve(r, http.MethodPost, path, `{"reason":"wrong_account"}`)
+	if w.Code != http.StatusConflict || problemCode(t, w) != apperrors.Conflict {
+		t.Errorf("second reversal = %d %s, want a conflict", w.Code, w.Body)
+	}
+	if got := getTestAccount(t, db, account.ID).Balance; got != lira(70) {
+		t.Errorf("balance after second reversal = %v, want %v", got, lira(70))
+	}
+}
+
+func TestReverseWithdrawalDispenseFailure(t *testing.T) {
+	h, db := newTestHandler(t)
+	r := newTestRouter(uuid.New())
+	r.POST("/staff/withdrawals/:id/reversal", h.ReverseWithdrawal)
+
+	account := putAccount(t, db, lira(100))
+	terminalID := uuid.New()
+	fee := models.Fee{ID: uuid.New(), AccountID: account.ID, Operation: models.FeeWithdrawal, Amount: lira(2)}
+	put(t, db, "fees/"+fee.ID.String(), fee)
+	withdrawal := models.Withdrawal{
+		ID:               uuid.New(),
+		AccountID:        account.ID,
+		WithdrawalAmount: lira(40),
+		WithdrawalDate:   time.Now(),
+		FeeIDs:           []uuid.UUID{fee.ID},
+		TerminalID:       &terminalID,
+		Notes:            []models.Notes{{Denomination: lira(20), Count: 2}},
+	}
+	put(t, db, "withdrawals/"+withdrawal.ID.String(), withdrawal)
+	put(t, db, cassettesKey(terminalID), models.CashInventory{
+		TerminalID: terminalID,
+		Cassettes:  []models.Notes{{Denomination: lira(20), Count: 3}},
+	})
+
+	path := "/staff/withdrawals/" + withdrawal.ID.String() + "/reversal"
+	if w := serve(r, http.MethodPost, path, `{"reason":"dispense_failure"}`); w.Code != http.StatusOK {
+		t.Fatalf("first reversal = %d %s", w.Code, w.Body)
+	}
+	// The withdrawal and its fee are both credited back.
+	if got := getTestAccount(t, db, account.ID).Balance; got != lira(142) {
+		t.Errorf("balance = %v, want %v", got, lira(142))
+	}
+	get(t, db, "withdrawals/"+withdrawal.ID.String(), &withdrawal)
+	var reversal models.Reversal
+	if withdrawal.ReversalID == nil || !get(t, db, reversalsPrefix+withdrawal.ReversalID.String(), &reversal) {
+		t.Fatal("withdrawal not linked to a stored reversal")
+	}
+	if !slices.Equal(reversal.RefundedFeeIDs, []uuid.UUID{fee.ID}) || reversal.FeesRefunded == nil || *reversal.FeesRefunded != lira(2) {
+		t.Errorf("reversal refunded %v (%v), want fee %s of %v", reversal.RefundedFeeIDs, reversal.FeesRefunded, fee.ID, lira(2))
+	}
+
+	// The notes never left the ATM.
+	var inventory models.CashInventory
+	get(t, db, cassettesKey(terminalID), &inventory)
+	if want := []models.Notes{{Denomination: lira(20), Count: 5}}; !slices.Equal(inventory.Cassettes, want) {
+		t.Errorf("cassettes = %v, want %v", inventory.Cassettes, want)
+	}
+	var cash models.TerminalCash
+	if !get(t, db, terminalTxKey(terminalID, withdrawal.WithdrawalDate, withdrawal.ID), &cash) || !cash.DispenseFailed {
+		t.Errorf("terminal transaction = %+v, want it marked as not dispensed", cash)
+	}
+
+	w := serve(r, http.MethodPost, path, `{"reason":"dispense_failure"}`)
+	if w.Code != http.StatusConflict || problemCode(t, w) != apperrors.Conflict {
+		t.Errorf("second reversal = %d %s, want a conflict", w.Code, w.Body)
+	}
+	if got := getTestAccount(t, db, account.ID).Balance; got != lira(142) {
+		t.Errorf("balance after second reversal = %v, want %v", got, lira(142))
+	}
+	get(t, db, cassettesKey(terminalID), &inventory)
+	if want := []models.Notes{{Denomination: lira(20), Count: 5}}; !slices.Equal(inventory.Cassettes, want) {
+		t.Errorf("cassettes after second reversal = %v, want %v", inventory.Cassettes, want)
+	}
+}
+
+func TestReverseUnknownTransaction(t *testing.T) {
+	h, _ := newTestHandler(t)
+	r := newTestRouter(uuid.New())
+	r.POST("/staff/withdrawals/:id/reversal", h.ReverseWithdrawal)
+
+	w := serve(r, http.MethodPost, "/staff/withdrawals/"+uuid.NewString()+"/reversal", `{"reason":"other"}`)
+	if w.Code != http.StatusNotFound || problemCode(t, w) != apperrors.TransactionNotFound {
+		t.Errorf("reversal = %d %s, want transaction not found", w.Code, w.Body)
+	}
+}
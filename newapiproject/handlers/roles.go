@@ -0,0 +1,146 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"newapiprojet/apperrors"
+	"newapiprojet/database"
+	"newapiprojet/dto"
+	"newapiprojet/models"
+	"slices"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// SetUserRole godoc
+// @Summary Set a user's role
+// @Description Make a user a customer, teller or admin. The change applies to the user's next request, including with tokens issued before it.
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param id path string true "User ID"
+// @Param input body dto.SetRoleRequest true "Role"
+// @Success 200 {object} dto.UserResponse "Role changed"
+// @Failure 400 {object} apperrors.Problem "Bad Request"
+// @Failure 403 {object} apperrors.Problem "Forbidden"
+// @Failure 404 {object} apperrors.Problem "User not found"
+// @Failure 500 {object} apperrors.Problem "Internal Server Error"
+// @Router /admin/users/{id}/role [put]
+func (h *Handler) SetUserRole(c *gin.Context) {
+	var params dto.IDParam
+	if !bindURI(c, &params) {
+		return
+	}
+
+	var input dto.SetRoleRequest
+	if !bindJSON(c, &input) {
+		return
+	}
+
+	userID, exists := c.Get("userID")
+	if !exists {
+		abortWithError(c, apperrors.New(apperrors.Unauthorized))
+		return
+	}
+
+	userIDUUID, ok := userID.(uuid.UUID)
+	if !ok {
+		abortWithError(c, errors.New("user ID in context is not a UUID"))
+		return
+	}
+
+	ctx := c.Request.Context()
+
+	var user models.User
+	var from models.Role
+	err := h.db.Atomic(ctx, func(tx database.Tx) error {
+		var err error
+		user, from, err = setRole(tx, "users/"+params.UUID().String(), models.Role(input.Role))
+		return err
+	})
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+
+	h.logger.InfoContext(ctx, "user role changed", "user_id", user.ID, "from", from, "to", user.Role, "changed_by", userIDUUID)
+	c.JSON(http.StatusOK, dto.UserResponse{User: dto.NewUserView(user)})
+}
+
+// GrantRole gives the user registered as username the given role. It is
+// how the first admin is appointed, from the command line, since only
+// admins may call PUT /admin/users/{id}/role.
+func GrantRole(ctx context.Context, db database.Database, username string, role models.Role) (models.User, error) {
+	var user models.User
+	err := db.Atomic(ctx, func(tx database.Tx) error {
+		key := "users/" + username
+		if id := tx.Get(usernameKey(username)); id != nil {
+			key = "users/" + string(id)
+		}
+		var err error
+		user, _, err = setRole(tx, key, role)
+		return err
+	})
+	return user, err
+}
+
+// setRole stores role on the user under key and returns the user and the
+// role it had before.
+func setRole(tx database.Tx, key string, role models.Role) (models.User, models.Role, error) {
+	var user models.User
+	data := tx.Get(key)
+	if data == nil {
+		return user, "", apperrors.New(apperrors.UserNotFound)
+	}
+	if err := json.Unmarshal(data, &user); err != nil {
+		return user, "", fmt.Errorf("unmarshaling user data: %w", err)
+	}
+	from := user.Role
+	user.Role = role
+	return user, from, putJSON(tx, key, user)
+}
+
+// RequireRole only lets through users whose role is one of roles. The role
+// is read from the stored user on every request, not from the token, so a
+// demoted teller or admin loses access at once. It must run after
+// middlewares.AuthenticateJWT.
+func (h *Handler) RequireRole(roles ...models.Role) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, exists := c.Get("userID")
+		if !exists {
+			abortWithError(c, apperrors.New(apperrors.Unauthorized))
+			return
+		}
+
+		userIDUUID, ok := userID.(uuid.UUID)
+		if !ok {
+			abortWithError(c, errors.New("user ID in context is not a UUID"))
+			return
+		}
+
+		data, err := h.db.Get(c.Request.Context(), "users/"+userIDUUID.String())
+		if err != nil {
+			abortWithError(c, fmt.Errorf("retrieving user data: %w", err))
+			return
+		}
+		// A deleted user's token is still valid until it expires.
+		if data == nil {
+			abortWithError(c, apperrors.New(apperrors.Forbidden))
+			return
+		}
+		var user models.User
+		if err := json.Unmarshal(data, &user); err != nil {
+			abortWithError(c, fmt.Errorf("unmarshaling user data: %w", err))
+			return
+		}
+		if !slices.Contains(roles, user.Role) {
+			abortWithError(c, apperrors.New(apperrors.Forbidden))
+			return
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,513 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"newapiprojet/apperrors"
+	"newapiprojet/cron"
+	"newapiprojet/database"
+	"newapiprojet/dto"
+	"newapiprojet/i18n"
+	"newapiprojet/ledger"
+	"newapiprojet/metrics"
+	"newapiprojet/models"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// Standing orders are stored one key each, and indexed by the accounts they
+// move money between so closing an account can cancel them:
+//
+//	standing_orders/<orderID>                models.StandingOrder
+//	account_standing_orders/<accountID>      []uuid.UUID of order IDs
+const (
+	standingOrdersPrefix        = "standing_orders/"
+	accountStandingOrdersPrefix = "account_standing_orders/"
+)
+
+func standingOrderKey(id uuid.UUID) string {
+	return standingOrdersPrefix + id.String()
+}
+
+func accountStandingOrdersKey(accountID uuid.UUID) string {
+	return accountStandingOrdersPrefix + accountID.String()
+}
+
+// accountStandingOrders returns the IDs of the standing orders from or to
+// accountID.
+func accountStandingOrders(tx database.Tx, accountID uuid.UUID) ([]uuid.UUID, error) {
+	var ids []uuid.UUID
+	if data := tx.Get(accountStandingOrdersKey(accountID)); data != nil {
+		if err := json.Unmarshal(data, &ids); err != nil {
+			return nil, fmt.Errorf("unmarshaling account standing orders: %w", err)
+		}
+	}
+	return ids, nil
+}
+
+// indexStandingOrder adds order to the index of both its accounts.
+func indexStandingOrder(tx database.Tx, order models.StandingOrder) error {
+	for _, accountID := range []uuid.UUID{order.FromAccountID, order.ToAccountID} {
+		ids, err := accountStandingOrders(tx, accountID)
+		if err != nil {
+			return err
+		}
+		if err := putJSON(tx, accountStandingOrdersKey(accountID), append(ids, order.ID)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// cancelAccountStandingOrders cancels, as part of tx, every standing order
+// from or to accountID, and returns how many it cancelled. It is called when
+// the account is closed.
+func cancelAccountStandingOrders(tx database.Tx, accountID uuid.UUID) (int, error) {
+	ids, err := accountStandingOrders(tx, accountID)
+	if err != nil {
+		return 0, err
+	}
+	cancelled := 0
+	for _, id := range ids {
+		data := tx.Get(standingOrderKey(id))
+		if data == nil {
+			continue
+		}
+		var order models.StandingOrder
+		if err := json.Unmarshal(data, &order); err != nil {
+			return 0, fmt.Errorf("unmarshaling standing order: %w", err)
+		}
+		if order.Status == models.StandingOrderCancelled {
+			continue
+		}
+		order.Status = models.StandingOrderCancelled
+		order.Attempts = 0
+		if err := putJSON(tx, standingOrderKey(order.ID), order); err != nil {
+			return 0, err
+		}
+		cancelled++
+	}
+	return cancelled, nil
+}
+
+// getOwnedStandingOrder reads a standing order that must belong to userID.
+func getOwnedStandingOrder(tx database.Tx, id, userID uuid.UUID) (models.StandingOrder, error) {
+	var order models.StandingOrder
+	data := tx.Get(standingOrderKey(id))
+	if data == nil {
+		return order, apperrors.New(apperrors.StandingOrderNotFound)
+	}
+	if err := json.Unmarshal(data, &order); err != nil {
+		return order, fmt.Errorf("unmarshaling standing order: %w", err)
+	}
+	if order.UserID != userID {
+		return order, apperrors.New(apperrors.Forbidden).WithDetail(i18n.MsgAccountAccessDenied)
+	}
+	return order, nil
+}
+
+// nextRun returns when schedule is next due after t, in the bank's time
+// zone, or the zero time if it never is.
+func (h *Handler) nextRun(schedule string, t time.Time) (time.Time, error) {
+	s, err := cron.Parse(schedule)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return s.Next(t.In(h.cal.Location())), nil
+}
+
+// CreateStandingOrder godoc
+// @Summary Create a standing order
+// @Description Schedule a recurring transfer from one of the user's accounts. The schedule is a five-field cron expression ("minute hour day-of-month month day-of-week") or a macro such as @monthly, in the bank's time zone. When the source account cannot cover a run, "retry" tries again later up to max_retries times and "skip" waits for the next scheduled run.
+// @Tags Account
+// @Accept json
+// @Produce json
+// @Param input body dto.StandingOrderRequest true "Standing order"
+// @Success 201 {object} dto.StandingOrderResponse "Standing order created"
+// @Failure 400 {object} apperrors.Problem "Bad Request"
+// @Failure 403 {object} apperrors.Problem "Forbidden"
+// @Failure 404 {object} apperrors.Problem "Account not found"
+// @Failure 500 {object} apperrors.Problem "Internal Server Error"
+// @Router /account/standing-orders [post]
+func (h *Handler) CreateStandingOrder(c *gin.Context) {
+	var input dto.StandingOrderRequest
+	if !bindJSON(c, &input) {
+		return
+	}
+
+	userID, exists := c.Get("userID")
+	if !exists {
+		abortWithError(c, apperrors.New(apperrors.Unauthorized))
+		return
+	}
+
+	userIDUUID, ok := userID.(uuid.UUID)
+	if !ok {
+		abortWithError(c, errors.New("user ID in context is not a UUID"))
+		return
+	}
+
+	if input.FromAccountID == input.ToAccountID {
+		abortWithError(c, apperrors.New(apperrors.InvalidRequest).WithDetail(i18n.MsgTransferSameAccount))
+		return
+	}
+
+	now := time.Now()
+	next, err := h.nextRun(input.Schedule, now)
+	if err != nil {
+		abortWithError(c, apperrors.Wrap(apperrors.InvalidRequest, err))
+		return
+	}
+	if next.IsZero() {
+		abortWithError(c, apperrors.New(apperrors.InvalidRequest).WithDetail(i18n.MsgStandingOrderNoRun))
+		return
+	}
+
+	order := models.StandingOrder{
+		ID:                  uuid.New(),
+		UserID:              userIDUUID,
+		FromAccountID:       input.FromAccountID,
+		ToAccountID:         input.ToAccountID,
+		Amount:              input.Amount,
+		Schedule:            input.Schedule,
+		Status:              models.StandingOrderActive,
+		OnInsufficientFunds: input.Policy(),
+		MaxRetries:          input.MaxRetries,
+		NextRunAt:           next,
+		CreatedAt:           now,
+	}
+
+	ctx := c.Request.Context()
+
+	err = h.db.Atomic(ctx, func(tx database.Tx) error {
+		from, err := getOwnedAccount(tx, order.FromAccountID, userIDUUID)
+		if err != nil {
+			return err
+		}
+		to, err := getAccount(tx, order.ToAccountID)
+		if err != nil {
+			return err
+		}
+		if err := ledger.CanDebit(from); err != nil {
+			return statusError(err)
+		}
+		if err := ledger.CanCredit(to); err != nil {
+			return statusError(err)
+		}
+		if currency := order.Amount.Currency(); currency != from.Balance.Currency() && currency != to.Balance.Currency() {
+			return apperrors.New(apperrors.CurrencyMismatch).WithDetail(i18n.MsgTransferCurrency)
+		}
+		if err := indexStandingOrder(tx, order); err != nil {
+			return err
+		}
+		return putJSON(tx, standingOrderKey(order.ID), order)
+	})
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+
+	h.logger.InfoContext(ctx, "standing order created", "standing_order_id", order.ID, "from_account_id", order.FromAccountID, "schedule", order.Schedule, "next_run_at", order.NextRunAt)
+	c.JSON(http.StatusCreated, dto.StandingOrderResponse{StandingOrder: dto.NewStandingOrderView(order)})
+}
+
+// ListStandingOrders godoc
+// @Summary List standing orders
+// @Description List the user's standing orders, including paused and cancelled ones, oldest first.
+// @Tags Account
+// @Produce json
+// @Success 200 {object} dto.StandingOrdersResponse "Standing orders"
+// @Failure 500 {object} apperrors.Problem "Internal Server Error"
+// @Router /account/standing-orders [get]
+func (h *Handler) ListStandingOrders(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		abortWithError(c, apperrors.New(apperrors.Unauthorized))
+		return
+	}
+
+	userIDUUID, ok := userID.(uuid.UUID)
+	if !ok {
+		abortWithError(c, errors.New("user ID in context is not a UUID"))
+		return
+	}
+
+	orders, err := h.standingOrders(c.Request.Context())
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+
+	views := []dto.StandingOrderView{}
+	for _, order := range orders {
+		if order.UserID == userIDUUID {
+			views = append(views, dto.NewStandingOrderView(order))
+		}
+	}
+	c.JSON(http.StatusOK, dto.StandingOrdersResponse{StandingOrders: views})
+}
+
+// standingOrders returns every stored standing order, oldest first.
+func (h *Handler) standingOrders(ctx context.Context) ([]models.StandingOrder, error) {
+	data, err := h.db.List(ctx, standingOrdersPrefix)
+	if err != nil {
+		return nil, fmt.Errorf("listing standing orders: %w", err)
+	}
+	orders := make([]models.StandingOrder, 0, len(data))
+	for key, value := range data {
+		var order models.StandingOrder
+		if err := json.Unmarshal(value, &order); err != nil {
+			return nil, fmt.Errorf("unmarshaling standing order %s: %w", strings.TrimPrefix(key, standingOrdersPrefix), err)
+		}
+		orders = append(orders, order)
+	}
+	sort.Slice(orders, func(i, j int) bool { return orders[i].CreatedAt.Before(orders[j].CreatedAt) })
+	return orders, nil
+}
+
+// PauseStandingOrder godoc
+// @Summary Pause a standing order
+// @Description Stop a standing order from running until it is resumed.
+// @Tags Account
+// @Produce json
+// @Param id path string true "Standing order ID"
+// @Success 200 {object} dto.StandingOrderResponse "Standing order paused"
+// @Failure 403 {object} apperrors.Problem "Forbidden"
+// @Failure 404 {object} apperrors.Problem "Standing order not found"
+// @Failure 409 {object} apperrors.Problem "Standing order is cancelled"
+// @Failure 500 {object} apperrors.Problem "Internal Server Error"
+// @Router /account/standing-orders/{id}/pause [post]
+func (h *Handler) PauseStandingOrder(c *gin.Context) {
+	h.updateStandingOrder(c, "standing order paused", func(order *models.StandingOrder, now time.Time) error {
+		order.Status = models.StandingOrderPaused
+		order.Attempts = 0
+		return nil
+	})
+}
+
+// ResumeStandingOrder godoc
+// @Summary Resume a standing order
+// @Description Reactivate a paused standing order. Runs missed while it was paused are not made up; it next runs at the first scheduled time from now.
+// @Tags Account
+// @Produce json
+// @Param id path string true "Standing order ID"
+// @Success 200 {object} dto.StandingOrderResponse "Standing order resumed"
+// @Failure 403 {object} apperrors.Problem "Forbidden"
+// @Failure 404 {object} apperrors.Problem "Standing order not found"
+// @Failure 409 {object} apperrors.Problem "Standing order is cancelled"
+// @Failure 500 {object} apperrors.Problem "Internal Server Error"
+// @Router /account/standing-orders/{id}/resume [post]
+func (h *Handler) ResumeStandingOrder(c *gin.Context) {
+	h.updateStandingOrder(c, "standing order resumed", func(order *models.StandingOrder, now time.Time) error {
+		if order.Status == models.StandingOrderActive {
+			return nil
+		}
+		next, err := h.nextRun(order.Schedule, now)
+		if err != nil {
+			return err
+		}
+		order.Status = models.StandingOrderActive
+		order.NextRunAt = next
+		return nil
+	})
+}
+
+// CancelStandingOrder godoc
+// @Summary Cancel a standing order
+// @Description Cancel a standing order for good. The order is kept, with status "cancelled", so its history stays visible.
+// @Tags Account
+// @Produce json
+// @Param id path string true "Standing order ID"
+// @Success 200 {object} dto.StandingOrderResponse "Standing order cancelled"
+// @Failure 403 {object} apperrors.Problem "Forbidden"
+// @Failure 404 {object} apperrors.Problem "Standing order not found"
+// @Failure 500 {object} apperrors.Problem "Internal Server Error"
+// @Router /account/standing-orders/{id} [delete]
+func (h *Handler) CancelStandingOrder(c *gin.Context) {
+	h.updateStandingOrder(c, "standing order cancelled", func(order *models.StandingOrder, now time.Time) error {
+		order.Status = models.StandingOrderCancelled
+		order.Attempts = 0
+		return nil
+	})
+}
+
+// updateStandingOrder applies change to the user's standing order {id} and
+// responds with the result. Cancelled orders are only ever cancelled again.
+func (h *Handler) updateStandingOrder(c *gin.Context, event string, change func(order *models.StandingOrder, now time.Time) error) {
+	var params dto.IDParam
+	if !bindURI(c, &params) {
+		return
+	}
+
+	userID, exists := c.Get("userID")
+	if !exists {
+		abortWithError(c, apperrors.New(apperrors.Unauthorized))
+		return
+	}
+
+	userIDUUID, ok := userID.(uuid.UUID)
+	if !ok {
+		abortWithError(c, errors.New("user ID in context is not a UUID"))
+		return
+	}
+
+	ctx := c.Request.Context()
+
+	var order models.StandingOrder
+	err := h.db.Atomic(ctx, func(tx database.Tx) error {
+		var err error
+		order, err = getOwnedStandingOrder(tx, params.UUID(), userIDUUID)
+		if err != nil {
+			return err
+		}
+		cancelled := order.Status == models.StandingOrderCancelled
+		if err := change(&order, time.Now()); err != nil {
+			return err
+		}
+		if cancelled {
+			if order.Status != models.StandingOrderCancelled {
+				return apperrors.New(apperrors.Conflict).WithDetail(i18n.MsgStandingOrderState)
+			}
+			return nil
+		}
+		return putJSON(tx, standingOrderKey(order.ID), order)
+	})
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+
+	h.logger.InfoContext(ctx, event, "standing_order_id", order.ID)
+	c.JSON(http.StatusOK, dto.StandingOrderResponse{StandingOrder: dto.NewStandingOrderView(order)})
+}
+
+// RunStandingOrders executes every active standing order that is due. It is
+// run by the scheduler on the leader replica and safe to run repeatedly: a
+// run moves the order's next run time forward in the same transaction as
+// the transfer. Runs missed while the scheduler was down are executed once,
+// not once per missed time.
+//
+// When the source account cannot cover a run, orders with the retry policy
+// try again after retryAfter until MaxRetries is reached; then, or for any
+// other rejection, the run is skipped and recorded on the order.
+func (h *Handler) RunStandingOrders(ctx context.Context, retryAfter time.Duration) error {
+	orders, err := h.standingOrders(ctx)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	var due, failed int
+	for _, order := range orders {
+		if order.Status != models.StandingOrderActive || order.NextRunAt.IsZero() || order.NextRunAt.After(now) {
+			continue
+		}
+		due++
+		run, err := h.runStandingOrder(ctx, order, now, retryAfter)
+		if err != nil {
+			failed++
+			h.logger.ErrorContext(ctx, "standing order failed", "standing_order_id", order.ID, "error", err)
+			continue
+		}
+		if run == nil {
+			continue // changed since it was listed
+		}
+		metrics.StandingOrderRunsTotal.WithLabelValues(string(run.Status)).Inc()
+		h.logger.InfoContext(ctx, "standing order run", "standing_order_id", order.ID, "status", run.Status, "transfer_id", run.TransferID, "error", run.Error)
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d of %d due standing orders failed", failed, due)
+	}
+	return nil
+}
+
+// runStandingOrder makes listed's transfer. A rejected transfer is recorded
+// in a second transaction so that none of its writes are kept. It returns
+// nil when the order was changed or already run since it was listed.
+func (h *Handler) runStandingOrder(ctx context.Context, listed models.StandingOrder, now time.Time, retryAfter time.Duration) (*models.StandingOrderRun, error) {
+	var run *models.StandingOrderRun
+	var rejected error
+	err := h.db.Atomic(ctx, func(tx database.Tx) error {
+		run, rejected = nil, nil
+
+		order, ok, err := h.dueStandingOrder(tx, listed)
+		if err != nil || !ok {
+			return err
+		}
+		result, err := h.executeTransfer(ctx, tx, transferRequest{
+			FromAccountID:   order.FromAccountID,
+			ToAccountID:     order.ToAccountID,
+			UserID:          order.UserID,
+			Amount:          order.Amount,
+			StandingOrderID: &order.ID,
+		})
+		if err != nil {
+			if errors.As(err, new(*apperrors.Error)) {
+				rejected = err
+			}
+			return err
+		}
+
+		next, err := h.nextRun(order.Schedule, now)
+		if err != nil {
+			return err
+		}
+		run = &models.StandingOrderRun{At: now, Status: models.StandingOrderExecuted, TransferID: &result.Transfer.ID}
+		order.LastRun = run
+		order.Attempts = 0
+		order.NextRunAt = next
+		return putJSON(tx, standingOrderKey(order.ID), order)
+	})
+	if rejected == nil {
+		return run, err
+	}
+
+	err = h.db.Atomic(ctx, func(tx database.Tx) error {
+		run = nil
+
+		order, ok, err := h.dueStandingOrder(tx, listed)
+		if err != nil || !ok {
+			return err
+		}
+		run = &models.StandingOrderRun{At: now, Status: models.StandingOrderSkipped, Error: string(apperrors.From(rejected).Code)}
+		retry := order.OnInsufficientFunds == models.RetryOnInsufficientFunds && order.Attempts < order.MaxRetries
+		if retry && errors.Is(rejected, ledger.ErrInsufficientFunds) {
+			run.Status = models.StandingOrderRetrying
+			order.Attempts++
+			order.NextRunAt = now.Add(retryAfter)
+		} else {
+			next, err := h.nextRun(order.Schedule, now)
+			if err != nil {
+				return err
+			}
+			order.Attempts = 0
+			order.NextRunAt = next
+		}
+		order.LastRun = run
+		return putJSON(tx, standingOrderKey(order.ID), order)
+	})
+	return run, err
+}
+
+// dueStandingOrder re-reads listed inside tx and reports whether it is
+// still active and due at the time it was listed.
+func (h *Handler) dueStandingOrder(tx database.Tx, listed models.StandingOrder) (models.StandingOrder, bool, error) {
+	var order models.StandingOrder
+	data := tx.Get(standingOrderKey(listed.ID))
+	if data == nil {
+		return order, false, nil
+	}
+	if err := json.Unmarshal(data, &order); err != nil {
+		return order, false, fmt.Errorf("unmarshaling standing order: %w", err)
+	}
+	ok := order.Status == models.StandingOrderActive && order.NextRunAt.Equal(listed.NextRunAt)
+	return order, ok, nil
+}
@@ -0,0 +1,49 @@
+package handlers
+
+import (
+	"net/http"
+	"testing"
+
+	"newapiprojet/apperrors"
+	"newapiprojet/models"
+
+	"github.com/google/uuid"
+)
+
+func TestCreateStandingOrderAccountStatus(t *testing.T) {
+	tests := []struct {
+		name     string
+		from, to models.AccountStatus
+		want     int
+	}{
+		{"active accounts", models.AccountActive, models.AccountActive, http.StatusCreated},
+		// Standing orders take money from the source, so it must be debitable.
+		{"frozen source", models.AccountFrozen, models.AccountActive, http.StatusConflict},
+		{"dormant source", models.AccountDormant, models.AccountActive, http.StatusConflict},
+		{"closed source", models.AccountClosed, models.AccountActive, http.StatusConflict},
+		// A frozen or dormant account can still be paid into.
+		{"frozen destination", models.AccountActive, models.AccountFrozen, http.StatusCreated},
+		{"dormant destination", models.AccountActive, models.AccountDormant, http.StatusCreated},
+		{"closed destination", models.AccountActive, models.AccountClosed, http.StatusConflict},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h, db := newTestHandler(t)
+			userID := uuid.New()
+			from := models.Account{ID: uuid.New(), UserID: userID, Balance: lira(100), Status: tt.from}
+			to := models.Account{ID: uuid.New(), UserID: uuid.New(), Balance: lira(0), Status: tt.to}
+			put(t, db, accountKey(from.ID), from)
+			put(t, db, accountKey(to.ID), to)
+
+			r := newTestRouter(userID)
+			r.POST("/account/standing-orders", h.CreateStandingOrder)
+			w := serve(r, http.MethodPost, "/account/standing-orders", `{"fromAccountID":"`+from.ID.String()+`","toAccountID":"`+to.ID.String()+`","amount":{"amount":"10","currency":"TRY"},"schedule":"@monthly"}`)
+			if w.Code != tt.want {
+				t.Fatalf("create = %d %s, want %d", w.Code, w.Body, tt.want)
+			}
+			if tt.want == http.StatusConflict && problemCode(t, w) != apperrors.AccountUnavailable {
+				t.Errorf("code = %s, want %s", problemCode(t, w), apperrors.AccountUnavailable)
+			}
+		})
+	}
+}
@@ -0,0 +1,52 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"newapiprojet/apperrors"
+	"newapiprojet/database"
+	"newapiprojet/i18n"
+	"newapiprojet/models"
+
+	"github.com/google/uuid"
+)
+
+func accountKey(id uuid.UUID) string {
+	return "accounts/" + id.String()
+}
+
+// getAccount reads an account inside a transaction without checking who
+// owns it.
+func getAccount(tx database.Tx, accountID uuid.UUID) (models.Account, error) {
+	var account models.Account
+	data := tx.Get(accountKey(accountID))
+	if data == nil {
+		return account, apperrors.New(apperrors.AccountNotFound)
+	}
+	if err := json.Unmarshal(data, &account); err != nil {
+		return account, fmt.Errorf("unmarshaling account data: %w", err)
+	}
+	return account, nil
+}
+
+// getOwnedAccount is getAccount for accounts that must belong to userID.
+func getOwnedAccount(tx database.Tx, accountID, userID uuid.UUID) (models.Account, error) {
+	account, err := getAccount(tx, accountID)
+	if err != nil {
+		return account, err
+	}
+	if account.UserID != userID {
+		return account, apperrors.New(apperrors.Forbidden).WithDetail(i18n.MsgAccountAccessDenied)
+	}
+	return account, nil
+}
+
+// putJSON marshals v and writes it under key as part of tx.
+func putJSON(tx database.Tx, key string, v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("marshaling %s: %w", database.KeyPrefix(key), err)
+	}
+	tx.Put(key, data)
+	return nil
+}
@@ -0,0 +1,438 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"newapiprojet/apperrors"
+	"newapiprojet/config"
+	"newapiprojet/database"
+	"newapiprojet/dto"
+	"newapiprojet/i18n"
+	"newapiprojet/models"
+	"newapiprojet/terminals"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// Terminals are stored one key each:
+//
+//	terminals/<terminalID>  models.Terminal
+const terminalsPrefix = "terminals/"
+
+func terminalKey(id uuid.UUID) string {
+	return terminalsPrefix + id.String()
+}
+
+func getTerminal(tx database.Tx, id uuid.UUID) (models.Terminal, error) {
+	var terminal models.Terminal
+	data := tx.Get(terminalKey(id))
+	if data == nil {
+		return terminal, apperrors.New(apperrors.TerminalNotFound)
+	}
+	if err := json.Unmarshal(data, &terminal); err != nil {
+		return terminal, fmt.Errorf("unmarshaling terminal: %w", err)
+	}
+	return terminal, nil
+}
+
+// terminalID returns the ATM the request was authenticated as, or nil if
+// it did not come from one.
+func terminalID(c *gin.Context) *uuid.UUID {
+	id, ok := c.Get("terminalID")
+	if !ok {
+		return nil
+	}
+	if id, ok := id.(uuid.UUID); ok {
+		return &id
+	}
+	return nil
+}
+
+// RegisterTerminal godoc
+// @Summary Register an ATM
+// @Description Register an ATM so that it can call the account routes. The terminal's shared secret is generated and returned only in this response. Admin only.
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param input body dto.RegisterTerminalRequest true "Terminal location"
+// @Success 201 {object} dto.RegisterTerminalResponse "Terminal registered"
+// @Failure 400 {object} apperrors.Problem "Bad Request"
+// @Failure 403 {object} apperrors.Problem "Forbidden"
+// @Failure 500 {object} apperrors.Problem "Internal Server Error"
+// @Router /admin/terminals [post]
+func (h *Handler) RegisterTerminal(c *gin.Context) {
+	var input dto.RegisterTerminalRequest
+	if !bindJSON(c, &input) {
+		return
+	}
+
+	userID, exists := c.Get("userID")
+	if !exists {
+		abortWithError(c, apperrors.New(apperrors.Unauthorized))
+		return
+	}
+
+	userIDUUID, ok := userID.(uuid.UUID)
+	if !ok {
+		abortWithError(c, errors.New("user ID in context is not a UUID"))
+		return
+	}
+
+	secret, err := terminals.NewSecret()
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+	terminal := models.Terminal{
+		ID:              uuid.New(),
+		Location:        input.Location,
+		Branch:          input.Branch,
+		Status:          models.TerminalActive,
+		Secret:          secret,
+		CertFingerprint: strings.ToLower(input.CertFingerprint),
+		RegisteredBy:    userIDUUID,
+		RegisteredAt:    time.Now().UTC(),
+	}
+
+	ctx := c.Request.Context()
+
+	err = h.db.Atomic(ctx, func(tx database.Tx) error {
+		return putJSON(tx, terminalKey(terminal.ID), terminal)
+	})
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+
+	h.logger.InfoContext(ctx, "terminal registered", "terminal_id", terminal.ID, "location", terminal.Location, "branch", terminal.Branch, "registered_by", userIDUUID)
+	c.JSON(http.StatusCreated, dto.RegisterTerminalResponse{Terminal: dto.NewTerminalView(terminal), Secret: secret})
+}
+
+// DecommissionTerminal godoc
+// @Summary Decommission an ATM
+// @Description Take an ATM out of service. Its requests are rejected from then on; decommissioning is permanent. Admin only.
+// @Tags Admin
+// @Produce json
+// @Param id path string true "Terminal ID"
+// @Success 200 {object} dto.TerminalResponse "Terminal decommissioned"
+// @Failure 400 {object} apperrors.Problem "Bad Request"
+// @Failure 403 {object} apperrors.Problem "Forbidden"
+// @Failure 404 {object} apperrors.Problem "Terminal not found"
+// @Failure 500 {object} apperrors.Problem "Internal Server Error"
+// @Router /admin/terminals/{id}/decommission [post]
+func (h *Handler) DecommissionTerminal(c *gin.Context) {
+	var params dto.IDParam
+	if !bindURI(c, &params) {
+		return
+	}
+
+	ctx := c.Request.Context()
+
+	var terminal models.Terminal
+	err := h.db.Atomic(ctx, func(tx database.Tx) error {
+		var err error
+		terminal, err = getTerminal(tx, params.UUID())
+		if err != nil || terminal.Status == models.TerminalDecommissioned {
+			return err
+		}
+		now := time.Now().UTC()
+		terminal.Status = models.TerminalDecommissioned
+		terminal.DecommissionedAt = &now
+		return putJSON(tx, terminalKey(terminal.ID), terminal)
+	})
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+
+	h.logger.InfoContext(ctx, "terminal decommissioned", "terminal_id", terminal.ID, "location", terminal.Location)
+	c.JSON(http.StatusOK, dto.TerminalResponse{Terminal: dto.NewTerminalView(terminal)})
+}
+
+// AuthenticateTerminal checks that the request comes from an active,
+// registered ATM, identified by the terminals headers. The ATM proves its
+// identity with its client certificate, when the API is served over TLS, or
+// by signing the request with its shared secret. Requests without the
+// headers pass only when require_terminal_auth is off.
+func (h *Handler) AuthenticateTerminal() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		header := c.GetHeader(terminals.IDHeader)
+		if header == "" {
+			if config.GetConfig().RequireTerminalAuth {
+				abortWithError(c, apperrors.New(apperrors.TerminalUnauthorized).WithDetail(i18n.MsgTerminalMissing))
+				return
+			}
+			c.Next()
+			return
+		}
+
+		ctx := c.Request.Context()
+
+		id, err := uuid.Parse(header)
+		if err != nil {
+			abortWithError(c, apperrors.New(apperrors.TerminalUnauthorized))
+			return
+		}
+		data, err := h.db.Get(ctx, terminalKey(id))
+		if err != nil {
+			abortWithError(c, fmt.Errorf("retrieving terminal: %w", err))
+			return
+		}
+		// An unknown terminal is a failed authentication rather than a
+		// missing terminal, so terminal IDs cannot be probed.
+		if data == nil {
+			h.logger.WarnContext(ctx, "request from unknown terminal", "terminal_id", id)
+			abortWithError(c, apperrors.New(apperrors.TerminalUnauthorized))
+			return
+		}
+		var terminal models.Terminal
+		if err := json.Unmarshal(data, &terminal); err != nil {
+			abortWithError(c, fmt.Errorf("unmarshaling terminal: %w", err))
+			return
+		}
+		if terminal.Status != models.TerminalActive {
+			h.logger.WarnContext(ctx, "request from decommissioned terminal", "terminal_id", id)
+			abortWithError(c, apperrors.New(apperrors.TerminalUnauthorized).WithDetail(i18n.MsgTerminalInactive))
+			return
+		}
+
+		if !terminalCertified(c, terminal) && !h.verifyTerminalSignature(c, terminal) {
+			return
+		}
+
+		c.Set("terminalID", terminal.ID)
+		c.Next()
+	}
+}
+
+// verifyTerminalSignature checks the request's signature and that its nonce
+// has not been used before. On failure it aborts and returns false.
+func (h *Handler) verifyTerminalSignature(c *gin.Context, terminal models.Terminal) bool {
+	ctx := c.Request.Context()
+
+	body, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, terminals.MaxBodyBytes))
+	if err != nil {
+		abortWithError(c, apperrors.Wrap(apperrors.InvalidRequest, err))
+		return false
+	}
+	c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+	nonce := c.GetHeader(terminals.NonceHeader)
+	if !terminals.ValidNonce(nonce) || !terminals.Verify(terminal.Secret,
+		c.GetHeader(terminals.TimestampHeader), nonce, c.Request.Method, c.Request.URL.Path, body,
+		c.GetHeader(terminals.SignatureHeader), time.Now()) {
+		h.logger.WarnContext(ctx, "terminal signature rejected", "terminal_id", terminal.ID)
+		abortWithError(c, apperrors.New(apperrors.TerminalUnauthorized).WithDetail(i18n.MsgTerminalSignature))
+		return false
+	}
+
+	fresh, err := h.useNonce(ctx, terminals.NoncesPrefix+terminal.ID.String()+"/"+nonce, []byte(c.GetHeader(terminals.TimestampHeader)))
+	if err != nil {
+		abortWithError(c, fmt.Errorf("recording terminal nonce: %w", err))
+		return false
+	}
+	if !fresh {
+		h.logger.WarnContext(ctx, "terminal request replayed", "terminal_id", terminal.ID, "nonce", nonce)
+		abortWithError(c, apperrors.New(apperrors.TerminalUnauthorized).WithDetail(i18n.MsgTerminalReplay))
+		return false
+	}
+	return true
+}
+
+// useNonce stores a terminal nonce under key and reports whether it was
+// new. Nonces are forgotten after terminals.NonceTTL; without leases they
+// are kept.
+func (h *Handler) useNonce(ctx context.Context, key string, value []byte) (bool, error) {
+	if h.leases != nil {
+		return h.leases.CreateWithTTL(ctx, key, value, terminals.NonceTTL)
+	}
+	fresh := false
+	err := h.db.Atomic(ctx, func(tx database.Tx) error {
+		fresh = tx.Get(key) == nil
+		if fresh {
+			tx.Put(key, value)
+		}
+		return nil
+	})
+	return fresh, err
+}
+
+// terminalCertified reports whether the request was made over TLS with the
+// terminal's registered client certificate. The certificate must have been
+// verified against the configured client CAs; only the client's own
+// certificate counts, not the intermediates it sent along.
+func terminalCertified(c *gin.Context, terminal models.Terminal) bool {
+	state := c.Request.TLS
+	if terminal.CertFingerprint == "" || state == nil || len(state.VerifiedChains) == 0 || len(state.PeerCertificates) == 0 {
+		return false
+	}
+	return terminals.Fingerprint(state.PeerCertificates[0]) == terminal.CertFingerprint
+}
+
+// Heartbeat godoc
+// @Summary Send a terminal heartbeat
+// @Description Called by an ATM every few seconds to stay online, with the error states it is in. The terminal's online key is kept under an etcd lease; once heartbeats stop for ttl_seconds the terminal is offline.
+// @Tags Terminal
+// @Accept json
+// @Produce json
+// @Param input body dto.HeartbeatRequest false "Error states"
+// @Success 200 {object} dto.HeartbeatResponse "Heartbeat received"
+// @Failure 400 {object} apperrors.Problem "Bad Request"
+// @Failure 401 {object} apperrors.Problem "Terminal authentication failed"
+// @Failure 500 {object} apperrors.Problem "Internal Server Error"
+// @Router /terminal/heartbeat [post]
+func (h *Handler) Heartbeat(c *gin.Context) {
+	var input dto.HeartbeatRequest
+	if c.Request.ContentLength != 0 && !bindJSON(c, &input) {
+		return
+	}
+
+	id := terminalID(c)
+	if id == nil {
+		abortWithError(c, apperrors.New(apperrors.TerminalUnauthorized).WithDetail(i18n.MsgTerminalMissing))
+		return
+	}
+
+	ctx := c.Request.Context()
+	now := time.Now().UTC()
+	ttl := terminals.HeartbeatTTL(config.GetConfig().TerminalHeartbeatTTLSeconds)
+	heartbeatKey := terminals.HeartbeatsPrefix + id.String()
+	onlineKey := terminals.OnlinePrefix + id.String()
+	stamp := []byte(now.Format(time.RFC3339Nano))
+
+	var previous models.TerminalHeartbeat
+	data, err := h.db.Get(ctx, heartbeatKey)
+	if err != nil {
+		abortWithError(c, fmt.Errorf("reading heartbeat: %w", err))
+		return
+	}
+	if data != nil {
+		if err := json.Unmarshal(data, &previous); err != nil {
+			abortWithError(c, fmt.Errorf("unmarshaling heartbeat: %w", err))
+			return
+		}
+	}
+
+	// Without leases the online key never expires, and the last heartbeat
+	// time alone decides whether the terminal is online.
+	lease := previous.LeaseID
+	if h.leases != nil {
+		if lease, err = h.leases.KeepAlive(ctx, onlineKey, stamp, lease, ttl); err != nil {
+			abortWithError(c, fmt.Errorf("renewing terminal lease: %w", err))
+			return
+		}
+	}
+
+	var hb models.TerminalHeartbeat
+	var backOnline bool
+	err = h.db.Atomic(ctx, func(tx database.Tx) error {
+		hb = models.TerminalHeartbeat{TerminalID: *id}
+		if data := tx.Get(heartbeatKey); data != nil {
+			if err := json.Unmarshal(data, &hb); err != nil {
+				return fmt.Errorf("unmarshaling heartbeat: %w", err)
+			}
+		}
+		backOnline = hb.OfflineAlerted
+		hb.LastSeenAt = now
+		hb.Errors = input.Errors
+		hb.LeaseID = lease
+		hb.OfflineAlerted = false
+		if h.leases == nil {
+			tx.Put(onlineKey, stamp)
+		}
+		return putJSON(tx, heartbeatKey, hb)
+	})
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+
+	if backOnline {
+		h.logger.InfoContext(ctx, "terminal back online", "terminal_id", id)
+	}
+	if len(hb.Errors) > 0 && !slices.Equal(hb.Errors, previous.Errors) {
+		h.logger.WarnContext(ctx, "terminal reported errors", "terminal_id", id, "errors", hb.Errors)
+	}
+	c.JSON(http.StatusOK, dto.HeartbeatResponse{ReceivedAt: now, TTLSeconds: int(ttl / time.Second)})
+}
+
+// ListTerminals godoc
+// @Summary List ATMs
+// @Description List every registered terminal with whether it is online, when it last sent a heartbeat, the error states it reported, the cash in its cassettes and the currencies it is low on. Admin only.
+// @Tags Admin
+// @Produce json
+// @Success 200 {object} dto.TerminalsResponse "Terminals"
+// @Failure 403 {object} apperrors.Problem "Forbidden"
+// @Failure 500 {object} apperrors.Problem "Internal Server Error"
+// @Router /admin/terminals [get]
+func (h *Handler) ListTerminals(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	lists := map[string]map[string][]byte{}
+	for _, prefix := range []string{terminalsPrefix, terminals.HeartbeatsPrefix, terminals.OnlinePrefix, cassettesPrefix} {
+		data, err := h.db.List(ctx, prefix)
+		if err != nil {
+			abortWithError(c, fmt.Errorf("listing %s: %w", prefix, err))
+			return
+		}
+		lists[prefix] = data
+	}
+
+	conf := config.GetConfig()
+	ttl := terminals.HeartbeatTTL(conf.TerminalHeartbeatTTLSeconds)
+	now := time.Now()
+
+	views := make([]dto.TerminalStatusView, 0, len(lists[terminalsPrefix]))
+	for key, data := range lists[terminalsPrefix] {
+		var terminal models.Terminal
+		if err := json.Unmarshal(data, &terminal); err != nil {
+			abortWithError(c, fmt.Errorf("unmarshaling terminal %s: %w", strings.TrimPrefix(key, terminalsPrefix), err))
+			return
+		}
+		id := terminal.ID.String()
+		view := dto.TerminalStatusView{TerminalView: dto.NewTerminalView(terminal), Cash: []dto.NotesView{}}
+
+		var hb *models.TerminalHeartbeat
+		if data := lists[terminals.HeartbeatsPrefix][terminals.HeartbeatsPrefix+id]; data != nil {
+			hb = &models.TerminalHeartbeat{}
+			if err := json.Unmarshal(data, hb); err != nil {
+				abortWithError(c, fmt.Errorf("unmarshaling heartbeat %s: %w", id, err))
+				return
+			}
+			view.LastSeenAt = &hb.LastSeenAt
+			view.Errors = hb.Errors
+		}
+		_, present := lists[terminals.OnlinePrefix][terminals.OnlinePrefix+id]
+		view.Online = terminal.Status == models.TerminalActive && terminals.Online(hb, present, ttl, now)
+
+		inventory := models.CashInventory{TerminalID: terminal.ID}
+		if data := lists[cassettesPrefix][cassettesKey(terminal.ID)]; data != nil {
+			if err := json.Unmarshal(data, &inventory); err != nil {
+				abortWithError(c, fmt.Errorf("unmarshaling cash inventory %s: %w", id, err))
+				return
+			}
+		}
+		view.Cash = dto.NewCassettesResponse(inventory).Cassettes
+		view.LowCash = terminals.LowCash(inventory, conf.LowCashThresholds)
+
+		views = append(views, view)
+	}
+	sort.Slice(views, func(i, j int) bool {
+		if views[i].Branch != views[j].Branch {
+			return views[i].Branch < views[j].Branch
+		}
+		return views[i].Location < views[j].Location
+	})
+
+	c.JSON(http.StatusOK, dto.TerminalsResponse{Terminals: views})
+}
@@ -0,0 +1,165 @@
+package handlers
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"newapiprojet/apperrors"
+	"newapiprojet/database/memdb"
+	"newapiprojet/models"
+	"newapiprojet/terminals"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// newTerminalRouter registers an active terminal and returns a router that
+// answers 204 to requests the terminal authenticates.
+func newTerminalRouter(t *testing.T, h *Handler, db *memdb.DB, fingerprint string) (*gin.Engine, models.Terminal) {
+	t.Helper()
+	secret, err := terminals.NewSecret()
+	if err != nil {
+		t.Fatal(err)
+	}
+	terminal := models.Terminal{ID: uuid.New(), Status: models.TerminalActive, Secret: secret, CertFingerprint: fingerprint}
+	put(t, db, terminalKey(terminal.ID), terminal)
+
+	r := newTestRouter(uuid.New())
+	r.POST("/terminal/ping", h.AuthenticateTerminal(), func(c *gin.Context) {
+		if id := terminalID(c); id == nil || *id != terminal.ID {
+			t.Errorf("terminal ID = %v, want %s", id, terminal.ID)
+		}
+		c.Status(http.StatusNoContent)
+	})
+	return r, terminal
+}
+
+func newClientCert(t *testing.T) *x509.Certificate {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "atm"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cert
+}
+
+func TestAuthenticateTerminalCertificate(t *testing.T) {
+	cert := newClientCert(t)
+	other := newClientCert(t)
+	tests := []struct {
+		name  string
+		state *tls.ConnectionState
+		want  int
+	}{
+		{"verified certificate", &tls.ConnectionState{PeerCertificates: []*x509.Certificate{cert}, VerifiedChains: [][]*x509.Certificate{{cert}}}, http.StatusNoContent},
+		{"plain HTTP", nil, http.StatusUnauthorized},
+		{"not verified against the client CAs", &tls.ConnectionState{PeerCertificates: []*x509.Certificate{cert}}, http.StatusUnauthorized},
+		{"another certificate", &tls.ConnectionState{PeerCertificates: []*x509.Certificate{other}, VerifiedChains: [][]*x509.Certificate{{other}}}, http.StatusUnauthorized},
+		// Only the leaf identifies the client; the registered certificate
+		// sent as an intermediate proves nothing.
+		{"registered certificate not the leaf", &tls.ConnectionState{PeerCertificates: []*x509.Certificate{other, cert}, VerifiedChains: [][]*x509.Certificate{{other}}}, http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h, db := newTestHandler(t)
+			r, terminal := newTerminalRouter(t, h, db, terminals.Fingerprint(cert))
+
+			// Unsigned: only the certificate can authenticate it.
+			req := httptest.NewRequest(http.MethodPost, "/terminal/ping", nil)
+			req.Header.Set(terminals.IDHeader, terminal.ID.String())
+			req.TLS = tt.state
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+			if w.Code != tt.want {
+				t.Errorf("status = %d %s, want %d", w.Code, w.Body, tt.want)
+			}
+		})
+	}
+}
+
+// signedRequest returns a request signed as terminal with nonce.
+func signedRequest(terminal models.Terminal, nonce, body string) *http.Request {
+	req := httptest.NewRequest(http.MethodPost, "/terminal/ping", strings.NewReader(body))
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	req.Header.Set(terminals.IDHeader, terminal.ID.String())
+	req.Header.Set(terminals.TimestampHeader, timestamp)
+	req.Header.Set(terminals.NonceHeader, nonce)
+	req.Header.Set(terminals.SignatureHeader, terminals.Sign(terminal.Secret, timestamp, nonce, http.MethodPost, "/terminal/ping", []byte(body)))
+	return req
+}
+
+func TestAuthenticateTerminalRejectsReplay(t *testing.T) {
+	tests := []struct {
+		name   string
+		leases bool
+	}{
+		{"with leases", true},
+		{"without leases", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h, db := newTestHandler(t)
+			if !tt.leases {
+				h.leases = nil
+			}
+			r, terminal := newTerminalRouter(t, h, db, "")
+
+			nonce := uuid.NewString()
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, signedRequest(terminal, nonce, `{"n":1}`))
+			if w.Code != http.StatusNoContent {
+				t.Fatalf("first request = %d %s", w.Code, w.Body)
+			}
+
+			w = httptest.NewRecorder()
+			r.ServeHTTP(w, signedRequest(terminal, nonce, `{"n":1}`))
+			if w.Code != http.StatusUnauthorized || problemCode(t, w) != apperrors.TerminalUnauthorized {
+				t.Errorf("replayed request = %d %s, want it rejected", w.Code, w.Body)
+			}
+
+			w = httptest.NewRecorder()
+			r.ServeHTTP(w, signedRequest(terminal, uuid.NewString(), `{"n":1}`))
+			if w.Code != http.StatusNoContent {
+				t.Errorf("request with a new nonce = %d %s", w.Code, w.Body)
+			}
+		})
+	}
+}
+
+func TestAuthenticateTerminalBodyLimit(t *testing.T) {
+	h, db := newTestHandler(t)
+	r, terminal := newTerminalRouter(t, h, db, "")
+
+	body := `{"pad":"` + strings.Repeat("x", terminals.MaxBodyBytes) + `"}`
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, signedRequest(terminal, uuid.NewString(), body))
+	if w.Code != http.StatusBadRequest || problemCode(t, w) != apperrors.InvalidRequest {
+		t.Errorf("oversized request = %d, want it rejected", w.Code)
+	}
+}
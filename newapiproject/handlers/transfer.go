@@ -0,0 +1,210 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"newapiprojet/apperrors"
+	"newapiprojet/database"
+	"newapiprojet/dto"
+	"newapiprojet/exchange"
+	"newapiprojet/fees"
+	"newapiprojet/i18n"
+	"newapiprojet/ledger"
+	"newapiprojet/metrics"
+	"newapiprojet/models"
+	"newapiprojet/money"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// Transfer godoc
+// @Summary Transfer money between accounts
+// @Description Move money from one of the user's accounts to any account. Between accounts in different currencies the amount is converted at the current exchange rate. Fees are charged to the source account.
+// @Tags Account
+// @Accept json
+// @Produce json
+// @Param input body dto.TransferRequest true "Transfer details"
+// @Param X-ATM-Network header string false "foreign at another bank's ATM"
+// @Success 200 {object} dto.TransferResponse "Transfer successful"
+// @Failure 400 {object} apperrors.Problem "Bad Request"
+// @Failure 403 {object} apperrors.Problem "Forbidden"
+// @Failure 404 {object} apperrors.Problem "Account not found"
+// @Failure 422 {object} apperrors.Problem "No exchange rate"
+// @Failure 500 {object} apperrors.Problem "Internal Server Error"
+// @Router /account/transfer [post]
+func (h *Handler) Transfer(c *gin.Context) {
+	var input dto.TransferRequest
+
+	if !bindJSON(c, &input) {
+		return
+	}
+
+	userID, exists := c.Get("userID")
+	if !exists {
+		abortWithError(c, apperrors.New(apperrors.Unauthorized))
+		return
+	}
+
+	userIDUUID, ok := userID.(uuid.UUID)
+	if !ok {
+		abortWithError(c, errors.New("user ID in context is not a UUID"))
+		return
+	}
+
+	if err := checkCardScope(c, input.FromAccountID); err != nil {
+		abortWithError(c, err)
+		return
+	}
+
+	ctx := c.Request.Context()
+
+	var result transferResult
+	err := h.db.Atomic(ctx, func(tx database.Tx) error {
+		var err error
+		result, err = h.executeTransfer(ctx, tx, transferRequest{
+			FromAccountID: input.FromAccountID,
+			ToAccountID:   input.ToAccountID,
+			UserID:        userIDUUID,
+			Amount:        input.Amount,
+			Foreign:       foreignATM(c),
+		})
+		return err
+	})
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+	transfer, from, charged := result.Transfer, result.From, result.Fees
+
+	metrics.TransfersTotal.Inc()
+	metrics.AmountMovedTotal.WithLabelValues("transfer", string(transfer.Debited.Currency())).Add(transfer.Debited.Float64())
+
+	h.logger.InfoContext(ctx, "transfer completed", "transfer_id", transfer.ID, "from_account_id", from.ID, "to_account_id", transfer.ToAccountID)
+
+	lang := i18n.FromContext(ctx)
+	c.JSON(http.StatusOK, dto.TransferResponse{
+		Message:          i18n.T(lang, i18n.MsgTransferSuccessful),
+		TransferID:       transfer.ID,
+		Debited:          transfer.Debited,
+		Credited:         transfer.Credited,
+		Balance:          from.Balance,
+		BalanceFormatted: i18n.FormatMoney(lang, from.Balance),
+		Date:             i18n.FormatDate(lang, transfer.TransferDate),
+		Conversion:       dto.NewConversionView(transfer.Conversion),
+		Fees:             dto.NewFeeViews(charged),
+	})
+}
+
+// transferRequest is a transfer from an account owned by UserID, made
+// directly or by a standing order.
+type transferRequest struct {
+	FromAccountID   uuid.UUID
+	ToAccountID     uuid.UUID
+	UserID          uuid.UUID
+	Amount          money.Money
+	Foreign         bool
+	StandingOrderID *uuid.UUID
+}
+
+type transferResult struct {
+	Transfer models.Transfer
+	From     models.Account // after the transfer and its fees
+	Fees     []models.Fee
+}
+
+// executeTransfer moves req.Amount between the accounts as part of tx and
+// stores the transfer. The amount must be in the currency of one of the two
+// accounts; the other leg is converted. Fees are charged to the source.
+func (h *Handler) executeTransfer(ctx context.Context, tx database.Tx, req transferRequest) (transferResult, error) {
+	var result transferResult
+	if req.FromAccountID == req.ToAccountID {
+		return result, apperrors.New(apperrors.InvalidRequest).WithDetail(i18n.MsgTransferSameAccount)
+	}
+
+	from, err := getOwnedAccount(tx, req.FromAccountID, req.UserID)
+	if err != nil {
+		return result, err
+	}
+	to, err := getAccount(tx, req.ToAccountID)
+	if err != nil {
+		return result, err
+	}
+	if err := ledger.CanDebit(from); err != nil {
+		return result, statusError(err)
+	}
+	if err := ledger.CanCredit(to); err != nil {
+		return result, statusError(err)
+	}
+
+	transfer := models.Transfer{
+		ID:              uuid.New(),
+		FromAccountID:   from.ID,
+		ToAccountID:     to.ID,
+		Debited:         req.Amount,
+		Credited:        req.Amount,
+		TransferDate:    time.Now(),
+		StandingOrderID: req.StandingOrderID,
+	}
+
+	// The amount is fixed in whichever account's currency it is given;
+	// the other leg is converted.
+	fromCurrency, toCurrency := from.Balance.Currency(), to.Balance.Currency()
+	switch req.Amount.Currency() {
+	case fromCurrency:
+		if toCurrency != fromCurrency {
+			conversion, err := h.rates.Convert(ctx, req.Amount, toCurrency, exchange.Credit)
+			if err != nil {
+				return result, exchangeError(err)
+			}
+			transfer.Credited = conversion.To
+			transfer.Conversion = &conversion
+		}
+	case toCurrency:
+		conversion, err := h.rates.Convert(ctx, req.Amount, fromCurrency, exchange.Debit)
+		if err != nil {
+			return result, exchangeError(err)
+		}
+		transfer.Debited = conversion.To
+		transfer.Conversion = &conversion
+	default:
+		return result, apperrors.New(apperrors.CurrencyMismatch).WithDetail(i18n.MsgTransferCurrency)
+	}
+
+	if err := ledger.Debit(tx, &from, transfer.Debited, transfer.TransferDate); err != nil {
+		return result, moneyError(err)
+	}
+	if err := ledger.Credit(tx, &to, transfer.Credited, transfer.TransferDate); err != nil {
+		return result, moneyError(err)
+	}
+
+	charged, err := h.fees.Charge(tx, &from, fees.Operation{
+		Type:          models.FeeTransfer,
+		TransactionID: transfer.ID,
+		Amount:        transfer.Debited,
+		Foreign:       req.Foreign,
+		At:            transfer.TransferDate,
+	})
+	if err != nil {
+		return result, moneyError(err)
+	}
+	transfer.FeeIDs = fees.IDs(charged)
+	// Standing orders run without the customer, so they do not count as
+	// activity.
+	if req.StandingOrderID == nil {
+		ledger.RecordActivity(&from, transfer.TransferDate)
+	}
+
+	if err := putJSON(tx, accountKey(from.ID), from); err != nil {
+		return result, err
+	}
+	if err := putJSON(tx, accountKey(to.ID), to); err != nil {
+		return result, err
+	}
+	if err := putJSON(tx, "transfers/"+transfer.ID.String(), transfer); err != nil {
+		return result, err
+	}
+	return transferResult{Transfer: transfer, From: from, Fees: charged}, nil
+}
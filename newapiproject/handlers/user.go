@@ -3,10 +3,16 @@ package handlers
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
+	"newapiprojet/apperrors"
+	"newapiprojet/database"
+	"newapiprojet/dto"
+	"newapiprojet/i18n"
+	"newapiprojet/ledger"
 	"newapiprojet/models"
-	"time"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
@@ -14,81 +20,100 @@ import (
 
 // DeleteUser godoc
 // @Summary Delete a user
+// @Description Delete the user. All of the user's accounts must be closed first; closed accounts and their history are kept.
 // @Scheme http
 // @Tags User
 // @Produce json
 // @Accept json
 // @Param id path string true "User ID"
 // @Success 204 {string} string "No Content"
-// @Failure 400 {string} string "Bad Request"
-// @Failure 403 {string} string "Forbidden"
-// @Failure 404 {string} string "User not found"
-// @Failure 500 {string} string "Internal Server Error"
-// @Router /user/{id} [delete]
+// @Failure 400 {object} apperrors.Problem "Bad Request"
+// @Failure 403 {object} apperrors.Problem "Forbidden"
+// @Failure 404 {object} apperrors.Problem "User not found"
+// @Failure 409 {object} apperrors.Problem "User has open accounts"
+// @Failure 500 {object} apperrors.Problem "Internal Server Error"
+// @Router /user/delete/{id} [delete]
 func (h *Handler) DeleteUser(c *gin.Context) {
-	userIDParam := c.Param("id") // Parametrelerden kullanıcı ID'sini al
-
-	if userIDParam == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Kullanıcı ID'si boş"})
-		return
-	}
-
-	userUUID, err := uuid.Parse(userIDParam)
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID format"})
+	var params dto.IDParam // Parametrelerden kullanıcı ID'sini al
+	if !bindURI(c, &params) {
 		return
 	}
+	userUUID := params.UUID()
+	ctx := c.Request.Context()
 
 	userID, exists := c.Get("userID")
 	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Yetkilendirme hatası"})
+		abortWithError(c, apperrors.New(apperrors.Unauthorized))
 		return
 	}
 
 	userIDUUID, ok := userID.(uuid.UUID)
 	if !ok {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "User ID dönüştürme hatası"})
+		abortWithError(c, errors.New("user ID in context is not a UUID"))
 		return
 	}
 
-	fmt.Printf("userUUID: %s, userIDUUID: %s\n", userUUID.String(), userIDUUID.String())
+	h.logger.DebugContext(ctx, "delete user requested", "path_user_id", userUUID, "user_id", userIDUUID)
 
 	if userUUID != userIDUUID {
-		c.JSON(http.StatusForbidden, gin.H{"error": "Bu kullanıcıyı silme izniniz yok"})
-		return
-	}
-
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	defer cancel()
-
-	resp, err := h.db.Get(ctx, "users/"+userUUID.String())
-	if err != nil || resp == nil {
-		fmt.Println("User not found or error retrieving user data:", err)
-		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		abortWithError(c, apperrors.New(apperrors.Forbidden).WithDetail(i18n.MsgUserDeleteDenied))
 		return
 	}
 
-	var user models.User
-	err = json.Unmarshal(resp, &user)
+	accounts, err := h.userAccounts(ctx, userUUID)
 	if err != nil {
-		fmt.Println("Error unmarshaling user data:", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Unable to unmarshal user data: " + err.Error()})
+		abortWithError(c, err)
 		return
 	}
 
-	err = h.db.Delete(ctx, "users/"+userUUID.String())
+	err = h.db.Atomic(ctx, func(tx database.Tx) error {
+		data := tx.Get("users/" + userUUID.String())
+		if data == nil {
+			return apperrors.New(apperrors.UserNotFound)
+		}
+		var user models.User
+		if err := json.Unmarshal(data, &user); err != nil {
+			return fmt.Errorf("unmarshaling user data: %w", err)
+		}
+		// The accounts are read again so their status is checked as of
+		// the delete.
+		for _, id := range accounts {
+			account, err := getAccount(tx, id)
+			if err != nil {
+				return err
+			}
+			if ledger.Status(account) != models.AccountClosed {
+				return apperrors.New(apperrors.Conflict).WithDetail(i18n.MsgUserHasOpenAccounts)
+			}
+		}
+		tx.Delete("users/" + userUUID.String())
+		tx.Delete(usernameKey(user.Username))
+		return nil
+	})
 	if err != nil {
-		fmt.Println("Error deleting user data from etcd:", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Unable to delete user data from etcd: " + err.Error()})
+		abortWithError(c, err)
 		return
 	}
 
-	err = h.db.Delete(ctx, "accounts/"+userUUID.String())
+	h.logger.InfoContext(ctx, "user deleted", "user_id", userUUID)
+	c.Status(http.StatusNoContent)
+}
+
+// userAccounts returns the IDs of every account of userID, closed or not.
+func (h *Handler) userAccounts(ctx context.Context, userID uuid.UUID) ([]uuid.UUID, error) {
+	data, err := h.db.List(ctx, "accounts/")
 	if err != nil {
-		fmt.Println("Error deleting account data from etcd:", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Unable to delete account data from etcd: " + err.Error()})
-		return
+		return nil, fmt.Errorf("listing accounts: %w", err)
 	}
-
-	c.JSON(http.StatusNoContent, nil)
+	var ids []uuid.UUID
+	for key, value := range data {
+		var account models.Account
+		if err := json.Unmarshal(value, &account); err != nil {
+			return nil, fmt.Errorf("unmarshaling account %s: %w", strings.TrimPrefix(key, "accounts/"), err)
+		}
+		if account.UserID == userID {
+			ids = append(ids, account.ID)
+		}
+	}
+	return ids, nil
 }
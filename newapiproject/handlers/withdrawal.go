@@ -1,9 +1,16 @@
 package handlers
 
 import (
-	"context"
-	"encoding/json"
+	"errors"
 	"net/http"
+	"newapiprojet/apperrors"
+	"newapiprojet/database"
+	"newapiprojet/dto"
+	"newapiprojet/exchange"
+	"newapiprojet/fees"
+	"newapiprojet/i18n"
+	"newapiprojet/ledger"
+	"newapiprojet/metrics"
 	"newapiprojet/models"
 	"time"
 
@@ -13,101 +20,135 @@ import (
 
 // Withdrawal godoc
 // @Summary Withdraw money from an account
-// @Description Withdraw money from an account
+// @Description Withdraw money from an account. Amounts in another currency are converted at the current exchange rate. The balance may go negative up to the account's overdraft limit. The account's withdrawal limits are checked against the amount in the account currency. Fees from the account currency's fee schedule are charged with the withdrawal. At an ATM the amount must be payable from the notes in its cassettes; the response lists the notes to dispense.
 // @Tags Account
 // @Accept json
 // @Produce json
-// @Param input body struct{ AccountID uuid.UUID `json:"accountID"`; WithdrawalAmount int `json:"withdrawalAmount"` } true "Withdrawal details"
-// @Success 200 {string} string "Withdrawal successful"
-// @Failure 400 {string} string "Bad Request"
-// @Failure 404 {string} string "Account not found"
-// @Failure 403 {string} string "Forbidden"
-// @Failure 500 {string} string "Internal Server Error"
+// @Param input body dto.WithdrawalRequest true "Withdrawal details"
+// @Param X-ATM-Network header string false "foreign at another bank's ATM"
+// @Success 200 {object} dto.TransactionResponse "Withdrawal successful"
+// @Failure 400 {object} apperrors.Problem "Bad Request"
+// @Failure 404 {object} apperrors.Problem "Account not found"
+// @Failure 403 {object} apperrors.Problem "Forbidden"
+// @Failure 422 {object} apperrors.Problem "No exchange rate, withdrawal limit exceeded or amount cannot be dispensed"
+// @Failure 500 {object} apperrors.Problem "Internal Server Error"
 // @Router /account/withdrawal [post]
 func (h *Handler) Withdrawal(c *gin.Context) {
-	var input struct {
-		AccountID        uuid.UUID `json:"accountID"`
-		WithdrawalAmount int       `json:"withdrawalAmount"`
-	}
+	var input dto.WithdrawalRequest
 
-	if err := c.BindJSON(&input); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+	if !bindJSON(c, &input) {
 		return
 	}
 
 	userID, exists := c.Get("userID")
 	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Authentication error"})
+		abortWithError(c, apperrors.New(apperrors.Unauthorized))
 		return
 	}
 
 	userIDUUID, ok := userID.(uuid.UUID)
 	if !ok {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "User ID conversion error"})
-		return
-	}
-
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	defer cancel()
-
-	resp, err := h.db.Get(ctx, "accounts/"+input.AccountID.String())
-	if err != nil || resp == nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Account not found"})
-		return
-	}
-
-	var account models.Account
-	err = json.Unmarshal(resp, &account)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Unable to unmarshal account data"})
-		return
-	}
-
-	if account.UserID != userIDUUID {
-		c.JSON(http.StatusForbidden, gin.H{"error": "Access denied"})
-		return
-	}
-
-	if account.Balance < input.WithdrawalAmount {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Insufficient balance"})
-		return
-	}
-
-	account.Balance -= input.WithdrawalAmount
-
-	accountData, err := json.Marshal(account)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Unable to marshal account data"})
+		abortWithError(c, errors.New("user ID in context is not a UUID"))
 		return
 	}
 
-	err = h.db.Put(context.Background(), "accounts/"+account.ID.String(), accountData)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Unable to update account data"})
+	if err := checkCardScope(c, input.AccountID); err != nil {
+		abortWithError(c, err)
 		return
 	}
 
-	withdrawal := models.Withdrawal{
-		ID:               uuid.New(),
-		AccountID:        account.ID,
-		WithdrawalAmount: input.WithdrawalAmount,
-		WithdrawalDate:   time.Now(),
-	}
+	ctx := c.Request.Context()
 
-	withdrawalData, err := json.Marshal(withdrawal)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Unable to marshal withdrawal data"})
-		return
-	}
+	foreign := foreignATM(c)
 
-	err = h.db.Put(context.Background(), "withdrawals/"+withdrawal.ID.String(), withdrawalData)
+	var account models.Account
+	var withdrawal models.Withdrawal
+	var charged []models.Fee
+	err := h.db.Atomic(ctx, func(tx database.Tx) error {
+		var err error
+		account, err = getOwnedAccount(tx, input.AccountID, userIDUUID)
+		if err != nil {
+			return err
+		}
+		if err := ledger.CanDebit(account); err != nil {
+			return statusError(err)
+		}
+
+		withdrawal = models.Withdrawal{
+			ID:               uuid.New(),
+			AccountID:        account.ID,
+			WithdrawalAmount: input.WithdrawalAmount,
+			WithdrawalDate:   time.Now(),
+			TerminalID:       terminalID(c),
+		}
+
+		debit := input.WithdrawalAmount
+		if debit.Currency() != account.Balance.Currency() {
+			conversion, err := h.rates.Convert(ctx, debit, account.Balance.Currency(), exchange.Debit)
+			if err != nil {
+				return exchangeError(err)
+			}
+			debit = conversion.To
+			withdrawal.Conversion = &conversion
+		}
+
+		if err := h.limits.Reserve(tx, account, debit); err != nil {
+			return limitError(err)
+		}
+
+		if err := ledger.Debit(tx, &account, debit, withdrawal.WithdrawalDate); err != nil {
+			return moneyError(err)
+		}
+
+		charged, err = h.fees.Charge(tx, &account, fees.Operation{
+			Type:          models.FeeWithdrawal,
+			TransactionID: withdrawal.ID,
+			Amount:        debit,
+			Foreign:       foreign,
+			At:            withdrawal.WithdrawalDate,
+		})
+		if err != nil {
+			return moneyError(err)
+		}
+		withdrawal.FeeIDs = fees.IDs(charged)
+
+		withdrawal.Notes, err = h.dispense(c, tx, input.WithdrawalAmount, withdrawal.WithdrawalDate)
+		if err != nil {
+			return err
+		}
+		ledger.RecordActivity(&account, withdrawal.WithdrawalDate)
+
+		if err := recordTerminalCash(tx, withdrawal.TerminalID, models.TerminalCash{
+			TransactionID:   withdrawal.ID,
+			TransactionType: models.ReversibleWithdrawal,
+			Amount:          withdrawal.WithdrawalAmount,
+			At:              withdrawal.WithdrawalDate,
+		}); err != nil {
+			return err
+		}
+		if err := putJSON(tx, accountKey(account.ID), account); err != nil {
+			return err
+		}
+		return putJSON(tx, "withdrawals/"+withdrawal.ID.String(), withdrawal)
+	})
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Unable to store withdrawal data"})
+		abortWithError(c, err)
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{
-		"message": "Withdrawal successful",
-		"balance": account.Balance,
+	metrics.WithdrawalsTotal.Inc()
+	countDispensed(withdrawal.Notes)
+	metrics.AmountMovedTotal.WithLabelValues("withdrawal", string(input.WithdrawalAmount.Currency())).Add(input.WithdrawalAmount.Float64())
+
+	lang := i18n.FromContext(ctx)
+	c.JSON(http.StatusOK, dto.TransactionResponse{
+		Message:          i18n.T(lang, i18n.MsgWithdrawalSuccessful),
+		TransactionID:    withdrawal.ID,
+		Balance:          account.Balance,
+		BalanceFormatted: i18n.FormatMoney(lang, account.Balance),
+		Date:             i18n.FormatDate(lang, withdrawal.WithdrawalDate),
+		Conversion:       dto.NewConversionView(withdrawal.Conversion),
+		Fees:             dto.NewFeeViews(charged),
+		Notes:            dto.NewNotesViews(withdrawal.Notes),
 	})
 }
@@ -0,0 +1,253 @@
+package i18n
+
+// Message keys that are not error codes. Error titles are keyed by their
+// apperrors.Code value.
+const (
+	MsgAuthHeaderMissing     = "AUTH_HEADER_MISSING"
+	MsgAuthSchemeInvalid     = "AUTH_SCHEME_INVALID"
+	MsgInvalidBody           = "INVALID_BODY"
+	MsgAccountAccessDenied   = "ACCOUNT_ACCESS_DENIED"
+	MsgUserDeleteDenied      = "USER_DELETE_DENIED"
+	MsgUserHasOpenAccounts   = "USER_HAS_OPEN_ACCOUNTS"
+	MsgValidationInvalid     = "VALIDATION_INVALID"
+	MsgDepositSuccessful     = "DEPOSIT_SUCCESSFUL"
+	MsgWithdrawalSuccessful  = "WITHDRAWAL_SUCCESSFUL"
+	MsgBalanceInquirySuccess = "BALANCE_INQUIRY_SUCCESSFUL"
+	MsgPINUpdated            = "PIN_UPDATED"
+	MsgTransferSuccessful    = "TRANSFER_SUCCESSFUL"
+	MsgTransferCurrency      = "TRANSFER_CURRENCY"
+	MsgTransferSameAccount   = "TRANSFER_SAME_ACCOUNT"
+	MsgLimitPerTransaction   = "LIMIT_PER_TRANSACTION"
+	MsgLimitDaily            = "LIMIT_DAILY"
+	MsgLimitDailyCount       = "LIMIT_DAILY_COUNT"
+	MsgLimitNegative         = "LIMIT_NEGATIVE"
+	MsgFeeScheduleInvalid    = "FEE_SCHEDULE_INVALID"
+	MsgStandingOrderState    = "STANDING_ORDER_STATE"
+	MsgStandingOrderNoRun    = "STANDING_ORDER_NO_RUN"
+	MsgAlreadyReversed       = "ALREADY_REVERSED"
+	MsgReversalSuccessful    = "REVERSAL_SUCCESSFUL"
+	MsgAccountFrozen         = "ACCOUNT_FROZEN"
+	MsgAccountDormant        = "ACCOUNT_DORMANT"
+	MsgAccountClosed         = "ACCOUNT_CLOSED"
+	MsgAccountTransition     = "ACCOUNT_TRANSITION"
+	MsgAccountBalanceNotZero = "ACCOUNT_BALANCE_NOT_ZERO"
+	MsgHoldState             = "HOLD_STATE"
+	MsgHoldCaptureExceeds    = "HOLD_CAPTURE_EXCEEDS"
+	MsgHoldCurrency          = "HOLD_CURRENCY"
+	MsgHoldPlaced            = "HOLD_PLACED"
+	MsgHoldCaptured          = "HOLD_CAPTURED"
+	MsgHoldReleased          = "HOLD_RELEASED"
+	MsgCardBlocked           = "CARD_BLOCKED"
+	MsgCardExpired           = "CARD_EXPIRED"
+	MsgCardAccountOwner      = "CARD_ACCOUNT_OWNER"
+	MsgCardScope             = "CARD_SCOPE"
+	MsgCardSession           = "CARD_SESSION"
+	MsgCardSessionTerminal   = "CARD_SESSION_TERMINAL"
+	MsgTerminalMissing       = "TERMINAL_MISSING"
+	MsgTerminalSignature     = "TERMINAL_SIGNATURE"
+	MsgTerminalInactive      = "TERMINAL_INACTIVE"
+	MsgTerminalReplay        = "TERMINAL_REPLAY"
+	MsgDispenseNotes         = "DISPENSE_NOTES"
+	MsgDispenseTooManyNotes  = "DISPENSE_TOO_MANY_NOTES"
+	MsgCassetteDuplicate     = "CASSETTE_DUPLICATE"
+)
+
+var catalog = map[Language]map[string]string{
+	English: {
+		// Error titles
+		"INVALID_REQUEST":          "Invalid request",
+		"VALIDATION_FAILED":        "Request validation failed",
+		"UNAUTHORIZED":             "Authentication required",
+		"TOKEN_INVALID":            "Token is not valid",
+		"INVALID_CREDENTIALS":      "Invalid credentials",
+		"FORBIDDEN":                "Access denied",
+		"USER_NOT_FOUND":           "User not found",
+		"ACCOUNT_NOT_FOUND":        "Account not found",
+		"ACCOUNT_UNAVAILABLE":      "The account cannot be used for this operation",
+		"STANDING_ORDER_NOT_FOUND": "Standing order not found",
+		"TRANSACTION_NOT_FOUND":    "Transaction not found",
+		"HOLD_NOT_FOUND":           "Hold not found",
+		"CARD_NOT_FOUND":           "Card not found",
+		"CARD_UNAVAILABLE":         "The card cannot be used",
+		"PIN_LOCKED":               "Too many wrong PINs; the card is blocked",
+		"TERMINAL_NOT_FOUND":       "Terminal not found",
+		"TERMINAL_UNAUTHORIZED":    "Terminal authentication failed",
+		"CANNOT_DISPENSE":          "The ATM cannot dispense this amount",
+		"USERNAME_TAKEN":           "Username already exists",
+		"CONFLICT":                 "The request conflicts with the current state of the resource",
+		"INVALID_PHONE_NUMBER":     "Invalid phone number",
+		"INVALID_PIN_FORMAT":       "Invalid PIN format",
+		"PIN_MISMATCH":             "Old PIN does not match the current PIN",
+		"INVALID_AMOUNT":           "Invalid amount",
+		"CURRENCY_MISMATCH":        "Amount currency does not match the account currency",
+		"RATE_UNAVAILABLE":         "No exchange rate is available for this currency pair",
+		"INVALID_RATE":             "Invalid exchange rate",
+		"INSUFFICIENT_FUNDS":       "Insufficient balance",
+		"LIMIT_EXCEEDED":           "Withdrawal limit exceeded",
+		"RATE_LIMITED":             "Request limit exceeded",
+		"TIMEOUT":                  "Request timed out",
+		"INTERNAL_ERROR":           "Internal server error",
+
+		// Details and responses
+		MsgAuthHeaderMissing:   "Authorization header is missing",
+		MsgAuthSchemeInvalid:   "Authorization header must use the Bearer scheme",
+		MsgInvalidBody:         "Invalid request body",
+		MsgAccountAccessDenied: "You do not have access to this account",
+		MsgUserDeleteDenied:    "You are not allowed to delete this user",
+		MsgUserHasOpenAccounts: "Close all of your accounts before deleting the user",
+
+		// Validation rules, keyed as VALIDATION_<tag>
+		MsgValidationInvalid:     "Value is not valid",
+		"VALIDATION_REQUIRED":    "This field is required",
+		"VALIDATION_UUID":        "Must be a valid UUID",
+		"VALIDATION_E164":        "Must be a phone number in E.164 format, e.g. +905321234567",
+		"VALIDATION_PIN":         "PIN must be 4 digits and not repeated or sequential (e.g. 1111, 1234)",
+		"VALIDATION_USERNAME":    "Username must be 3-30 letters, digits, '.' or '_' and start with a letter",
+		"VALIDATION_AMOUNT":      "Amount must be greater than zero and within the transaction limit",
+		"VALIDATION_MAX":         "Must be at most {param} characters",
+		"VALIDATION_NEFIELD":     "Must differ from {param}",
+		"VALIDATION_ONEOF":       "Must be one of: {param}",
+		"VALIDATION_CURRENCY":    "Must be a supported ISO 4217 currency code",
+		"VALIDATION_GTE":         "Must be at least {param}",
+		"VALIDATION_LTE":         "Must be at most {param}",
+		"VALIDATION_CRON":        "Must be a cron expression such as \"0 9 1 * *\" or @monthly",
+		"VALIDATION_PAN":         "Must be a valid card number",
+		MsgDepositSuccessful:     "Deposit successful",
+		MsgWithdrawalSuccessful:  "Withdrawal successful",
+		MsgBalanceInquirySuccess: "Balance inquiry successful",
+		MsgPINUpdated:            "PIN updated successfully",
+		MsgTransferSuccessful:    "Transfer successful",
+		MsgTransferCurrency:      "The amount must be in the currency of the source or the destination account",
+		MsgTransferSameAccount:   "Source and destination accounts must differ",
+		MsgLimitPerTransaction:   "The amount is above the per-transaction withdrawal limit",
+		MsgLimitDaily:            "The amount is above what is left of today's withdrawal limit",
+		MsgLimitDailyCount:       "The daily number of withdrawals has been reached",
+		MsgLimitNegative:         "Limits must not be negative",
+		MsgFeeScheduleInvalid:    "Fee rules need unique names, non-negative fees and a positive maximum",
+		MsgStandingOrderState:    "Cancelled standing orders cannot be changed",
+		MsgStandingOrderNoRun:    "The schedule never comes due",
+		MsgAlreadyReversed:       "This transaction has already been reversed",
+		MsgReversalSuccessful:    "Transaction reversed",
+		MsgAccountFrozen:         "The account is frozen; money can be paid in but not taken out",
+		MsgAccountDormant:        "The account is dormant; ask the bank to reactivate it",
+		MsgAccountClosed:         "The account is closed",
+		MsgAccountTransition:     "The account cannot change to this status",
+		MsgAccountBalanceNotZero: "Only accounts with a zero balance and no holds can be closed",
+		MsgHoldState:             "The hold has already been captured, released or has expired",
+		MsgHoldCaptureExceeds:    "The capture amount cannot be more than the hold",
+		MsgHoldCurrency:          "The amount must be in the account currency",
+		MsgHoldPlaced:            "Hold placed",
+		MsgHoldCaptured:          "Hold captured",
+		MsgHoldReleased:          "Hold released",
+		MsgCardBlocked:           "The card is blocked",
+		MsgCardExpired:           "The card has expired",
+		MsgCardAccountOwner:      "Cards can only be linked to the holder's own accounts that are not closed",
+		MsgCardScope:             "This card session cannot use the account",
+		MsgCardSession:           "Sign in with username and PIN for this operation",
+		MsgCardSessionTerminal:   "The card session was opened at another ATM",
+		MsgTerminalMissing:       "Requests must come from a registered ATM",
+		MsgTerminalSignature:     "The terminal signature is invalid or expired",
+		MsgTerminalInactive:      "The terminal has been decommissioned",
+		MsgTerminalReplay:        "The request has already been received",
+		MsgDispenseNotes:         "The amount cannot be made from the notes in the ATM",
+		MsgDispenseTooManyNotes:  "The amount needs more notes than the ATM can dispense at once; try a smaller amount",
+		MsgCassetteDuplicate:     "Each denomination can only be listed once",
+	},
+	Turkish: {
+		"INVALID_REQUEST":          "Geçersiz istek",
+		"VALIDATION_FAILED":        "İstek doğrulaması başarısız",
+		"UNAUTHORIZED":             "Yetkilendirme hatası",
+		"TOKEN_INVALID":            "Token geçerli değil",
+		"INVALID_CREDENTIALS":      "Geçersiz kimlik bilgileri",
+		"FORBIDDEN":                "Erişim reddedildi",
+		"USER_NOT_FOUND":           "Kullanıcı bulunamadı",
+		"ACCOUNT_NOT_FOUND":        "Hesap bulunamadı",
+		"ACCOUNT_UNAVAILABLE":      "Hesap bu işlem için kullanılamaz",
+		"STANDING_ORDER_NOT_FOUND": "Düzenli ödeme talimatı bulunamadı",
+		"TRANSACTION_NOT_FOUND":    "İşlem bulunamadı",
+		"HOLD_NOT_FOUND":           "Provizyon bulunamadı",
+		"CARD_NOT_FOUND":           "Kart bulunamadı",
+		"CARD_UNAVAILABLE":         "Kart kullanılamıyor",
+		"PIN_LOCKED":               "Çok fazla hatalı PIN girildi; kart bloke edildi",
+		"TERMINAL_NOT_FOUND":       "Terminal bulunamadı",
+		"TERMINAL_UNAUTHORIZED":    "Terminal doğrulanamadı",
+		"CANNOT_DISPENSE":          "ATM bu tutarı ödeyemiyor",
+		"USERNAME_TAKEN":           "Bu kullanıcı adı zaten kullanılıyor",
+		"CONFLICT":                 "İstek, kaynağın mevcut durumuyla çelişiyor",
+		"INVALID_PHONE_NUMBER":     "Geçersiz telefon numarası",
+		"INVALID_PIN_FORMAT":       "Geçersiz PIN formatı",
+		"PIN_MISMATCH":             "Eski PIN mevcut PIN ile eşleşmiyor",
+		"INVALID_AMOUNT":           "Geçersiz tutar",
+		"CURRENCY_MISMATCH":        "Tutarın para birimi hesabın para birimiyle eşleşmiyor",
+		"RATE_UNAVAILABLE":         "Bu döviz çifti için kur bilgisi bulunmuyor",
+		"INVALID_RATE":             "Geçersiz döviz kuru",
+		"INSUFFICIENT_FUNDS":       "Yetersiz bakiye",
+		"LIMIT_EXCEEDED":           "Para çekme limiti aşıldı",
+		"RATE_LIMITED":             "İstek limiti aşıldı",
+		"TIMEOUT":                  "İstek zaman aşımına uğradı",
+		"INTERNAL_ERROR":           "Sunucu hatası",
+
+		MsgAuthHeaderMissing:   "Yetkilendirme başlığı eksik",
+		MsgAuthSchemeInvalid:   "Geçersiz token formatı",
+		MsgInvalidBody:         "Geçersiz istek gövdesi",
+		MsgAccountAccessDenied: "Bu hesaba erişim izniniz yok",
+		MsgUserDeleteDenied:    "Bu kullanıcıyı silme izniniz yok",
+		MsgUserHasOpenAccounts: "Kullanıcıyı silmeden önce tüm hesaplarınızı kapatın",
+
+		MsgValidationInvalid:     "Değer geçerli değil",
+		"VALIDATION_REQUIRED":    "Bu alan zorunludur",
+		"VALIDATION_UUID":        "Geçerli bir UUID olmalıdır",
+		"VALIDATION_E164":        "Telefon numarası E.164 formatında olmalıdır, örn. +905321234567",
+		"VALIDATION_PIN":         "PIN 4 haneli olmalı, tekrarlı veya ardışık olmamalıdır (örn. 1111, 1234)",
+		"VALIDATION_USERNAME":    "Kullanıcı adı 3-30 karakter olmalı, harfle başlamalı ve yalnızca harf, rakam, '.' veya '_' içermelidir",
+		"VALIDATION_AMOUNT":      "Tutar sıfırdan büyük ve işlem limiti dahilinde olmalıdır",
+		"VALIDATION_MAX":         "En fazla {param} karakter olmalıdır",
+		"VALIDATION_NEFIELD":     "{param} alanından farklı olmalıdır",
+		"VALIDATION_ONEOF":       "Şunlardan biri olmalıdır: {param}",
+		"VALIDATION_CURRENCY":    "Desteklenen bir ISO 4217 para birimi kodu olmalıdır",
+		"VALIDATION_GTE":         "En az {param} olmalıdır",
+		"VALIDATION_LTE":         "En fazla {param} olmalıdır",
+		"VALIDATION_CRON":        "\"0 9 1 * *\" veya @monthly gibi bir cron ifadesi olmalıdır",
+		"VALIDATION_PAN":         "Geçerli bir kart numarası olmalıdır",
+		MsgDepositSuccessful:     "Para yatırma başarılı",
+		MsgWithdrawalSuccessful:  "Para çekme başarılı",
+		MsgBalanceInquirySuccess: "Bakiye sorgulama başarılı",
+		MsgPINUpdated:            "PIN başarıyla güncellendi",
+		MsgTransferSuccessful:    "Transfer başarılı",
+		MsgTransferCurrency:      "Tutar, gönderen veya alıcı hesabın para biriminde olmalıdır",
+		MsgTransferSameAccount:   "Gönderen ve alıcı hesap farklı olmalıdır",
+		MsgLimitPerTransaction:   "Tutar, işlem başına para çekme limitini aşıyor",
+		MsgLimitDaily:            "Tutar, bugün kalan para çekme limitini aşıyor",
+		MsgLimitDailyCount:       "Günlük para çekme işlem sayısına ulaşıldı",
+		MsgLimitNegative:         "Limitler negatif olamaz",
+		MsgFeeScheduleInvalid:    "Ücret kurallarının adları benzersiz, ücretleri negatif olmayan ve üst sınırları pozitif olmalıdır",
+		MsgStandingOrderState:    "İptal edilmiş düzenli ödeme talimatları değiştirilemez",
+		MsgStandingOrderNoRun:    "Zamanlama hiçbir zaman gerçekleşmiyor",
+		MsgAlreadyReversed:       "Bu işlem zaten iptal edilmiş",
+		MsgReversalSuccessful:    "İşlem iptal edildi",
+		MsgAccountFrozen:         "Hesap dondurulmuş; para yatırılabilir ancak çekilemez",
+		MsgAccountDormant:        "Hesap hareketsiz; yeniden etkinleştirmek için bankaya başvurun",
+		MsgAccountClosed:         "Hesap kapatılmış",
+		MsgAccountTransition:     "Hesap bu duruma geçirilemez",
+		MsgAccountBalanceNotZero: "Yalnızca bakiyesi sıfır olan ve provizyonu bulunmayan hesaplar kapatılabilir",
+		MsgHoldState:             "Provizyon zaten tahsil edilmiş, serbest bırakılmış veya süresi dolmuş",
+		MsgHoldCaptureExceeds:    "Tahsil edilecek tutar provizyon tutarından fazla olamaz",
+		MsgHoldCurrency:          "Tutar hesabın para biriminde olmalıdır",
+		MsgHoldPlaced:            "Provizyon alındı",
+		MsgHoldCaptured:          "Provizyon tahsil edildi",
+		MsgHoldReleased:          "Provizyon serbest bırakıldı",
+		MsgCardBlocked:           "Kart bloke edilmiş",
+		MsgCardExpired:           "Kartın süresi dolmuş",
+		MsgCardAccountOwner:      "Kart yalnızca kart sahibinin kapalı olmayan hesaplarına bağlanabilir",
+		MsgCardScope:             "Bu kart oturumu bu hesabı kullanamaz",
+		MsgCardSession:           "Bu işlem için kullanıcı adı ve PIN ile giriş yapın",
+		MsgCardSessionTerminal:   "Kart oturumu başka bir ATM'de açıldı",
+		MsgTerminalMissing:       "İstekler kayıtlı bir ATM'den gelmelidir",
+		MsgTerminalSignature:     "Terminal imzası geçersiz veya süresi dolmuş",
+		MsgTerminalInactive:      "Terminal hizmet dışı bırakılmış",
+		MsgTerminalReplay:        "Bu istek daha önce alındı",
+		MsgDispenseNotes:         "Tutar ATM'deki banknotlarla oluşturulamıyor",
+		MsgDispenseTooManyNotes:  "Tutar, ATM'nin tek seferde verebileceğinden fazla banknot gerektiriyor; daha küçük bir tutar deneyin",
+		MsgCassetteDuplicate:     "Her kupür yalnızca bir kez listelenebilir",
+	},
+}
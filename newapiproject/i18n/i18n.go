@@ -0,0 +1,105 @@
+package i18n
+
+import (
+	"context"
+	"newapiprojet/money"
+	"strings"
+	"time"
+
+	"golang.org/x/text/language"
+)
+
+type Language string
+
+const (
+	English Language = "en"
+	Turkish Language = "tr"
+
+	Default = English
+)
+
+var (
+	supported = []language.Tag{language.English, language.Turkish}
+	matcher   = language.NewMatcher(supported)
+)
+
+type languageKey struct{}
+
+// Parse returns the supported language closest to lang, which may be a
+// single tag ("tr-TR") or a full Accept-Language header value. ok is false
+// when lang is empty or nothing in it is supported.
+func Parse(lang string) (Language, bool) {
+	if strings.TrimSpace(lang) == "" {
+		return Default, false
+	}
+	tags, _, err := language.ParseAcceptLanguage(lang)
+	if err != nil || len(tags) == 0 {
+		return Default, false
+	}
+	_, index, confidence := matcher.Match(tags...)
+	if confidence == language.No {
+		return Default, false
+	}
+	return Language(supported[index].String()), true
+}
+
+func WithLanguage(ctx context.Context, lang Language) context.Context {
+	return context.WithValue(ctx, languageKey{}, lang)
+}
+
+// FromContext returns the language chosen for the request, or Default.
+func FromContext(ctx context.Context) Language {
+	if lang, ok := ctx.Value(languageKey{}).(Language); ok {
+		return lang
+	}
+	return Default
+}
+
+// T translates a message key. Keys missing in lang fall back to English, and
+// unknown keys are returned unchanged.
+func T(lang Language, key string) string {
+	if msg, ok := catalog[lang][key]; ok {
+		return msg
+	}
+	if msg, ok := catalog[English][key]; ok {
+		return msg
+	}
+	return key
+}
+
+// FormatMoney formats amount with its currency symbol and the language's
+// grouping and decimal separators, e.g. "₺1,250.50" in English and
+// "₺1.250,50" in Turkish.
+func FormatMoney(lang Language, amount money.Money) string {
+	thousands, decimal := ",", "."
+	if lang == Turkish {
+		thousands, decimal = ".", ","
+	}
+
+	sign, digits := "", strings.TrimPrefix(amount.Decimal(), "-")
+	if amount.IsNegative() {
+		sign = "-"
+	}
+	digits, fraction, _ := strings.Cut(digits, ".")
+
+	var grouped strings.Builder
+	for i, d := range digits {
+		if i > 0 && (len(digits)-i)%3 == 0 {
+			grouped.WriteString(thousands)
+		}
+		grouped.WriteRune(d)
+	}
+
+	if fraction != "" {
+		grouped.WriteString(decimal + fraction)
+	}
+	return sign + amount.Currency().Symbol() + grouped.String()
+}
+
+// FormatDate formats t in the conventional style for lang.
+func FormatDate(lang Language, t time.Time) string {
+	if lang == Turkish {
+		return t.Format("02.01.2006 15:04")
+	}
+	return t.Format("Jan 2, 2006 3:04 PM")
+}
@@ -0,0 +1,269 @@
+package interest
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/big"
+	"sort"
+	"strings"
+	"time"
+
+	"newapiprojet/calendar"
+	"newapiprojet/database"
+	"newapiprojet/ledger"
+	"newapiprojet/models"
+	"newapiprojet/money"
+
+	"github.com/google/uuid"
+)
+
+// Interest state is stored per account, postings one key each:
+//
+//	interest_accruals/<accountID>  models.InterestAccrual
+//	interest_postings/<postingID>  models.InterestPosting
+const (
+	accrualsPrefix = "interest_accruals/"
+	postingsPrefix = "interest_postings/"
+)
+
+// daysPerYear is the day count used to turn yearly rates into daily ones.
+const daysPerYear = 365
+
+var ErrInvalidTier = errors.New("interest: invalid rate tier")
+
+// Tier sets the yearly rate, in basis points, for the part of a balance
+// above From whole units, up to the next tier's From. With tiers
+// {0, 0} and {10000, 3000}, a 15000 balance earns 30% a year on 5000.
+type Tier struct {
+	From    int64
+	RateBps int64
+}
+
+// Job accrues interest every business day and posts it once the month is
+// over. Positive balances earn interest by the tier table of their
+// currency; overdrawn balances are charged their overdraft rate.
+type Job struct {
+	db     database.Database
+	cal    *calendar.Calendar
+	tiers  map[money.Currency][]Tier
+	logger *slog.Logger
+	now    func() time.Time
+}
+
+// NewJob checks and sorts tiers. Currencies without tiers earn nothing.
+func NewJob(db database.Database, cal *calendar.Calendar, tiers map[money.Currency][]Tier, logger *slog.Logger) (*Job, error) {
+	sorted := make(map[money.Currency][]Tier, len(tiers))
+	for currency, list := range tiers {
+		list = append([]Tier(nil), list...)
+		sort.Slice(list, func(i, j int) bool { return list[i].From < list[j].From })
+		for i, tier := range list {
+			if tier.From < 0 || tier.RateBps < 0 || (i > 0 && tier.From == list[i-1].From) {
+				return nil, fmt.Errorf("%w: %s from %d", ErrInvalidTier, currency, tier.From)
+			}
+		}
+		sorted[currency] = list
+	}
+	return &Job{db: db, cal: cal, tiers: sorted, logger: logger, now: time.Now}, nil
+}
+
+// Run accrues every account up to and including the last completed business
+// day, posting the previous month's interest when a new month begins. It is
+// safe to run repeatedly: days already accrued are skipped.
+func (j *Job) Run(ctx context.Context) error {
+	through, err := calendar.AddDays(j.cal.Day(j.now()), -1)
+	if err != nil {
+		return err
+	}
+
+	accounts, err := j.db.List(ctx, "accounts/")
+	if err != nil {
+		return fmt.Errorf("listing accounts: %w", err)
+	}
+
+	var failed, posted int
+	for key := range accounts {
+		id, err := uuid.Parse(strings.TrimPrefix(key, "accounts/"))
+		if err != nil {
+			continue // legacy key layout
+		}
+		postings, err := j.accrue(ctx, id, through)
+		if err != nil {
+			failed++
+			j.logger.ErrorContext(ctx, "interest accrual failed", "account_id", id, "error", err)
+			continue
+		}
+		for _, posting := range postings {
+			posted++
+			j.logger.InfoContext(ctx, "interest posted", "account_id", id, "period", posting.Period, "amount", posting.Amount)
+		}
+	}
+
+	j.logger.InfoContext(ctx, "interest accrued", "through", through, "accounts", len(accounts), "posted", posted, "failed", failed)
+	if failed > 0 {
+		return fmt.Errorf("interest accrual failed for %d of %d accounts", failed, len(accounts))
+	}
+	return nil
+}
+
+// accrue brings one account's accrual up to through. Days missed while the
+// job was not running are accrued on the current balance.
+func (j *Job) accrue(ctx context.Context, accountID uuid.UUID, through string) ([]models.InterestPosting, error) {
+	var postings []models.InterestPosting
+	err := j.db.Atomic(ctx, func(tx database.Tx) error {
+		postings = nil
+
+		data := tx.Get("accounts/" + accountID.String())
+		if data == nil {
+			return nil // deleted since it was listed
+		}
+		var account models.Account
+		if err := json.Unmarshal(data, &account); err != nil {
+			return fmt.Errorf("unmarshaling account data: %w", err)
+		}
+
+		accrual, accrued, err := j.loadAccrual(tx, accountID, through)
+		if err != nil {
+			return err
+		}
+		if accrual.LastDay >= through {
+			return nil
+		}
+
+		daily := j.daily(account)
+		for accrual.LastDay < through {
+			day, err := calendar.AddDays(accrual.LastDay, 1)
+			if err != nil {
+				return err
+			}
+			if period := day[:7]; period != accrual.Period {
+				posting, err := j.post(tx, &account, accrual.Period, accrued)
+				if err != nil {
+					return err
+				}
+				if posting != nil {
+					postings = append(postings, *posting)
+					daily = j.daily(account)
+				}
+				accrual.Period = period
+			}
+			accrued.Add(accrued, daily)
+			accrual.LastDay = day
+		}
+
+		accrual.Accrued = accrued.RatString()
+		data, err = json.Marshal(accrual)
+		if err != nil {
+			return fmt.Errorf("marshaling interest accrual: %w", err)
+		}
+		tx.Put(accrualsPrefix+accountID.String(), data)
+		if len(postings) == 0 {
+			return nil
+		}
+
+		data, err = json.Marshal(account)
+		if err != nil {
+			return fmt.Errorf("marshaling account data: %w", err)
+		}
+		tx.Put("accounts/"+accountID.String(), data)
+		for _, posting := range postings {
+			data, err = json.Marshal(posting)
+			if err != nil {
+				return fmt.Errorf("marshaling interest posting: %w", err)
+			}
+			tx.Put(postingsPrefix+posting.ID.String(), data)
+		}
+		return nil
+	})
+	return postings, err
+}
+
+// loadAccrual returns the account's accrual and its exact amount. Accounts
+// seen for the first time start accruing on through.
+func (j *Job) loadAccrual(tx database.Tx, accountID uuid.UUID, through string) (models.InterestAccrual, *big.Rat, error) {
+	data := tx.Get(accrualsPrefix + accountID.String())
+	if data == nil {
+		start, err := calendar.AddDays(through, -1)
+		accrual := models.InterestAccrual{AccountID: accountID, Period: through[:7], LastDay: start}
+		return accrual, new(big.Rat), err
+	}
+
+	var accrual models.InterestAccrual
+	if err := json.Unmarshal(data, &accrual); err != nil {
+		return accrual, nil, fmt.Errorf("unmarshaling interest accrual: %w", err)
+	}
+	accrued, ok := new(big.Rat).SetString(accrual.Accrued)
+	if !ok {
+		return accrual, nil, fmt.Errorf("invalid accrued interest %q", accrual.Accrued)
+	}
+	return accrual, accrued, nil
+}
+
+// daily returns one day's interest on account's balance, in minor units.
+func (j *Job) daily(account models.Account) *big.Rat {
+	balance := account.Balance.Minor()
+	yearly := new(big.Rat)
+
+	switch {
+	case balance < 0:
+		if account.Overdraft == nil {
+			break
+		}
+		yearly.SetInt64(balance)
+		yearly.Mul(yearly, big.NewRat(account.Overdraft.RateBps, 10000))
+	case balance > 0:
+		tiers := j.tiers[account.Balance.Currency()]
+		scale := big.NewRat(1, 10000)
+		for i, tier := range tiers {
+			from, err := money.FromMajor(tier.From, account.Balance.Currency())
+			if err != nil || balance <= from.Minor() {
+				break
+			}
+			portion := balance - from.Minor()
+			if i+1 < len(tiers) {
+				if next, err := money.FromMajor(tiers[i+1].From, account.Balance.Currency()); err == nil && balance > next.Minor() {
+					portion = next.Minor() - from.Minor()
+				}
+			}
+			part := new(big.Rat).SetInt64(portion)
+			part.Mul(part, big.NewRat(tier.RateBps, 1))
+			yearly.Add(yearly, part.Mul(part, scale))
+		}
+	}
+	return yearly.Quo(yearly, big.NewRat(daysPerYear, 1))
+}
+
+// post books the whole minor units of accrued to account and leaves the
+// fraction in accrued for the next period. It returns nil when there is
+// nothing to post.
+func (j *Job) post(tx database.Tx, account *models.Account, period string, accrued *big.Rat) (*models.InterestPosting, error) {
+	units := new(big.Int).Quo(accrued.Num(), accrued.Denom()) // rounds towards zero
+	if units.Sign() == 0 {
+		return nil, nil
+	}
+	if !units.IsInt64() {
+		return nil, money.ErrOverflow
+	}
+	accrued.Sub(accrued, new(big.Rat).SetInt(units))
+
+	now := j.now()
+	amount := money.New(units.Int64(), account.Balance.Currency())
+	var err error
+	if amount.IsNegative() {
+		err = ledger.Charge(tx, account, amount.Neg(), now)
+	} else {
+		err = ledger.Credit(tx, account, amount, now)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &models.InterestPosting{
+		ID:        uuid.New(),
+		AccountID: account.ID,
+		Period:    period,
+		Amount:    amount,
+		PostedAt:  now,
+	}, nil
+}
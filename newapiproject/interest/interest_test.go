@@ -0,0 +1,106 @@
+package interest
+
+import (
+	"errors"
+	"io"
+	"log/slog"
+	"math/big"
+	"testing"
+	"time"
+
+	"newapiprojet/database/memdb"
+	"newapiprojet/models"
+	"newapiprojet/money"
+
+	"github.com/google/uuid"
+)
+
+func newTestJob(t *testing.T, tiers map[money.Currency][]Tier) *Job {
+	t.Helper()
+	j, err := NewJob(nil, nil, tiers, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return j
+}
+
+func TestDailyTiers(t *testing.T) {
+	j := newTestJob(t, map[money.Currency][]Tier{
+		// Given out of order; NewJob sorts them.
+		money.TRY: {{From: 5000, RateBps: 500}, {From: 0, RateBps: 100}, {From: 1000, RateBps: 200}},
+		money.USD: {{From: 0, RateBps: 0}, {From: 10000, RateBps: 3000}},
+		money.EUR: {{From: 1000, RateBps: 100}},
+	})
+	tests := []struct {
+		name      string
+		balance   money.Money
+		overdraft *models.Overdraft
+		yearly    int64 // minor units
+	}{
+		{"first tier only", money.New(50000, money.TRY), nil, 500},
+		{"top of first tier", money.New(100000, money.TRY), nil, 1000},
+		{"into second tier", money.New(100100, money.TRY), nil, 1000 + 2},
+		// 1000 at 1%, 4000 at 2% and 1000 at 5%.
+		{"all tiers", money.New(600000, money.TRY), nil, 1000 + 8000 + 5000},
+		// The example from the Tier doc comment: 30% on 5000.
+		{"zero-rate first tier", money.New(1500000, money.USD), nil, 150000},
+		{"below a tier starting above zero", money.New(50000, money.EUR), nil, 0},
+		{"above a tier starting above zero", money.New(150000, money.EUR), nil, 500},
+		{"currency without tiers", money.New(100000, money.GBP), nil, 0},
+		{"zero balance", money.New(0, money.TRY), nil, 0},
+		{"overdrawn", money.New(-100000, money.TRY), &models.Overdraft{RateBps: 2000}, -20000},
+		{"overdrawn without facility", money.New(-100000, money.TRY), nil, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			account := models.Account{ID: uuid.New(), Balance: tt.balance, Overdraft: tt.overdraft}
+			got := j.daily(account)
+			want := big.NewRat(tt.yearly, daysPerYear)
+			if got.Cmp(want) != 0 {
+				t.Errorf("daily = %s, want %s (%d a year)", got.RatString(), want.RatString(), tt.yearly)
+			}
+		})
+	}
+}
+
+func TestNewJobRejects(t *testing.T) {
+	tests := [][]Tier{
+		{{From: -1, RateBps: 100}},
+		{{From: 0, RateBps: -1}},
+		{{From: 0, RateBps: 100}, {From: 0, RateBps: 200}},
+	}
+	for _, tiers := range tests {
+		_, err := NewJob(nil, nil, map[money.Currency][]Tier{money.TRY: tiers}, slog.New(slog.NewTextHandler(io.Discard, nil)))
+		if !errors.Is(err, ErrInvalidTier) {
+			t.Errorf("NewJob(%v) error = %v, want %v", tiers, err, ErrInvalidTier)
+		}
+	}
+}
+
+func TestPostKeepsFraction(t *testing.T) {
+	j := newTestJob(t, nil)
+	now := time.Date(2026, 11, 1, 0, 0, 0, 0, time.UTC)
+	j.now = func() time.Time { return now }
+
+	account := models.Account{ID: uuid.New(), Balance: money.New(100000, money.TRY)}
+	accrued := big.NewRat(1234, 100)
+	posting, err := j.post(memdb.Tx{}, &account, "2026-10", accrued)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if posting == nil || posting.Amount != money.New(12, money.TRY) || posting.Period != "2026-10" {
+		t.Fatalf("posting = %+v, want 0.12 TRY for 2026-10", posting)
+	}
+	if want := money.New(100012, money.TRY); account.Balance != want {
+		t.Errorf("balance = %v, want %v", account.Balance, want)
+	}
+	if want := big.NewRat(34, 100); accrued.Cmp(want) != 0 {
+		t.Errorf("carried = %s, want %s", accrued.RatString(), want.RatString())
+	}
+
+	// Less than a minor unit is carried, not posted.
+	posting, err = j.post(memdb.Tx{}, &account, "2026-11", accrued)
+	if err != nil || posting != nil {
+		t.Errorf("post of a fraction = %+v, %v, want nothing", posting, err)
+	}
+}
@@ -0,0 +1,69 @@
+package jobs
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"newapiprojet/metrics"
+)
+
+// retryDelay is how long to wait before campaigning again after an
+// election error.
+const retryDelay = 5 * time.Second
+
+// Elector makes sure only one replica runs a job at a time.
+// etcd.EtcdClient implements it with an etcd election.
+type Elector interface {
+	Lead(ctx context.Context, name, candidate string, fn func(ctx context.Context)) error
+}
+
+// Job is periodic background work.
+type Job struct {
+	Name  string
+	Every time.Duration
+	Run   func(ctx context.Context) error
+}
+
+// Start runs job every job.Every on whichever replica wins its election,
+// until ctx is done. It blocks, so call it in its own goroutine.
+func Start(ctx context.Context, elector Elector, candidate string, job Job, logger *slog.Logger) {
+	logger = logger.With("job", job.Name, "candidate", candidate)
+	for ctx.Err() == nil {
+		err := elector.Lead(ctx, job.Name, candidate, func(ctx context.Context) {
+			logger.InfoContext(ctx, "elected job leader")
+			ticker := time.NewTicker(job.Every)
+			defer ticker.Stop()
+			for {
+				runOnce(ctx, job, logger)
+				select {
+				case <-ctx.Done():
+					logger.Info("job leadership ended")
+					return
+				case <-ticker.C:
+				}
+			}
+		})
+		if err != nil && ctx.Err() == nil {
+			logger.Error("job election failed", "error", err)
+			select {
+			case <-ctx.Done():
+			case <-time.After(retryDelay):
+			}
+		}
+	}
+}
+
+func runOnce(ctx context.Context, job Job, logger *slog.Logger) {
+	start := time.Now()
+	err := job.Run(ctx)
+	metrics.JobRunDuration.WithLabelValues(job.Name).Observe(time.Since(start).Seconds())
+	if err != nil {
+		if ctx.Err() == nil {
+			logger.ErrorContext(ctx, "job run failed", "error", err)
+		}
+		metrics.JobRunsTotal.WithLabelValues(job.Name, "error").Inc()
+		return
+	}
+	metrics.JobRunsTotal.WithLabelValues(job.Name, "ok").Inc()
+}
@@ -0,0 +1,50 @@
+package ledger
+
+import (
+	"errors"
+
+	"newapiprojet/models"
+	"newapiprojet/money"
+)
+
+var ErrNotHeld = errors.New("ledger: amount is not held")
+
+// Held returns the total of account's active holds.
+func Held(account models.Account) money.Money {
+	if account.Held == nil {
+		return money.New(0, account.Balance.Currency())
+	}
+	return *account.Held
+}
+
+// Hold sets amount of account's available balance aside, failing with
+// ErrInsufficientFunds if it is not available. The balance itself does not
+// change until the hold is captured. The caller stores the account.
+func Hold(account *models.Account, amount money.Money) error {
+	if err := checkAvailable(*account, amount); err != nil {
+		return err
+	}
+	held, err := Held(*account).Add(amount)
+	if err != nil {
+		return err
+	}
+	account.Held = &held
+	return nil
+}
+
+// Unhold makes amount held by Hold available again. The caller stores the
+// account.
+func Unhold(account *models.Account, amount money.Money) error {
+	held, err := Held(*account).Sub(amount)
+	if err != nil {
+		return err
+	}
+	if held.IsNegative() {
+		return ErrNotHeld
+	}
+	account.Held = &held
+	if held.IsZero() {
+		account.Held = nil
+	}
+	return nil
+}
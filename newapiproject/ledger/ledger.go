@@ -0,0 +1,123 @@
+package ledger
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"newapiprojet/database"
+	"newapiprojet/models"
+	"newapiprojet/money"
+
+	"github.com/google/uuid"
+)
+
+// Overdraft events are kept per account in time order:
+//
+//	overdraft_events/<accountID>/<unix nanos, zero-padded>
+const overdraftEventsPrefix = "overdraft_events/"
+
+var ErrInsufficientFunds = errors.New("ledger: insufficient funds")
+
+// OverdraftEventsPrefix returns the key prefix under which account's
+// overdraft events are stored.
+func OverdraftEventsPrefix(accountID uuid.UUID) string {
+	return overdraftEventsPrefix + accountID.String() + "/"
+}
+
+// Available returns how much can be debited from account: its balance plus
+// any approved overdraft, less its active holds.
+func Available(account models.Account) (money.Money, error) {
+	available := account.Balance
+	if account.Overdraft != nil {
+		var err error
+		if available, err = available.Add(account.Overdraft.Limit); err != nil {
+			return available, err
+		}
+	}
+	return available.Sub(Held(account))
+}
+
+// Debit takes amount from account, letting the balance go negative as far as
+// its overdraft allows. Held money cannot be debited. The caller stores the
+// account.
+func Debit(tx database.Tx, account *models.Account, amount money.Money, at time.Time) error {
+	if err := checkAvailable(*account, amount); err != nil {
+		return err
+	}
+	balance, err := account.Balance.Sub(amount)
+	if err != nil {
+		return err
+	}
+	return setBalance(tx, account, balance, at)
+}
+
+// checkAvailable fails with ErrInsufficientFunds if amount is more than
+// account has available.
+func checkAvailable(account models.Account, amount money.Money) error {
+	available, err := Available(account)
+	if err != nil {
+		return err
+	}
+	cmp, err := amount.Cmp(available)
+	if err != nil {
+		return err
+	}
+	if cmp > 0 {
+		return ErrInsufficientFunds
+	}
+	return nil
+}
+
+// Credit adds amount to account. The caller stores the account.
+func Credit(tx database.Tx, account *models.Account, amount money.Money, at time.Time) error {
+	balance, err := account.Balance.Add(amount)
+	if err != nil {
+		return err
+	}
+	return setBalance(tx, account, balance, at)
+}
+
+// Charge takes amount from account even beyond its overdraft, for charges
+// such as interest that the customer cannot decline. The caller stores the
+// account.
+func Charge(tx database.Tx, account *models.Account, amount money.Money, at time.Time) error {
+	balance, err := account.Balance.Sub(amount)
+	if err != nil {
+		return err
+	}
+	return setBalance(tx, account, balance, at)
+}
+
+// setBalance updates account's balance and records an overdraft event when
+// the balance crosses zero.
+func setBalance(tx database.Tx, account *models.Account, balance money.Money, at time.Time) error {
+	wasOverdrawn := account.Balance.IsNegative()
+	account.Balance = balance
+
+	var eventType models.OverdraftEventType
+	switch {
+	case !wasOverdrawn && balance.IsNegative():
+		eventType = models.OverdraftEntered
+		account.OverdraftSince = &at
+	case wasOverdrawn && !balance.IsNegative():
+		eventType = models.OverdraftLeft
+		account.OverdraftSince = nil
+	default:
+		return nil
+	}
+
+	event := models.OverdraftEvent{
+		AccountID: account.ID,
+		Type:      eventType,
+		Balance:   balance,
+		At:        at,
+	}
+	data, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("marshaling overdraft event: %w", err)
+	}
+	tx.Put(fmt.Sprintf("%s%020d", OverdraftEventsPrefix(account.ID), at.UnixNano()), data)
+	return nil
+}
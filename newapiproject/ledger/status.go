@@ -0,0 +1,121 @@
+package ledger
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"newapiprojet/database"
+	"newapiprojet/models"
+
+	"github.com/google/uuid"
+)
+
+// Status changes are kept per account in time order:
+//
+//	account_status_events/<accountID>/<unix nanos, zero-padded>
+const statusEventsPrefix = "account_status_events/"
+
+var (
+	ErrAccountFrozen     = errors.New("ledger: account is frozen")
+	ErrAccountDormant    = errors.New("ledger: account is dormant")
+	ErrAccountClosed     = errors.New("ledger: account is closed")
+	ErrInvalidTransition = errors.New("ledger: account status change not allowed")
+	ErrBalanceNotZero    = errors.New("ledger: account balance is not zero")
+)
+
+// transitions lists the statuses each status may change to.
+var transitions = map[models.AccountStatus][]models.AccountStatus{
+	models.AccountActive:  {models.AccountFrozen, models.AccountDormant, models.AccountClosed},
+	models.AccountFrozen:  {models.AccountActive},
+	models.AccountDormant: {models.AccountActive},
+}
+
+// StatusEventsPrefix returns the key prefix under which account's status
+// changes are stored.
+func StatusEventsPrefix(accountID uuid.UUID) string {
+	return statusEventsPrefix + accountID.String() + "/"
+}
+
+// Status returns account's status; accounts stored without one are active.
+func Status(account models.Account) models.AccountStatus {
+	if account.Status == "" {
+		return models.AccountActive
+	}
+	return account.Status
+}
+
+// CanDebit reports whether money may be taken from account: only active
+// accounts can be debited.
+func CanDebit(account models.Account) error {
+	switch Status(account) {
+	case models.AccountFrozen:
+		return ErrAccountFrozen
+	case models.AccountDormant:
+		return ErrAccountDormant
+	case models.AccountClosed:
+		return ErrAccountClosed
+	}
+	return nil
+}
+
+// CanCredit reports whether money may be paid into account: anything but a
+// closed account can be credited.
+func CanCredit(account models.Account) error {
+	if Status(account) == models.AccountClosed {
+		return ErrAccountClosed
+	}
+	return nil
+}
+
+// RecordActivity notes that the customer used account at at. It does not
+// reactivate a dormant account; only staff can. The caller stores the
+// account.
+func RecordActivity(account *models.Account, at time.Time) {
+	account.LastActivityAt = &at
+}
+
+// SetStatus moves account to status and records the change. Accounts can
+// only be closed with a zero balance and no holds, and closed accounts stay
+// closed.
+// Reactivating a dormant account restarts its inactivity period.
+// changedBy is nil for changes made by the system. The caller stores the
+// account.
+func SetStatus(tx database.Tx, account *models.Account, status models.AccountStatus, reason string, changedBy *uuid.UUID, at time.Time) error {
+	from := Status(*account)
+	if from == status {
+		return nil
+	}
+	allowed := false
+	for _, to := range transitions[from] {
+		allowed = allowed || to == status
+	}
+	if !allowed {
+		return fmt.Errorf("%w: %s to %s", ErrInvalidTransition, from, status)
+	}
+	if status == models.AccountClosed && (!account.Balance.IsZero() || !Held(*account).IsZero()) {
+		return ErrBalanceNotZero
+	}
+
+	account.Status = status
+	account.StatusChangedAt = &at
+	if from == models.AccountDormant {
+		RecordActivity(account, at)
+	}
+
+	event := models.AccountStatusEvent{
+		AccountID: account.ID,
+		From:      from,
+		To:        status,
+		Reason:    reason,
+		ChangedBy: changedBy,
+		At:        at,
+	}
+	data, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("marshaling account status event: %w", err)
+	}
+	tx.Put(fmt.Sprintf("%s%020d", StatusEventsPrefix(account.ID), at.UnixNano()), data)
+	return nil
+}
@@ -0,0 +1,144 @@
+package ledger
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"newapiprojet/database/memdb"
+	"newapiprojet/models"
+	"newapiprojet/money"
+
+	"github.com/google/uuid"
+)
+
+func try(minor int64) money.Money {
+	return money.New(minor, money.TRY)
+}
+
+func TestSetStatusTransitions(t *testing.T) {
+	statuses := []models.AccountStatus{models.AccountActive, models.AccountFrozen, models.AccountDormant, models.AccountClosed}
+	allowed := map[[2]models.AccountStatus]bool{
+		{models.AccountActive, models.AccountFrozen}:  true,
+		{models.AccountActive, models.AccountDormant}: true,
+		{models.AccountActive, models.AccountClosed}:  true,
+		{models.AccountFrozen, models.AccountActive}:  true,
+		{models.AccountDormant, models.AccountActive}: true,
+	}
+	for _, from := range statuses {
+		for _, to := range statuses {
+			account := models.Account{ID: uuid.New(), Balance: try(0), Status: from}
+			err := SetStatus(memdb.Tx{}, &account, to, "test", nil, time.Now())
+			switch {
+			case from == to:
+				if err != nil {
+					t.Errorf("%s to itself: %v, want no change", from, err)
+				}
+			case allowed[[2]models.AccountStatus{from, to}]:
+				if err != nil || account.Status != to {
+					t.Errorf("%s to %s: status %s, error %v", from, to, account.Status, err)
+				}
+			default:
+				if !errors.Is(err, ErrInvalidTransition) || account.Status != from {
+					t.Errorf("%s to %s: status %s, error %v, want %v", from, to, account.Status, err, ErrInvalidTransition)
+				}
+			}
+		}
+	}
+}
+
+func TestSetStatusRecordsEvent(t *testing.T) {
+	tx := memdb.Tx{}
+	changedBy := uuid.New()
+	at := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+	// Accounts stored before statuses existed are active.
+	account := models.Account{ID: uuid.New(), Balance: try(500)}
+	if err := SetStatus(tx, &account, models.AccountFrozen, "fraud check", &changedBy, at); err != nil {
+		t.Fatal(err)
+	}
+	if account.StatusChangedAt == nil || !account.StatusChangedAt.Equal(at) {
+		t.Errorf("status changed at %v, want %v", account.StatusChangedAt, at)
+	}
+	if len(tx) != 1 {
+		t.Fatalf("stored %d keys, want one event", len(tx))
+	}
+	for key, data := range tx {
+		var event models.AccountStatusEvent
+		if err := json.Unmarshal(data, &event); err != nil {
+			t.Fatal(err)
+		}
+		want := models.AccountStatusEvent{AccountID: account.ID, From: models.AccountActive, To: models.AccountFrozen, Reason: "fraud check", ChangedBy: &changedBy, At: at}
+		if event.AccountID != want.AccountID || event.From != want.From || event.To != want.To || event.Reason != want.Reason || event.ChangedBy == nil || *event.ChangedBy != changedBy || !event.At.Equal(at) {
+			t.Errorf("%s = %+v, want %+v", key, event, want)
+		}
+	}
+}
+
+func TestCloseNeedsZeroBalance(t *testing.T) {
+	held := try(100)
+	tests := []struct {
+		name    string
+		account models.Account
+		err     error
+	}{
+		{"zero balance", models.Account{Balance: try(0)}, nil},
+		{"money left", models.Account{Balance: try(1)}, ErrBalanceNotZero},
+		{"overdrawn", models.Account{Balance: try(-1)}, ErrBalanceNotZero},
+		{"active hold", models.Account{Balance: try(0), Held: &held}, ErrBalanceNotZero},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			account := tt.account
+			account.ID = uuid.New()
+			err := SetStatus(memdb.Tx{}, &account, models.AccountClosed, "closed by customer", nil, time.Now())
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("close error = %v, want %v", err, tt.err)
+			}
+			if err != nil && Status(account) != models.AccountActive {
+				t.Errorf("status = %s after a refused close", account.Status)
+			}
+		})
+	}
+}
+
+func TestReactivatingDormantRestartsInactivity(t *testing.T) {
+	lastUsed := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	now := time.Date(2026, 10, 19, 0, 0, 0, 0, time.UTC)
+	account := models.Account{ID: uuid.New(), Balance: try(0), Status: models.AccountDormant, LastActivityAt: &lastUsed}
+	if err := SetStatus(memdb.Tx{}, &account, models.AccountActive, "customer visited", nil, now); err != nil {
+		t.Fatal(err)
+	}
+	if account.LastActivityAt == nil || !account.LastActivityAt.Equal(now) {
+		t.Errorf("last activity = %v, want %v", account.LastActivityAt, now)
+	}
+
+	// Using a dormant account does not reactivate it.
+	account.Status = models.AccountDormant
+	RecordActivity(&account, now.Add(time.Hour))
+	if account.Status != models.AccountDormant {
+		t.Errorf("status = %s after activity, want %s", account.Status, models.AccountDormant)
+	}
+}
+
+func TestCanDebitAndCredit(t *testing.T) {
+	tests := []struct {
+		status        models.AccountStatus
+		debit, credit error
+	}{
+		{"", nil, nil},
+		{models.AccountActive, nil, nil},
+		{models.AccountFrozen, ErrAccountFrozen, nil},
+		{models.AccountDormant, ErrAccountDormant, nil},
+		{models.AccountClosed, ErrAccountClosed, ErrAccountClosed},
+	}
+	for _, tt := range tests {
+		account := models.Account{Status: tt.status}
+		if err := CanDebit(account); !errors.Is(err, tt.debit) {
+			t.Errorf("CanDebit(%q) = %v, want %v", tt.status, err, tt.debit)
+		}
+		if err := CanCredit(account); !errors.Is(err, tt.credit) {
+			t.Errorf("CanCredit(%q) = %v, want %v", tt.status, err, tt.credit)
+		}
+	}
+}
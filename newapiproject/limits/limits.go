@@ -0,0 +1,292 @@
+package limits
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"newapiprojet/calendar"
+	"newapiprojet/database"
+	"newapiprojet/models"
+	"newapiprojet/money"
+
+	"github.com/google/uuid"
+)
+
+// Limits and usage are stored per account:
+//
+//	limits/<accountID>            models.WithdrawalLimits, absent means defaults
+//	withdrawal_usage/<accountID>  models.WithdrawalUsage for the current business day
+const (
+	limitsPrefix = "limits/"
+	usagePrefix  = "withdrawal_usage/"
+)
+
+var (
+	ErrPerTransaction = errors.New("limits: amount exceeds per-transaction limit")
+	ErrDaily          = errors.New("limits: amount exceeds daily limit")
+	ErrDailyCount     = errors.New("limits: daily withdrawal count reached")
+	ErrInvalidLimit   = errors.New("limits: limit must not be negative")
+)
+
+// Defaults apply to accounts without limits of their own, in whole units of
+// the account currency. Zero disables a limit.
+type Defaults struct {
+	PerTransaction int64
+	Daily          int64
+	DailyCount     int
+}
+
+type Service struct {
+	cal      *calendar.Calendar
+	defaults Defaults
+	now      func() time.Time
+}
+
+func NewService(cal *calendar.Calendar, defaults Defaults) *Service {
+	return &Service{cal: cal, defaults: defaults, now: time.Now}
+}
+
+func limitsKey(accountID uuid.UUID) string {
+	return limitsPrefix + accountID.String()
+}
+
+func usageKey(accountID uuid.UUID) string {
+	return usagePrefix + accountID.String()
+}
+
+// Get returns the limits for account, falling back to the defaults.
+func (s *Service) Get(tx database.Tx, account models.Account) (models.WithdrawalLimits, error) {
+	data := tx.Get(limitsKey(account.ID))
+	if data == nil {
+		return s.defaultLimits(account.Balance.Currency())
+	}
+	var limits models.WithdrawalLimits
+	if err := json.Unmarshal(data, &limits); err != nil {
+		return limits, fmt.Errorf("unmarshaling withdrawal limits: %w", err)
+	}
+	return limits, nil
+}
+
+func (s *Service) defaultLimits(currency money.Currency) (models.WithdrawalLimits, error) {
+	perTransaction, err := money.FromMajor(s.defaults.PerTransaction, currency)
+	if err != nil {
+		return models.WithdrawalLimits{}, err
+	}
+	daily, err := money.FromMajor(s.defaults.Daily, currency)
+	if err != nil {
+		return models.WithdrawalLimits{}, err
+	}
+	return models.WithdrawalLimits{
+		PerTransaction: perTransaction,
+		Daily:          daily,
+		DailyCount:     s.defaults.DailyCount,
+	}, nil
+}
+
+// Set stores limits for account. Amounts must be in the account currency.
+func (s *Service) Set(tx database.Tx, account models.Account, limits models.WithdrawalLimits) error {
+	currency := account.Balance.Currency()
+	for _, m := range []*money.Money{&limits.PerTransaction, &limits.Daily} {
+		switch {
+		case m.IsNegative():
+			return ErrInvalidLimit
+		case m.IsZero():
+			*m = money.New(0, currency)
+		case m.Currency() != currency:
+			return money.ErrCurrencyMismatch
+		}
+	}
+	if limits.DailyCount < 0 {
+		return ErrInvalidLimit
+	}
+
+	data, err := json.Marshal(limits)
+	if err != nil {
+		return fmt.Errorf("marshaling withdrawal limits: %w", err)
+	}
+	tx.Put(limitsKey(account.ID), data)
+	return nil
+}
+
+// usage returns what account has withdrawn on the current business day.
+// Usage recorded on an earlier day counts as nothing.
+func (s *Service) usage(tx database.Tx, account models.Account, day string) (models.WithdrawalUsage, error) {
+	usage := models.WithdrawalUsage{
+		BusinessDay: day,
+		Withdrawn:   money.New(0, account.Balance.Currency()),
+	}
+	data := tx.Get(usageKey(account.ID))
+	if data == nil {
+		return usage, nil
+	}
+
+	var stored models.WithdrawalUsage
+	if err := json.Unmarshal(data, &stored); err != nil {
+		return usage, fmt.Errorf("unmarshaling withdrawal usage: %w", err)
+	}
+	if stored.BusinessDay != day {
+		return usage, nil
+	}
+	return stored, nil
+}
+
+// Reserve checks debit, in the account currency, against the account's
+// limits and records it against today's usage. Call it in the same
+// transaction as the balance update so concurrent withdrawals cannot both
+// fit under the limit.
+func (s *Service) Reserve(tx database.Tx, account models.Account, debit money.Money) error {
+	limits, err := s.Get(tx, account)
+	if err != nil {
+		return err
+	}
+	usage, err := s.usage(tx, account, s.cal.Day(s.now()))
+	if err != nil {
+		return err
+	}
+
+	if !limits.PerTransaction.IsZero() {
+		cmp, err := debit.Cmp(limits.PerTransaction)
+		if err != nil {
+			return err
+		}
+		if cmp > 0 {
+			return ErrPerTransaction
+		}
+	}
+	if limits.DailyCount > 0 && usage.Count >= limits.DailyCount {
+		return ErrDailyCount
+	}
+
+	withdrawn, err := usage.Withdrawn.Add(debit)
+	if err != nil {
+		return err
+	}
+	if !limits.Daily.IsZero() {
+		cmp, err := withdrawn.Cmp(limits.Daily)
+		if err != nil {
+			return err
+		}
+		if cmp > 0 {
+			return ErrDaily
+		}
+	}
+
+	usage.Withdrawn = withdrawn
+	usage.Count++
+	data, err := json.Marshal(usage)
+	if err != nil {
+		return fmt.Errorf("marshaling withdrawal usage: %w", err)
+	}
+	tx.Put(usageKey(account.ID), data)
+	return nil
+}
+
+// Release gives back a withdrawal of debit made at at, when it was made on
+// the current business day. Withdrawals from earlier days no longer count
+// against any limit.
+func (s *Service) Release(tx database.Tx, account models.Account, debit money.Money, at time.Time) error {
+	return s.giveBack(tx, account, debit, 1, at)
+}
+
+// Reduce gives back part of a withdrawal made at at, such as the part of a
+// hold that was not captured. The withdrawal still counts towards the daily
+// count.
+func (s *Service) Reduce(tx database.Tx, account models.Account, by money.Money, at time.Time) error {
+	return s.giveBack(tx, account, by, 0, at)
+}
+
+func (s *Service) giveBack(tx database.Tx, account models.Account, debit money.Money, count int, at time.Time) error {
+	day := s.cal.Day(s.now())
+	if s.cal.Day(at) != day {
+		return nil
+	}
+	usage, err := s.usage(tx, account, day)
+	if err != nil {
+		return err
+	}
+	if usage.Count == 0 {
+		return nil
+	}
+
+	withdrawn, err := usage.Withdrawn.Sub(debit)
+	if err != nil {
+		return err
+	}
+	if withdrawn.IsNegative() {
+		withdrawn = money.New(0, withdrawn.Currency())
+	}
+	usage.Withdrawn = withdrawn
+	usage.Count -= count
+	data, err := json.Marshal(usage)
+	if err != nil {
+		return fmt.Errorf("marshaling withdrawal usage: %w", err)
+	}
+	tx.Put(usageKey(account.ID), data)
+	return nil
+}
+
+// Remaining is what an account may still withdraw on the current business
+// day. A nil field means the corresponding limit is disabled.
+type Remaining struct {
+	BusinessDay    string
+	ResetsAt       time.Time
+	Limits         models.WithdrawalLimits
+	Usage          models.WithdrawalUsage
+	PerTransaction *money.Money
+	Daily          *money.Money
+	DailyCount     *int
+}
+
+// Remaining reports account's limits and what is left of them today.
+func (s *Service) Remaining(tx database.Tx, account models.Account) (Remaining, error) {
+	now := s.now()
+	day := s.cal.Day(now)
+	limits, err := s.Get(tx, account)
+	if err != nil {
+		return Remaining{}, err
+	}
+	usage, err := s.usage(tx, account, day)
+	if err != nil {
+		return Remaining{}, err
+	}
+
+	r := Remaining{
+		BusinessDay: day,
+		ResetsAt:    s.cal.NextBoundary(now),
+		Limits:      limits,
+		Usage:       usage,
+	}
+	if !limits.Daily.IsZero() {
+		daily, err := limits.Daily.Sub(usage.Withdrawn)
+		if err != nil {
+			return r, err
+		}
+		if daily.IsNegative() {
+			daily = money.New(0, daily.Currency())
+		}
+		r.Daily = &daily
+	}
+	if limits.DailyCount > 0 {
+		count := max(limits.DailyCount-usage.Count, 0)
+		r.DailyCount = &count
+	}
+	// A single withdrawal is bounded by the per-transaction limit and by
+	// what is left of the daily one.
+	if !limits.PerTransaction.IsZero() || r.Daily != nil {
+		perTransaction := limits.PerTransaction
+		if perTransaction.IsZero() {
+			perTransaction = *r.Daily
+		} else if r.Daily != nil {
+			if cmp, err := r.Daily.Cmp(perTransaction); err == nil && cmp < 0 {
+				perTransaction = *r.Daily
+			}
+		}
+		if r.DailyCount != nil && *r.DailyCount == 0 {
+			perTransaction = money.New(0, perTransaction.Currency())
+		}
+		r.PerTransaction = &perTransaction
+	}
+	return r, nil
+}
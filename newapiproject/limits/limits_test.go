@@ -0,0 +1,205 @@
+package limits
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"newapiprojet/calendar"
+	"newapiprojet/database/memdb"
+	"newapiprojet/models"
+	"newapiprojet/money"
+
+	"github.com/google/uuid"
+)
+
+func newTestService(t *testing.T, now *time.Time) *Service {
+	t.Helper()
+	cal, err := calendar.New("", "17:30")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := NewService(cal, Defaults{PerTransaction: 500, Daily: 1000, DailyCount: 3})
+	s.now = func() time.Time { return *now }
+	return s
+}
+
+func try(minor int64) money.Money {
+	return money.New(minor, money.TRY)
+}
+
+func TestReserve(t *testing.T) {
+	tests := []struct {
+		name   string
+		limits *models.WithdrawalLimits
+		debits []int64
+		err    error
+	}{
+		{name: "within defaults", debits: []int64{50000, 50000}},
+		{name: "over per-transaction default", debits: []int64{50001}, err: ErrPerTransaction},
+		{name: "over daily default", debits: []int64{50000, 40000, 10001}, err: ErrDaily},
+		{name: "daily count reached", debits: []int64{100, 100, 100, 100}, err: ErrDailyCount},
+		{
+			name:   "account limits replace defaults",
+			limits: &models.WithdrawalLimits{PerTransaction: try(200000), Daily: try(300000), DailyCount: 10},
+			debits: []int64{200000, 100000},
+		},
+		{
+			name:   "zero disables a limit",
+			limits: &models.WithdrawalLimits{},
+			debits: []int64{10000000, 10000000, 10000000, 10000000},
+		},
+		{
+			name:   "daily limit below per-transaction",
+			limits: &models.WithdrawalLimits{PerTransaction: try(50000), Daily: try(30000)},
+			debits: []int64{30001},
+			err:    ErrDaily,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			now := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+			s := newTestService(t, &now)
+			tx := memdb.Tx{}
+			account := models.Account{ID: uuid.New(), Balance: try(0)}
+			if tt.limits != nil {
+				if err := s.Set(tx, account, *tt.limits); err != nil {
+					t.Fatal(err)
+				}
+			}
+			var err error
+			for i, debit := range tt.debits {
+				if err = s.Reserve(tx, account, try(debit)); err != nil {
+					if i != len(tt.debits)-1 {
+						t.Fatalf("Reserve #%d: %v", i+1, err)
+					}
+				}
+			}
+			if !errors.Is(err, tt.err) {
+				t.Errorf("Reserve error = %v, want %v", err, tt.err)
+			}
+		})
+	}
+}
+
+func TestUsageResetsAtCutoff(t *testing.T) {
+	now := time.Date(2026, 10, 19, 17, 0, 0, 0, time.UTC)
+	s := newTestService(t, &now)
+	tx := memdb.Tx{}
+	account := models.Account{ID: uuid.New(), Balance: try(0)}
+
+	for range 3 {
+		if err := s.Reserve(tx, account, try(30000)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := s.Reserve(tx, account, try(100)); !errors.Is(err, ErrDailyCount) {
+		t.Fatalf("Reserve before cut-off: error = %v, want %v", err, ErrDailyCount)
+	}
+
+	now = time.Date(2026, 10, 19, 17, 30, 0, 0, time.UTC)
+	if err := s.Reserve(tx, account, try(50000)); err != nil {
+		t.Fatalf("Reserve after cut-off: %v", err)
+	}
+	r, err := s.Remaining(tx, account)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.BusinessDay != "2026-10-20" {
+		t.Errorf("BusinessDay = %s, want 2026-10-20", r.BusinessDay)
+	}
+	if want := time.Date(2026, 10, 20, 17, 30, 0, 0, time.UTC); !r.ResetsAt.Equal(want) {
+		t.Errorf("ResetsAt = %v, want %v", r.ResetsAt, want)
+	}
+	if r.Daily == nil || *r.Daily != try(50000) {
+		t.Errorf("Daily = %v, want %v", r.Daily, try(50000))
+	}
+	if r.DailyCount == nil || *r.DailyCount != 2 {
+		t.Errorf("DailyCount = %v, want 2", r.DailyCount)
+	}
+}
+
+func TestReleaseAndReduce(t *testing.T) {
+	now := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+	s := newTestService(t, &now)
+	tx := memdb.Tx{}
+	account := models.Account{ID: uuid.New(), Balance: try(0)}
+
+	yesterday := now.Add(-24 * time.Hour)
+	if err := s.Reserve(tx, account, try(40000)); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Reserve(tx, account, try(40000)); err != nil {
+		t.Fatal(err)
+	}
+
+	// A withdrawal from an earlier day gives nothing back.
+	if err := s.Release(tx, account, try(40000), yesterday); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Reduce(tx, account, try(10000), now); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Release(tx, account, try(40000), now); err != nil {
+		t.Fatal(err)
+	}
+
+	usage, err := s.usage(tx, account, "2026-10-19")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := (models.WithdrawalUsage{BusinessDay: "2026-10-19", Withdrawn: try(30000), Count: 1}); usage != want {
+		t.Errorf("usage = %+v, want %+v", usage, want)
+	}
+}
+
+func TestRemainingPerTransaction(t *testing.T) {
+	tests := []struct {
+		name   string
+		debits []int64
+		want   money.Money
+	}{
+		{"per-transaction limit", nil, try(50000)},
+		{"bounded by daily left", []int64{50000, 20000}, try(30000)},
+		{"count used up", []int64{100, 100, 100}, try(0)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			now := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+			s := newTestService(t, &now)
+			tx := memdb.Tx{}
+			account := models.Account{ID: uuid.New(), Balance: try(0)}
+			for _, debit := range tt.debits {
+				if err := s.Reserve(tx, account, try(debit)); err != nil {
+					t.Fatal(err)
+				}
+			}
+			r, err := s.Remaining(tx, account)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if r.PerTransaction == nil || *r.PerTransaction != tt.want {
+				t.Errorf("PerTransaction = %v, want %v", r.PerTransaction, tt.want)
+			}
+		})
+	}
+}
+
+func TestSetRejects(t *testing.T) {
+	account := models.Account{ID: uuid.New(), Balance: try(0)}
+	tests := []struct {
+		limits models.WithdrawalLimits
+		err    error
+	}{
+		{models.WithdrawalLimits{Daily: try(-1)}, ErrInvalidLimit},
+		{models.WithdrawalLimits{DailyCount: -1}, ErrInvalidLimit},
+		{models.WithdrawalLimits{PerTransaction: money.New(100, money.USD)}, money.ErrCurrencyMismatch},
+	}
+	now := time.Now()
+	s := newTestService(t, &now)
+	for _, tt := range tests {
+		if err := s.Set(memdb.Tx{}, account, tt.limits); !errors.Is(err, tt.err) {
+			t.Errorf("Set(%+v) error = %v, want %v", tt.limits, err, tt.err)
+		}
+	}
+}
@@ -0,0 +1,160 @@
+package logging
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/url"
+	"reflect"
+	"strings"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+type requestIDKey struct{}
+
+const redacted = "[REDACTED]"
+
+// sensitiveKeys lists attribute and JSON field names whose values must never
+// reach the logs. Keys are compared lower-cased with '_' and '-' removed, so
+// "phone_number", "phoneNumber" and "Phone-Number" all match.
+var sensitiveKeys = map[string]bool{
+	"pin":           true,
+	"oldpin":        true,
+	"newpin":        true,
+	"phonenumber":   true,
+	"token":         true,
+	"accesstoken":   true,
+	"authorization": true,
+	"jwtsecret":     true,
+}
+
+// New returns a JSON logger writing to w at the given level ("debug", "info",
+// "warn" or "error"; anything else means info). Every record passes through
+// the redaction filter and carries the request ID found in its context.
+func New(w io.Writer, level string) *slog.Logger {
+	h := slog.NewJSONHandler(w, &slog.HandlerOptions{
+		Level:       ParseLevel(level),
+		ReplaceAttr: redactAttr,
+	})
+	return slog.New(contextHandler{h})
+}
+
+func ParseLevel(level string) slog.Level {
+	switch strings.ToLower(level) {
+	case "debug":
+		return slog.LevelDebug
+	case "warn", "warning":
+		return slog.LevelWarn
+	case "error":
+		return slog.LevelError
+	default:
+		return slog.LevelInfo
+	}
+}
+
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+func RequestID(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// RedactQuery masks sensitive query parameters so request paths can be logged.
+func RedactQuery(rawQuery string) string {
+	values, err := url.ParseQuery(rawQuery)
+	if err != nil {
+		return redacted
+	}
+	for key := range values {
+		if isSensitive(key) {
+			values[key] = []string{redacted}
+		}
+	}
+	return values.Encode()
+}
+
+// contextHandler adds the request ID and trace ID stored in the record's
+// context, so callers only need to use the *Context logging methods.
+type contextHandler struct {
+	slog.Handler
+}
+
+func (h contextHandler) Handle(ctx context.Context, r slog.Record) error {
+	if id := RequestID(ctx); id != "" {
+		r.AddAttrs(slog.String("request_id", id))
+	}
+	if sc := trace.SpanContextFromContext(ctx); sc.IsValid() {
+		r.AddAttrs(slog.String("trace_id", sc.TraceID().String()))
+	}
+	return h.Handler.Handle(ctx, r)
+}
+
+func (h contextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return contextHandler{h.Handler.WithAttrs(attrs)}
+}
+
+func (h contextHandler) WithGroup(name string) slog.Handler {
+	return contextHandler{h.Handler.WithGroup(name)}
+}
+
+func isSensitive(key string) bool {
+	key = strings.ToLower(key)
+	key = strings.NewReplacer("_", "", "-", "").Replace(key)
+	return sensitiveKeys[key]
+}
+
+func redactAttr(_ []string, a slog.Attr) slog.Attr {
+	if isSensitive(a.Key) {
+		return slog.String(a.Key, redacted)
+	}
+	if a.Value.Kind() == slog.KindAny {
+		if v, ok := redactValue(a.Value.Any()); ok {
+			return slog.Any(a.Key, v)
+		}
+	}
+	return a
+}
+
+// redactValue scrubs structs and maps logged with slog.Any by round-tripping
+// them through JSON, so their sensitive fields are caught by name as well.
+func redactValue(v any) (any, bool) {
+	if _, isErr := v.(error); isErr || v == nil {
+		return nil, false
+	}
+	kind := reflect.Indirect(reflect.ValueOf(v)).Kind()
+	if kind != reflect.Struct && kind != reflect.Map && kind != reflect.Slice {
+		return nil, false
+	}
+
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, false
+	}
+	var generic any
+	if err := json.Unmarshal(data, &generic); err != nil {
+		return nil, false
+	}
+	return scrub(generic), true
+}
+
+func scrub(v any) any {
+	switch t := v.(type) {
+	case map[string]any:
+		for key, val := range t {
+			if isSensitive(key) {
+				t[key] = redacted
+			} else {
+				t[key] = scrub(val)
+			}
+		}
+	case []any:
+		for i, val := range t {
+			t[i] = scrub(val)
+		}
+	}
+	return v
+}
@@ -1,28 +1,56 @@
 package main
 
 import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
 	"fmt"
-	"log"
+	"net/http"
 	"newapiprojet/adapter"
+	"newapiprojet/calendar"
 	"newapiprojet/config"
+	"newapiprojet/dormancy"
 	"newapiprojet/etcd"
+	"newapiprojet/exchange"
 	"newapiprojet/handlers"
-	"newapiprojet/middlewares"
+	"newapiprojet/interest"
+	"newapiprojet/jobs"
+	"newapiprojet/limits"
+	"newapiprojet/logging"
+	"newapiprojet/money"
+	"newapiprojet/routes"
+	"newapiprojet/terminals"
+	"newapiprojet/tracing"
+	"newapiprojet/validation"
 	"os"
+	"time"
+	_ "time/tzdata" // the runtime image has no zoneinfo for business_timezone
 
 	"github.com/gin-gonic/gin"
 	"github.com/joho/godotenv"
-	swaggerfiles "github.com/swaggo/files"
-	ginSwagger "github.com/swaggo/gin-swagger"
 	clientv3 "go.etcd.io/etcd/client/v3"
 )
 
+// @title ATM Management API
+// @version 1.0
+// @BasePath /v1
 func main() {
+	conf := config.GetConfig()
+	logger := logging.New(os.Stdout, conf.LogLevel)
+
 	err := godotenv.Load()
 	if err != nil {
-		log.Fatalf("Error loading .env file")
+		logger.Error("error loading .env file", "error", err)
+		os.Exit(1)
 	}
 
+	shutdownTracing, err := tracing.Setup(context.Background(), conf.TracingExporter, conf.TracingFile)
+	if err != nil {
+		logger.Error("unable to set up tracing", "error", err)
+		os.Exit(1)
+	}
+	defer shutdownTracing(context.Background())
+
 	client, err := etcd.NewEtcdClient(clientv3.Config{
 		Endpoints: []string{
 			"http://etcd1:2379",
@@ -31,54 +59,148 @@ func main() {
 		},
 	})
 	if err != nil {
-		panic(err)
+		logger.Error("unable to create etcd client", "error", err)
+		os.Exit(1)
 	}
 	defer client.Close()
 
-	etcdAdapter := adapter.NewEtcdAdapter(client)
+	etcdAdapter := adapter.NewInstrumentedAdapter(adapter.NewTracedAdapter(adapter.NewEtcdAdapter(client)))
 
-	conf := config.GetConfig()
+	if err := validation.Register(conf.MaxTransactionAmount); err != nil {
+		logger.Error("failed to register request validators", "error", err)
+		os.Exit(1)
+	}
 
-	gin.SetMode(gin.ReleaseMode)
-	r := gin.Default()
+	if conf.ExchangeRatesFile != "" {
+		n, err := exchange.NewService(etcdAdapter).LoadFile(context.Background(), conf.ExchangeRatesFile)
+		if err != nil {
+			logger.Error("unable to load exchange rates", "file", conf.ExchangeRatesFile, "error", err)
+			os.Exit(1)
+		}
+		logger.Info("exchange rates loaded", "file", conf.ExchangeRatesFile, "count", n)
+	}
 
-	mw := middlewares.NewNewapiprojetMiddlewares()
-	r.Use(mw.LogMiddleware())
-	r.Use(middlewares.RequestLimitMiddleware())
+	cal, err := calendar.New(conf.BusinessTimezone, conf.BusinessDayCutoff)
+	if err != nil {
+		logger.Error("invalid business day settings", "error", err)
+		os.Exit(1)
+	}
+	h := handlers.NewHandler(etcdAdapter, client, cal, limits.Defaults{
+		PerTransaction: conf.WithdrawalLimits.PerTransaction,
+		Daily:          conf.WithdrawalLimits.Daily,
+		DailyCount:     conf.WithdrawalLimits.DailyCount,
+	}, logger)
 
-	h := handlers.NewHandler(etcdAdapter)
+	jobsCtx, stopJobs := context.WithCancel(context.Background())
+	defer stopJobs()
+	hostname, _ := os.Hostname()
 
-	// User routes
-	secretKey := os.Getenv("JWT_SECRET")
-	if secretKey == "" {
-		fmt.Println("JWT secret key is not configured")
-		os.Exit(1)
+	if conf.InterestRunEveryMinutes > 0 {
+		tiers := make(map[money.Currency][]interest.Tier, len(conf.InterestTiers))
+		for code, list := range conf.InterestTiers {
+			currency, err := money.ParseCurrency(code)
+			if err != nil {
+				logger.Error("invalid interest tier currency", "currency", code, "error", err)
+				os.Exit(1)
+			}
+			for _, tier := range list {
+				tiers[currency] = append(tiers[currency], interest.Tier{From: tier.From, RateBps: tier.RateBps})
+			}
+		}
+		interestJob, err := interest.NewJob(etcdAdapter, cal, tiers, logger)
+		if err != nil {
+			logger.Error("invalid interest tiers", "error", err)
+			os.Exit(1)
+		}
+		go jobs.Start(jobsCtx, client, hostname, jobs.Job{
+			Name:  "interest",
+			Every: time.Duration(conf.InterestRunEveryMinutes) * time.Minute,
+			Run:   interestJob.Run,
+		}, logger)
 	}
-	fmt.Println("JWT Secret Key: ", secretKey)
 
-	userRoutes := r.Group("/user")
-	{
-		userRoutes.POST("/register", h.Register)
-		userRoutes.POST("/login", h.Login)
+	if conf.StandingOrdersRunEverySeconds > 0 {
+		retryAfter := time.Duration(conf.StandingOrderRetryMinutes) * time.Minute
+		go jobs.Start(jobsCtx, client, hostname, jobs.Job{
+			Name:  "standing_orders",
+			Every: time.Duration(conf.StandingOrdersRunEverySeconds) * time.Second,
+			Run: func(ctx context.Context) error {
+				return h.RunStandingOrders(ctx, retryAfter)
+			},
+		}, logger)
 	}
 
-	// Account routes
-	protected := r.Group("/account")
-	protected.Use(middlewares.AuthenticateJWT())
-	{
-		protected.GET("/balance/:accountID", h.GetAccountBalance)
-		protected.POST("/withdrawal", h.Withdrawal)
-		protected.POST("/deposit", h.Deposit)
-		protected.POST("/pin-change/:id", h.PinChange)
-		protected.DELETE("/deleteacc/:id", h.DeleteAccountByID)
+	if conf.DormancyRunEveryMinutes > 0 && conf.DormancyDays > 0 {
+		dormancyJob := dormancy.NewJob(etcdAdapter, time.Duration(conf.DormancyDays)*24*time.Hour, logger)
+		go jobs.Start(jobsCtx, client, hostname, jobs.Job{
+			Name:  "dormancy",
+			Every: time.Duration(conf.DormancyRunEveryMinutes) * time.Minute,
+			Run:   dormancyJob.Run,
+		}, logger)
 	}
 
-	protected2 := r.Group("/user")
-	protected2.Use(middlewares.AuthenticateJWT())
-	{
-		protected2.DELETE("delete/:id", h.DeleteUser)
+	// Every replica watches for hold leases running out.
+	go h.WatchHoldLeases(jobsCtx)
+	if conf.HoldExpiryRunEverySeconds > 0 {
+		go jobs.Start(jobsCtx, client, hostname, jobs.Job{
+			Name:  "hold_expiry",
+			Every: time.Duration(conf.HoldExpiryRunEverySeconds) * time.Second,
+			Run:   h.ExpireHolds,
+		}, logger)
 	}
 
-	r.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerfiles.Handler))
-	r.Run(fmt.Sprintf(":%d", conf.APIPort)) // listen and serve on
+	if conf.TerminalMonitorRunEverySeconds > 0 {
+		monitor := terminals.NewMonitor(etcdAdapter, terminals.HeartbeatTTL(conf.TerminalHeartbeatTTLSeconds), conf.LowCashThresholds, logger)
+		go jobs.Start(jobsCtx, client, hostname, jobs.Job{
+			Name:  "terminal_monitor",
+			Every: time.Duration(conf.TerminalMonitorRunEverySeconds) * time.Second,
+			Run:   monitor.Run,
+		}, logger)
+	}
+
+	if os.Getenv("JWT_SECRET") == "" {
+		logger.Error("JWT secret key is not configured")
+		os.Exit(1)
+	}
+
+	gin.SetMode(gin.ReleaseMode)
+	r := routes.NewRouter(h, logger)
+
+	srv := &http.Server{Addr: fmt.Sprintf(":%d", conf.APIPort), Handler: r}
+	if conf.TLSCertFile == "" || conf.TLSKeyFile == "" {
+		logger.Info("starting API server", "port", conf.APIPort)
+		err = srv.ListenAndServe()
+	} else {
+		if srv.TLSConfig, err = tlsConfig(conf.TLSClientCAFile); err != nil {
+			logger.Error("unable to load TLS client CAs", "file", conf.TLSClientCAFile, "error", err)
+			os.Exit(1)
+		}
+		logger.Info("starting API server over TLS", "port", conf.APIPort, "client_certificates", conf.TLSClientCAFile != "")
+		err = srv.ListenAndServeTLS(conf.TLSCertFile, conf.TLSKeyFile)
+	}
+	if err != nil {
+		logger.Error("API server stopped", "error", err)
+		os.Exit(1)
+	}
+}
+
+// tlsConfig asks clients for a certificate issued by a CA in caFile, so
+// terminals can authenticate with theirs. Clients without one can still
+// connect. With no caFile, client certificates are not requested.
+func tlsConfig(caFile string) (*tls.Config, error) {
+	conf := &tls.Config{MinVersion: tls.VersionTLS12}
+	if caFile == "" {
+		return conf, nil
+	}
+	pem, err := os.ReadFile(caFile)
+	if err != nil {
+		return nil, err
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(pem) {
+		return nil, fmt.Errorf("no certificates in %s", caFile)
+	}
+	conf.ClientAuth = tls.VerifyClientCertIfGiven
+	conf.ClientCAs = pool
+	return conf, nil
 }
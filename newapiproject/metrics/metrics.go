@@ -0,0 +1,151 @@
+package metrics
+
+import (
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "atm"
+
+// HTTP metrics, recorded by middlewares.MetricsMiddleware. The route label is
+// the gin route pattern, not the raw path, to keep cardinality bounded.
+var (
+	HTTPRequestsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "http_requests_total",
+		Help:      "Number of HTTP requests by method, route and status code.",
+	}, []string{"method", "route", "status"})
+
+	HTTPRequestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "HTTP request latency by method and route.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route"})
+)
+
+// Business metrics, recorded by the handlers once an operation is stored.
+var (
+	DepositsTotal = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "deposits_total",
+		Help:      "Number of successful deposits.",
+	})
+
+	WithdrawalsTotal = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "withdrawals_total",
+		Help:      "Number of successful withdrawals.",
+	})
+
+	AmountMovedTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "amount_moved_total",
+		Help:      "Sum of amounts moved by successful operations, in major units of the currency.",
+	}, []string{"operation", "currency"})
+
+	TransfersTotal = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "transfers_total",
+		Help:      "Number of successful transfers between accounts.",
+	})
+
+	StandingOrderRunsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "standing_order_runs_total",
+		Help:      "Number of standing order runs by outcome: executed, retrying or skipped.",
+	}, []string{"status"})
+
+	ReversalsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "reversals_total",
+		Help:      "Number of reversed transactions by operation and reason.",
+	}, []string{"operation", "reason"})
+
+	HoldsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "holds_total",
+		Help:      "Number of holds by outcome: placed, captured, released or expired.",
+	}, []string{"status"})
+
+	NotesDispensedTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "notes_dispensed_total",
+		Help:      "Number of banknotes paid out by ATMs, by currency and denomination.",
+	}, []string{"currency", "denomination"})
+
+	ReconciliationDiscrepanciesTotal = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "reconciliation_discrepancies_total",
+		Help:      "Number of ATM cash reconciliations whose counted cash did not match.",
+	})
+
+	TerminalAlertsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "terminal_alerts_total",
+		Help:      "Number of ATM alerts raised by kind: offline or low_cash.",
+	}, []string{"kind"})
+
+	TerminalsOffline = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "terminals_offline",
+		Help:      "Number of active ATMs that were offline at the last terminal monitor run.",
+	})
+
+	FailedLoginsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "failed_logins_total",
+		Help:      "Number of rejected login attempts by reason.",
+	}, []string{"reason"})
+
+	LockoutsTotal = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "lockouts_total",
+		Help:      "Number of logins locked out after too many wrong PINs.",
+	})
+)
+
+// Background job metrics, recorded by jobs.Start on the leader replica.
+var (
+	JobRunsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "job_runs_total",
+		Help:      "Number of background job runs by job and result.",
+	}, []string{"job", "result"})
+
+	JobRunDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "job_run_duration_seconds",
+		Help:      "Duration of background job runs by job.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"job"})
+)
+
+// etcd metrics, recorded by adapter.InstrumentedAdapter.
+var (
+	EtcdOpDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "etcd_op_duration_seconds",
+		Help:      "Latency of etcd operations including retries, by operation and key prefix.",
+		Buckets:   []float64{.001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5},
+	}, []string{"operation", "prefix"})
+
+	EtcdOpErrorsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "etcd_op_errors_total",
+		Help:      "Number of etcd operations that failed after all retries.",
+	}, []string{"operation", "prefix"})
+
+	EtcdOpRetriesTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "etcd_op_retries_total",
+		Help:      "Number of etcd operation retries after a transient error.",
+	}, []string{"operation", "prefix"})
+)
+
+func Handler() http.Handler {
+	return promhttp.Handler()
+}
@@ -1,8 +1,10 @@
 package middlewares
 
 import (
+	"errors"
 	"fmt"
-	"net/http"
+	"newapiprojet/apperrors"
+	"newapiprojet/i18n"
 	"os"
 	"strings"
 
@@ -12,7 +14,12 @@ import (
 )
 
 type Claims struct {
-	UserID uuid.UUID `json:"user_id"`
+	UserID   uuid.UUID `json:"user_id"`
+	Language string    `json:"lang,omitempty"`
+	// CardID, AccountIDs and TerminalID are set on card sessions.
+	CardID     *uuid.UUID  `json:"card_id,omitempty"`
+	AccountIDs []uuid.UUID `json:"account_ids,omitempty"`
+	TerminalID *uuid.UUID  `json:"terminal_id,omitempty"`
 	jwt.StandardClaims
 }
 
@@ -22,44 +29,48 @@ func AuthenticateJWT() gin.HandlerFunc {
 		authHeader := c.GetHeader("Authorization")
 
 		if authHeader == "" {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Yetkilendirme başlığı eksik."})
-			c.Abort()
+			abortWithError(c, apperrors.New(apperrors.Unauthorized).WithDetail(i18n.MsgAuthHeaderMissing))
 			return
 		}
 
 		tokenString := strings.TrimPrefix(authHeader, BearerSchema)
 		if tokenString == authHeader {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Geçersiz token formatı"})
-			c.Abort()
+			abortWithError(c, apperrors.New(apperrors.Unauthorized).WithDetail(i18n.MsgAuthSchemeInvalid))
 			return
 		}
 
 		secretKey := os.Getenv("JWT_SECRET")
 		if secretKey == "" {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "JWT gizli anahtarı yapılandırılmamış"})
-			c.Abort()
+			abortWithError(c, apperrors.Wrap(apperrors.Internal, errors.New("JWT secret key is not configured")))
 			return
 		}
 
 		token, err := jwt.ParseWithClaims(tokenString, &Claims{}, func(token *jwt.Token) (interface{}, error) {
 			if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-				return nil, fmt.Errorf("beklenmedik imzalama yöntemi: %v", token.Header["alg"])
+				return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
 			}
 			return []byte(secretKey), nil
 		})
 
 		if err != nil {
-			c.JSON(http.StatusForbidden, gin.H{"error": "Token geçerli değil"})
-			c.Abort()
+			abortWithError(c, apperrors.Wrap(apperrors.TokenInvalid, err))
 			return
 		}
 
 		if claims, ok := token.Claims.(*Claims); ok && token.Valid {
 			c.Set("userID", claims.UserID) // userID'yi UUID olarak ayarla
+			if claims.CardID != nil {
+				c.Set("cardID", *claims.CardID)
+				c.Set("cardAccountIDs", claims.AccountIDs)
+				c.Set("cardTerminalID", claims.TerminalID)
+			}
+			// Accept-Language wins over the preference stored in the token.
+			if lang, ok := i18n.Parse(claims.Language); ok && !c.GetBool("languageFromHeader") {
+				setLanguage(c, lang)
+			}
 			c.Next()
 		} else {
-			c.JSON(http.StatusForbidden, gin.H{"error": "Token geçerli değil"})
-			c.Abort()
+			abortWithError(c, apperrors.New(apperrors.TokenInvalid))
 		}
 	}
 }
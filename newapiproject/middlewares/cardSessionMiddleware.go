@@ -0,0 +1,21 @@
+package middlewares
+
+import (
+	"newapiprojet/apperrors"
+	"newapiprojet/i18n"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RejectCardSession keeps card sessions opened at an ATM away from routes
+// that need the user's own login, such as opening accounts or changing the
+// PIN. It must run after AuthenticateJWT.
+func RejectCardSession() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if _, ok := c.Get("cardID"); ok {
+			abortWithError(c, apperrors.New(apperrors.Forbidden).WithDetail(i18n.MsgCardSession))
+			return
+		}
+		c.Next()
+	}
+}
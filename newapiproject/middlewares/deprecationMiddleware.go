@@ -0,0 +1,28 @@
+package middlewares
+
+import (
+	"fmt"
+	"net/http"
+	"newapiprojet/config"
+
+	"github.com/gin-gonic/gin"
+)
+
+// DeprecationMiddleware marks responses from legacy, unversioned paths as
+// deprecated (RFC 9745) with the sunset date from config (RFC 8594), and links
+// to the same route under successorPrefix.
+func DeprecationMiddleware(successorPrefix string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		conf := config.GetConfig()
+		if !conf.LegacyDeprecatedAt.IsZero() {
+			c.Header("Deprecation", fmt.Sprintf("@%d", conf.LegacyDeprecatedAt.Unix()))
+		} else {
+			c.Header("Deprecation", "true")
+		}
+		if !conf.LegacySunsetAt.IsZero() {
+			c.Header("Sunset", conf.LegacySunsetAt.UTC().Format(http.TimeFormat))
+		}
+		c.Header("Link", fmt.Sprintf(`<%s%s>; rel="successor-version"`, successorPrefix, c.Request.URL.Path))
+		c.Next()
+	}
+}
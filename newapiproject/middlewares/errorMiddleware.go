@@ -0,0 +1,40 @@
+package middlewares
+
+import (
+	"newapiprojet/apperrors"
+	"newapiprojet/i18n"
+	"newapiprojet/logging"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ErrorMiddleware renders the last error attached with c.Error as an RFC 7807
+// problem+json response. Internal causes are logged, never sent to clients.
+func (m Newapiprojetmiddlewares) ErrorMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+
+		last := c.Errors.Last()
+		if last == nil || c.Writer.Written() {
+			return
+		}
+
+		appErr := apperrors.From(last.Err)
+		ctx := c.Request.Context()
+		if appErr.Status() >= 500 {
+			m.logger.ErrorContext(ctx, "request failed", "code", appErr.Code, "error", appErr)
+		} else if appErr.Err != nil {
+			m.logger.DebugContext(ctx, "request rejected", "code", appErr.Code, "error", appErr)
+		}
+
+		problem := appErr.Problem(c.Request.URL.Path, logging.RequestID(ctx), i18n.FromContext(ctx))
+		c.Header("Content-Type", apperrors.ProblemContentType)
+		c.JSON(problem.Status, problem)
+	}
+}
+
+// abortWithError stops the chain and leaves err for ErrorMiddleware to render.
+func abortWithError(c *gin.Context, err error) {
+	_ = c.Error(err)
+	c.Abort()
+}
@@ -0,0 +1,25 @@
+package middlewares
+
+import (
+	"newapiprojet/i18n"
+
+	"github.com/gin-gonic/gin"
+)
+
+// LanguageMiddleware picks the response language from the Accept-Language
+// header. Requests without a supported language keep the default until
+// AuthenticateJWT applies the user's stored preference.
+func LanguageMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		lang, ok := i18n.Parse(c.GetHeader("Accept-Language"))
+		c.Set("languageFromHeader", ok)
+		setLanguage(c, lang)
+		c.Next()
+	}
+}
+
+func setLanguage(c *gin.Context, lang i18n.Language) {
+	c.Set("language", lang)
+	c.Request = c.Request.WithContext(i18n.WithLanguage(c.Request.Context(), lang))
+	c.Header("Content-Language", string(lang))
+}
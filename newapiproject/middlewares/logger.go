@@ -1,9 +1,50 @@
 package middlewares
 
-type Newapiprojetmiddlewares struct{}
+import (
+	"log/slog"
+	"newapiprojet/logging"
+	"time"
 
-func NewNewapiprojetMiddlewares() *Newapiprojetmiddlewares {
+	"github.com/gin-gonic/gin"
+)
 
-	mw := Newapiprojetmiddlewares{}
-	return &mw
+func (m Newapiprojetmiddlewares) LogMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+
+		start := time.Now()
+		path := c.Request.URL.Path
+		raw := c.Request.URL.RawQuery
+
+		c.Next()
+
+		latency := time.Since(start)
+		statusCode := c.Writer.Status()
+		errorMessage := c.Errors.ByType(gin.ErrorTypePrivate).String()
+
+		if raw != "" {
+			path = path + "?" + logging.RedactQuery(raw)
+		}
+
+		level := slog.LevelInfo
+		if statusCode >= 500 {
+			level = slog.LevelError
+		} else if statusCode >= 400 {
+			level = slog.LevelWarn
+		}
+
+		attrs := []slog.Attr{
+			slog.String("method", c.Request.Method),
+			slog.String("path", path),
+			slog.String("route", c.FullPath()),
+			slog.Int("status", statusCode),
+			slog.String("client_ip", c.ClientIP()),
+			slog.Int("body_size", c.Writer.Size()),
+			slog.Duration("latency", latency),
+		}
+		if errorMessage != "" {
+			attrs = append(attrs, slog.String("error", errorMessage))
+		}
+
+		m.logger.LogAttrs(c.Request.Context(), level, "request completed", attrs...)
+	}
 }
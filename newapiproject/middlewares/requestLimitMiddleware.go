@@ -1,7 +1,7 @@
 package middlewares
 
 import (
-	"net/http"
+	"newapiprojet/apperrors"
 	"newapiprojet/config"
 
 	"github.com/gin-gonic/gin"
@@ -12,8 +12,7 @@ func RequestLimitMiddleware() gin.HandlerFunc {
 		conf := config.GetConfig()
 		err := conf.DecreaseRequestCount()
 		if err != nil {
-			c.JSON(http.StatusTooManyRequests, gin.H{"error": "Request limit exceeded"})
-			c.Abort()
+			abortWithError(c, apperrors.New(apperrors.RateLimited))
 			return
 		}
 		c.Next()
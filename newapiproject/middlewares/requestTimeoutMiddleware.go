@@ -0,0 +1,24 @@
+package middlewares
+
+import (
+	"context"
+	"newapiprojet/config"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RequestTimeoutMiddleware attaches a deadline to the request context so that
+// etcd calls made by the handlers are cancelled when the route's time budget
+// runs out or the client disconnects.
+func RequestTimeoutMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		conf := config.GetConfig()
+		timeout := conf.RouteTimeout(c.Request.Method, c.FullPath())
+
+		ctx, cancel := context.WithTimeout(c.Request.Context(), timeout)
+		defer cancel()
+
+		c.Request = c.Request.WithContext(ctx)
+		c.Next()
+	}
+}
@@ -1,52 +1,159 @@
 package models
 
 import (
+	"newapiprojet/money"
 	"time"
 
 	"github.com/google/uuid"
 )
 
 type User struct {
-	ID          uuid.UUID `json:"-"`
+	ID          uuid.UUID `json:"id"`
 	Username    string    `json:"username"`
 	FirstName   string    `json:"first_name"`
 	LastName    string    `json:"last_name"`
 	PhoneNumber string    `json:"phone_number"`
 	PIN         string    `json:"pin"`
+	Language    string    `json:"language,omitempty"` // preferred API language, "en" or "tr"
+	Role        Role      `json:"role,omitempty"`
 }
 
+// Role decides which staff-only endpoints a user may call. Users stored
+// before roles existed have no role and are treated as customers.
+type Role string
+
+const (
+	RoleCustomer Role = "customer"
+	RoleTeller   Role = "teller"
+	RoleAdmin    Role = "admin"
+)
+
 // Account Model
 type Account struct {
-	ID               uuid.UUID        `json:"id"`
-	UserID           uuid.UUID        `json:"user_id"`
-	Balance          int              `json:"balance"`
+	ID             uuid.UUID   `json:"id"`
+	UserID         uuid.UUID   `json:"user_id"`
+	Balance        money.Money `json:"balance"`
+	Overdraft      *Overdraft  `json:"overdraft,omitempty"`
+	OverdraftSince *time.Time  `json:"overdraft_since,omitempty"` // set while Balance is negative
+	// Status is empty for accounts opened before statuses existed, which
+	// are active; use ledger.Status to read it.
+	Status          AccountStatus `json:"status,omitempty"`
+	StatusChangedAt *time.Time    `json:"status_changed_at,omitempty"`
+	// LastActivityAt is the last time the customer used the account; the
+	// dormancy job flags accounts left unused for too long.
+	LastActivityAt *time.Time `json:"last_activity_at,omitempty"`
+	// Held is the total of the account's active holds, which the balance
+	// must cover on top of any debit; use ledger.Held to read it.
+	Held             *money.Money     `json:"held,omitempty"`
 	Deposits         []Deposit        `json:"deposits"`
 	Withdrawals      []Withdrawal     `json:"withdrawals"`
 	BalanceInquiries []BalanceInquiry `json:"balance_inquiries"`
 }
 
+// Overdraft is an approved facility that lets an account's balance go
+// below zero, down to -Limit.
+type Overdraft struct {
+	Limit   money.Money `json:"limit"`
+	RateBps int64       `json:"rate_bps"` // yearly interest on the overdrawn amount
+}
+
+// AccountStatus is where an account is in its lifecycle. Only active
+// accounts can be debited; frozen and dormant ones can still receive money,
+// closed ones nothing.
+type AccountStatus string
+
+const (
+	AccountActive  AccountStatus = "active"
+	AccountFrozen  AccountStatus = "frozen"
+	AccountDormant AccountStatus = "dormant"
+	AccountClosed  AccountStatus = "closed"
+)
+
+// AccountStatusEvent records a change of an account's status. ChangedBy is
+// nil for changes made by the system.
+type AccountStatusEvent struct {
+	AccountID uuid.UUID     `json:"account_id"`
+	From      AccountStatus `json:"from"`
+	To        AccountStatus `json:"to"`
+	Reason    string        `json:"reason,omitempty"`
+	ChangedBy *uuid.UUID    `json:"changed_by,omitempty"`
+	At        time.Time     `json:"at"`
+}
+
+// DormancyReport lists the accounts one run of the dormancy job made
+// dormant because they had no customer activity since InactiveSince.
+type DormancyReport struct {
+	ID            uuid.UUID        `json:"id"`
+	GeneratedAt   time.Time        `json:"generated_at"`
+	InactiveSince time.Time        `json:"inactive_since"`
+	Scanned       int              `json:"scanned"`
+	Accounts      []DormantAccount `json:"accounts"`
+}
+
+type DormantAccount struct {
+	AccountID      uuid.UUID   `json:"account_id"`
+	UserID         uuid.UUID   `json:"user_id"`
+	Balance        money.Money `json:"balance"`
+	LastActivityAt time.Time   `json:"last_activity_at"`
+}
+
+// OverdraftEvent records an account's balance crossing zero.
+type OverdraftEvent struct {
+	AccountID uuid.UUID          `json:"account_id"`
+	Type      OverdraftEventType `json:"type"`
+	Balance   money.Money        `json:"balance"` // balance right after the crossing
+	At        time.Time          `json:"at"`
+}
+
+type OverdraftEventType string
+
+const (
+	OverdraftEntered OverdraftEventType = "entered"
+	OverdraftLeft    OverdraftEventType = "left"
+)
+
 // Deposit Model
 type Deposit struct {
-	ID            uuid.UUID `json:"id"`
-	AccountID     uuid.UUID `json:"account_id"`
-	DepositAmount int       `json:"deposit_amount"`
-	DepositDate   time.Time `json:"deposit_date"`
+	ID            uuid.UUID   `json:"id"`
+	AccountID     uuid.UUID   `json:"account_id"`
+	DepositAmount money.Money `json:"deposit_amount"`
+	DepositDate   time.Time   `json:"deposit_date"`
+	// Conversion is set when the deposited currency differs from the
+	// account currency; DepositAmount is then what was handed in.
+	Conversion *Conversion `json:"conversion,omitempty"`
+	ReversalID *uuid.UUID  `json:"reversal_id,omitempty"`
+	// TerminalID is the ATM the deposit was made at.
+	TerminalID *uuid.UUID `json:"terminal_id,omitempty"`
 }
 
 // Withdrawal Model
 type Withdrawal struct {
-	ID               uuid.UUID `json:"id"`
-	AccountID        uuid.UUID `json:"account_id"`
-	WithdrawalAmount int       `json:"withdrawal_amount"`
-	WithdrawalDate   time.Time `json:"withdrawal_date"`
+	ID               uuid.UUID   `json:"id"`
+	AccountID        uuid.UUID   `json:"account_id"`
+	WithdrawalAmount money.Money `json:"withdrawal_amount"`
+	WithdrawalDate   time.Time   `json:"withdrawal_date"`
+	// Conversion is set when the paid-out currency differs from the
+	// account currency; WithdrawalAmount is then what was paid out.
+	Conversion *Conversion `json:"conversion,omitempty"`
+	FeeIDs     []uuid.UUID `json:"fee_ids,omitempty"`
+	ReversalID *uuid.UUID  `json:"reversal_id,omitempty"`
+	// HoldID is set when the withdrawal captured a hold.
+	HoldID *uuid.UUID `json:"hold_id,omitempty"`
+	// TerminalID is the ATM the withdrawal was made at.
+	TerminalID *uuid.UUID `json:"terminal_id,omitempty"`
+	// Notes are the banknotes the ATM dispensed.
+	Notes []Notes `json:"notes,omitempty"`
 }
 
 // BalanceInquiry Model
 type BalanceInquiry struct {
-	ID             uuid.UUID `json:"id"`
-	AccountID      uuid.UUID `json:"account_id"`
-	CurrentBalance int       `json:"current_balance"`
-	InquiryDate    time.Time `json:"inquiry_date"`
+	ID             uuid.UUID   `json:"id"`
+	AccountID      uuid.UUID   `json:"account_id"`
+	CurrentBalance money.Money `json:"current_balance"`
+	InquiryDate    time.Time   `json:"inquiry_date"`
+	FeeIDs         []uuid.UUID `json:"fee_ids,omitempty"`
+	// FeesWaived is set when the account could not cover the inquiry fee.
+	FeesWaived bool `json:"fees_waived,omitempty"`
 }
 
 // PinChange Model
@@ -57,3 +164,371 @@ type PinChange struct {
 	NewPIN     string    `json:"new_pin"`
 	ChangeDate time.Time `json:"change_date"`
 }
+
+// Transfer Model
+type Transfer struct {
+	ID            uuid.UUID   `json:"id"`
+	FromAccountID uuid.UUID   `json:"from_account_id"`
+	ToAccountID   uuid.UUID   `json:"to_account_id"`
+	Debited       money.Money `json:"debited"`
+	Credited      money.Money `json:"credited"`
+	TransferDate  time.Time   `json:"transfer_date"`
+	// Conversion is set when the two accounts use different currencies.
+	Conversion *Conversion `json:"conversion,omitempty"`
+	FeeIDs     []uuid.UUID `json:"fee_ids,omitempty"`
+	// StandingOrderID is set when a standing order made the transfer.
+	StandingOrderID *uuid.UUID `json:"standing_order_id,omitempty"`
+}
+
+// ExchangeRate is the mid-market price of one unit of Base in Quote, valid
+// from EffectiveAt until a later rate for the same pair takes effect.
+type ExchangeRate struct {
+	Base        money.Currency `json:"base"`
+	Quote       money.Currency `json:"quote"`
+	Rate        string         `json:"rate"`       // decimal, e.g. "32.8150"
+	SpreadBps   int64          `json:"spread_bps"` // charged on each side, in basis points
+	EffectiveAt time.Time      `json:"effective_at"`
+}
+
+// Conversion records the rate applied when money changed currency.
+type Conversion struct {
+	From        money.Money `json:"from"`
+	To          money.Money `json:"to"`
+	Rate        string      `json:"rate"`         // mid rate From -> To
+	AppliedRate string      `json:"applied_rate"` // rate after spread
+	SpreadBps   int64       `json:"spread_bps"`
+	EffectiveAt time.Time   `json:"effective_at"` // when the rate took effect
+}
+
+// WithdrawalLimits caps withdrawals from one account, in the account
+// currency. A zero value disables that limit.
+type WithdrawalLimits struct {
+	PerTransaction money.Money `json:"per_transaction"`
+	Daily          money.Money `json:"daily"`
+	DailyCount     int         `json:"daily_count"`
+}
+
+// WithdrawalUsage counts withdrawals made on one business day. It is reset
+// by the first withdrawal of a later business day.
+type WithdrawalUsage struct {
+	BusinessDay string      `json:"business_day"` // "2006-01-02"
+	Withdrawn   money.Money `json:"withdrawn"`
+	Count       int         `json:"count"`
+}
+
+// InterestAccrual is interest earned, or owed on an overdrawn balance, but
+// not yet posted. It covers the business days of Period up to LastDay.
+type InterestAccrual struct {
+	AccountID uuid.UUID `json:"account_id"`
+	Period    string    `json:"period"`   // "2006-01"
+	LastDay   string    `json:"last_day"` // "2006-01-02"
+	Accrued   string    `json:"accrued"`  // exact minor units as a fraction, e.g. "1234567/365"
+}
+
+// InterestPosting is accrued interest booked to an account at the end of a
+// month. A negative Amount is overdraft interest charged.
+type InterestPosting struct {
+	ID        uuid.UUID   `json:"id"`
+	AccountID uuid.UUID   `json:"account_id"`
+	Period    string      `json:"period"`
+	Amount    money.Money `json:"amount"`
+	PostedAt  time.Time   `json:"posted_at"`
+}
+
+// FeeOperation names an operation fees can be charged on.
+type FeeOperation string
+
+const (
+	FeeWithdrawal     FeeOperation = "withdrawal"
+	FeeBalanceInquiry FeeOperation = "balance_inquiry"
+	FeeTransfer       FeeOperation = "transfer"
+)
+
+// FeeSchedule holds the fee rules for accounts in one currency. Every rule
+// matching an operation is charged.
+type FeeSchedule struct {
+	Currency  money.Currency `json:"currency"`
+	Rules     []FeeRule      `json:"rules"`
+	UpdatedAt time.Time      `json:"updated_at"`
+}
+
+// FeeRule charges Fixed plus RateBps of the operation amount, capped at Max
+// when set. The first FreePerMonth matching operations in a calendar month
+// are free. ForeignOnly rules apply only at other banks' ATMs.
+type FeeRule struct {
+	Name         string       `json:"name"`
+	Operation    FeeOperation `json:"operation"`
+	ForeignOnly  bool         `json:"foreign_only,omitempty"`
+	FreePerMonth int          `json:"free_per_month,omitempty"`
+	Fixed        money.Money  `json:"fixed"`
+	RateBps      int64        `json:"rate_bps,omitempty"`
+	Max          *money.Money `json:"max,omitempty"`
+}
+
+// Fee is a charge posted as its own transaction, linked to the operation
+// that caused it.
+type Fee struct {
+	ID            uuid.UUID    `json:"id"`
+	AccountID     uuid.UUID    `json:"account_id"`
+	Operation     FeeOperation `json:"operation"`
+	Rule          string       `json:"rule"`
+	Amount        money.Money  `json:"amount"`
+	TransactionID uuid.UUID    `json:"transaction_id"` // the withdrawal, inquiry or transfer
+	ChargedAt     time.Time    `json:"charged_at"`
+}
+
+type StandingOrderStatus string
+
+const (
+	StandingOrderActive    StandingOrderStatus = "active"
+	StandingOrderPaused    StandingOrderStatus = "paused"
+	StandingOrderCancelled StandingOrderStatus = "cancelled"
+)
+
+// InsufficientFundsPolicy decides what a standing order does when the source
+// account cannot cover a run.
+type InsufficientFundsPolicy string
+
+const (
+	// RetryOnInsufficientFunds tries again later, up to MaxRetries times,
+	// before skipping the run.
+	RetryOnInsufficientFunds InsufficientFundsPolicy = "retry"
+	// SkipOnInsufficientFunds waits for the next scheduled run.
+	SkipOnInsufficientFunds InsufficientFundsPolicy = "skip"
+)
+
+// StandingOrder transfers Amount from one of the user's accounts on a cron
+// Schedule, evaluated in the bank's time zone.
+type StandingOrder struct {
+	ID                  uuid.UUID               `json:"id"`
+	UserID              uuid.UUID               `json:"user_id"`
+	FromAccountID       uuid.UUID               `json:"from_account_id"`
+	ToAccountID         uuid.UUID               `json:"to_account_id"`
+	Amount              money.Money             `json:"amount"`
+	Schedule            string                  `json:"schedule"`
+	Status              StandingOrderStatus     `json:"status"`
+	OnInsufficientFunds InsufficientFundsPolicy `json:"on_insufficient_funds"`
+	MaxRetries          int                     `json:"max_retries,omitempty"`
+	// Attempts counts failed tries of the current run.
+	Attempts  int               `json:"attempts,omitempty"`
+	NextRunAt time.Time         `json:"next_run_at"`
+	LastRun   *StandingOrderRun `json:"last_run,omitempty"`
+	CreatedAt time.Time         `json:"created_at"`
+}
+
+type StandingOrderRunStatus string
+
+const (
+	StandingOrderExecuted StandingOrderRunStatus = "executed"
+	StandingOrderRetrying StandingOrderRunStatus = "retrying"
+	StandingOrderSkipped  StandingOrderRunStatus = "skipped"
+)
+
+// StandingOrderRun is the outcome of a standing order's latest attempt.
+type StandingOrderRun struct {
+	At         time.Time              `json:"at"`
+	Status     StandingOrderRunStatus `json:"status"`
+	TransferID *uuid.UUID             `json:"transfer_id,omitempty"`
+	Error      string                 `json:"error,omitempty"`
+}
+
+// ReversibleType is the kind of transaction a reversal undoes.
+type ReversibleType string
+
+const (
+	ReversibleDeposit    ReversibleType = "deposit"
+	ReversibleWithdrawal ReversibleType = "withdrawal"
+)
+
+type ReversalReason string
+
+const (
+	ReversalDuplicate       ReversalReason = "duplicate"
+	ReversalWrongAccount    ReversalReason = "wrong_account"
+	ReversalWrongAmount     ReversalReason = "wrong_amount"
+	ReversalDispenseFailure ReversalReason = "dispense_failure" // the ATM did not pay out
+	ReversalFraud           ReversalReason = "fraud"
+	ReversalOther           ReversalReason = "other"
+)
+
+// Reversal is a compensating transaction posted by staff to undo a deposit
+// or withdrawal. The original record is kept and points back to it through
+// its ReversalID. Amount is in the account currency; fees charged with a
+// withdrawal are refunded as well.
+type Reversal struct {
+	ID              uuid.UUID      `json:"id"`
+	TransactionType ReversibleType `json:"transaction_type"`
+	TransactionID   uuid.UUID      `json:"transaction_id"`
+	AccountID       uuid.UUID      `json:"account_id"`
+	Amount          money.Money    `json:"amount"`
+	RefundedFeeIDs  []uuid.UUID    `json:"refunded_fee_ids,omitempty"`
+	FeesRefunded    *money.Money   `json:"fees_refunded,omitempty"`
+	Reason          ReversalReason `json:"reason"`
+	Note            string         `json:"note,omitempty"`
+	ReversedBy      uuid.UUID      `json:"reversed_by"`
+	ReversedAt      time.Time      `json:"reversed_at"`
+}
+
+type HoldStatus string
+
+const (
+	HoldActive   HoldStatus = "active"
+	HoldCaptured HoldStatus = "captured"
+	HoldReleased HoldStatus = "released"
+	HoldExpired  HoldStatus = "expired"
+)
+
+// Hold reserves Amount of an account's balance until it is captured,
+// released or expires at ExpiresAt. Capturing posts a withdrawal of up to
+// Amount and frees the rest.
+type Hold struct {
+	ID        uuid.UUID   `json:"id"`
+	AccountID uuid.UUID   `json:"account_id"`
+	UserID    uuid.UUID   `json:"user_id"`
+	Amount    money.Money `json:"amount"`
+	Reference string      `json:"reference,omitempty"` // the caller's own ID for the operation
+	Status    HoldStatus  `json:"status"`
+	CreatedAt time.Time   `json:"created_at"`
+	ExpiresAt time.Time   `json:"expires_at"`
+	// Captured and WithdrawalID are set once the hold is captured.
+	Captured     *money.Money `json:"captured,omitempty"`
+	WithdrawalID *uuid.UUID   `json:"withdrawal_id,omitempty"`
+	ClosedAt     *time.Time   `json:"closed_at,omitempty"`
+}
+
+type CardStatus string
+
+const (
+	CardActive  CardStatus = "active"
+	CardBlocked CardStatus = "blocked"
+)
+
+// Card is a debit card issued to a user. A card session, opened at an ATM
+// with the card number and the holder's PIN, can only use AccountIDs.
+type Card struct {
+	ID         uuid.UUID   `json:"id"`
+	PAN        string      `json:"pan"`
+	UserID     uuid.UUID   `json:"user_id"`
+	AccountIDs []uuid.UUID `json:"account_ids"`
+	// ExpiresAt is the first instant after the month printed on the card.
+	ExpiresAt   time.Time  `json:"expires_at"`
+	Status      CardStatus `json:"status"`
+	BlockReason string     `json:"block_reason,omitempty"`
+	BlockedAt   *time.Time `json:"blocked_at,omitempty"`
+	// FailedPINAttempts counts wrong PINs since the last successful login;
+	// the card is blocked when it reaches the limit.
+	FailedPINAttempts int       `json:"failed_pin_attempts,omitempty"`
+	IssuedBy          uuid.UUID `json:"issued_by"`
+	IssuedAt          time.Time `json:"issued_at"`
+}
+
+type TerminalStatus string
+
+const (
+	TerminalActive         TerminalStatus = "active"
+	TerminalDecommissioned TerminalStatus = "decommissioned"
+)
+
+// Terminal is an ATM allowed to call the account routes. It proves its
+// identity by signing requests with Secret or, when the API is served over
+// TLS, with the client certificate whose SHA-256 is CertFingerprint.
+type Terminal struct {
+	ID               uuid.UUID      `json:"id"`
+	Location         string         `json:"location"`
+	Branch           string         `json:"branch"`
+	Status           TerminalStatus `json:"status"`
+	Secret           string         `json:"secret"`
+	CertFingerprint  string         `json:"cert_fingerprint,omitempty"`
+	RegisteredBy     uuid.UUID      `json:"registered_by"`
+	RegisteredAt     time.Time      `json:"registered_at"`
+	DecommissionedAt *time.Time     `json:"decommissioned_at,omitempty"`
+}
+
+// Notes is a number of banknotes of one denomination.
+type Notes struct {
+	Denomination money.Money `json:"denomination"`
+	Count        int         `json:"count"`
+}
+
+// CashInventory is the cash in an ATM's cassettes, one entry per
+// denomination.
+type CashInventory struct {
+	TerminalID uuid.UUID `json:"terminal_id"`
+	Cassettes  []Notes   `json:"cassettes"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+type CashMovementType string
+
+const (
+	CashLoad   CashMovementType = "load"
+	CashUnload CashMovementType = "unload"
+)
+
+// CashMovement records notes put into or taken out of an ATM's cassettes
+// by an operator.
+type CashMovement struct {
+	ID         uuid.UUID        `json:"id"`
+	TerminalID uuid.UUID        `json:"terminal_id"`
+	Type       CashMovementType `json:"type"`
+	Notes      []Notes          `json:"notes"`
+	RecordedBy uuid.UUID        `json:"recorded_by"`
+	RecordedAt time.Time        `json:"recorded_at"`
+}
+
+// TerminalCash is cash that changed hands at an ATM: a withdrawal paid out
+// or a deposit accepted. It indexes the transaction by terminal and time for
+// reconciliation.
+type TerminalCash struct {
+	TransactionID   uuid.UUID      `json:"transaction_id"`
+	TransactionType ReversibleType `json:"transaction_type"`
+	Amount          money.Money    `json:"amount"`
+	At              time.Time      `json:"at"`
+	// DispenseFailed is set when the withdrawal was reversed because the
+	// ATM did not pay out.
+	DispenseFailed bool `json:"dispense_failed,omitempty"`
+}
+
+// ReconciliationLine compares, in one currency, the cash an ATM should hold
+// with the cash counted in it: Expected is Opening + Loaded - Unloaded -
+// Dispensed + Deposited, and Discrepancy is Counted - Expected.
+type ReconciliationLine struct {
+	Currency    money.Currency `json:"currency"`
+	Opening     money.Money    `json:"opening"`
+	Loaded      money.Money    `json:"loaded"`
+	Unloaded    money.Money    `json:"unloaded"`
+	Dispensed   money.Money    `json:"dispensed"`
+	Deposited   money.Money    `json:"deposited"`
+	Expected    money.Money    `json:"expected"`
+	Counted     money.Money    `json:"counted"`
+	Discrepancy money.Money    `json:"discrepancy"`
+}
+
+// Reconciliation is an ATM's cash reconciliation for the period since its
+// previous one, or since it was registered. The cash counted at the end of
+// one period is the opening cash of the next.
+type Reconciliation struct {
+	ID           uuid.UUID            `json:"id"`
+	TerminalID   uuid.UUID            `json:"terminal_id"`
+	BusinessDay  string               `json:"business_day"` // of To
+	From         time.Time            `json:"from"`
+	To           time.Time            `json:"to"`
+	Counted      []Notes              `json:"counted"`
+	Lines        []ReconciliationLine `json:"lines"`
+	Discrepancy  bool                 `json:"discrepancy"`
+	ReconciledBy uuid.UUID            `json:"reconciled_by"`
+}
+
+// TerminalHeartbeat is an ATM's latest heartbeat and the alerts raised for
+// it since, so each alert is raised once.
+type TerminalHeartbeat struct {
+	TerminalID uuid.UUID `json:"terminal_id"`
+	LastSeenAt time.Time `json:"last_seen_at"`
+	// Errors are the error states the ATM reported, such as
+	// "cassette_jam"; empty when it is healthy.
+	Errors []string `json:"errors,omitempty"`
+	// LeaseID is the etcd lease that keeps the terminal's online key.
+	LeaseID        int64            `json:"lease_id,omitempty"`
+	OfflineAlerted bool             `json:"offline_alerted,omitempty"`
+	LowCashAlerted []money.Currency `json:"low_cash_alerted,omitempty"`
+}
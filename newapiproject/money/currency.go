@@ -0,0 +1,75 @@
+package money
+
+import (
+	"slices"
+	"strings"
+)
+
+// Currency is an ISO 4217 alphabetic currency code.
+type Currency string
+
+const (
+	TRY Currency = "TRY"
+	USD Currency = "USD"
+	EUR Currency = "EUR"
+	GBP Currency = "GBP"
+	JPY Currency = "JPY"
+
+	// DefaultCurrency is used for amounts stored before Money existed.
+	DefaultCurrency = TRY
+)
+
+// minorUnits is the number of decimal places of each supported currency.
+var minorUnits = map[Currency]int{
+	TRY: 2,
+	USD: 2,
+	EUR: 2,
+	GBP: 2,
+	JPY: 0,
+}
+
+// ParseCurrency validates code case-insensitively and returns its Currency.
+func ParseCurrency(code string) (Currency, error) {
+	c := Currency(strings.ToUpper(strings.TrimSpace(code)))
+	if _, ok := minorUnits[c]; !ok {
+		return "", ErrUnknownCurrency
+	}
+	return c, nil
+}
+
+// Currencies returns the supported currencies sorted by code.
+func Currencies() []Currency {
+	currencies := make([]Currency, 0, len(minorUnits))
+	for c := range minorUnits {
+		currencies = append(currencies, c)
+	}
+	slices.Sort(currencies)
+	return currencies
+}
+
+// MinorUnits returns the number of decimal places used by c.
+func (c Currency) MinorUnits() int {
+	return minorUnits[c]
+}
+
+func (c Currency) Valid() bool {
+	_, ok := minorUnits[c]
+	return ok
+}
+
+// Symbol returns the display symbol for c, or the code itself.
+func (c Currency) Symbol() string {
+	switch c {
+	case TRY:
+		return "₺"
+	case USD:
+		return "$"
+	case EUR:
+		return "€"
+	case GBP:
+		return "£"
+	case JPY:
+		return "¥"
+	}
+	return string(c) + " "
+}
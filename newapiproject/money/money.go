@@ -0,0 +1,207 @@
+package money
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+var (
+	ErrCurrencyMismatch = errors.New("money: currency mismatch")
+	ErrOverflow         = errors.New("money: amount overflows")
+	ErrUnknownCurrency  = errors.New("money: unknown currency")
+	ErrInvalidAmount    = errors.New("money: invalid decimal amount")
+)
+
+// Money is an amount in the minor units of its currency (kuruş for TRY,
+// cents for USD). The zero value has no currency and is only useful as "unset".
+type Money struct {
+	minor    int64
+	currency Currency
+}
+
+// New returns minor units of currency.
+func New(minor int64, currency Currency) Money {
+	return Money{minor: minor, currency: currency}
+}
+
+// FromMajor returns whole units of currency, e.g. FromMajor(10, TRY) is 10.00 TRY.
+func FromMajor(major int64, currency Currency) (Money, error) {
+	minor, ok := mul(major, pow10(currency.MinorUnits()))
+	if !ok {
+		return Money{}, ErrOverflow
+	}
+	return New(minor, currency), nil
+}
+
+// Parse reads a decimal string such as "1250.5" in currency. More fractional
+// digits than the currency allows is an error rather than silent rounding.
+func Parse(decimal string, currency Currency) (Money, error) {
+	if !currency.Valid() {
+		return Money{}, ErrUnknownCurrency
+	}
+	s := strings.TrimSpace(decimal)
+	negative := strings.HasPrefix(s, "-")
+	s = strings.TrimPrefix(s, "-")
+
+	whole, frac, _ := strings.Cut(s, ".")
+	scale := currency.MinorUnits()
+	if whole == "" || len(frac) > scale || !digits(whole) || !digits(frac) {
+		return Money{}, fmt.Errorf("%w: %q", ErrInvalidAmount, decimal)
+	}
+	frac += strings.Repeat("0", scale-len(frac))
+
+	sign := ""
+	if negative {
+		sign = "-"
+	}
+	units, err := strconv.ParseInt(sign+whole+frac, 10, 64)
+	if err != nil {
+		return Money{}, ErrOverflow
+	}
+	return New(units, currency), nil
+}
+
+func (m Money) Minor() int64       { return m.minor }
+func (m Money) Currency() Currency { return m.currency }
+func (m Money) IsZero() bool       { return m.minor == 0 }
+func (m Money) IsPositive() bool   { return m.minor > 0 }
+func (m Money) IsNegative() bool   { return m.minor < 0 }
+
+func (m Money) Add(o Money) (Money, error) {
+	if m.currency != o.currency {
+		return Money{}, ErrCurrencyMismatch
+	}
+	sum := m.minor + o.minor
+	if (o.minor > 0 && sum < m.minor) || (o.minor < 0 && sum > m.minor) {
+		return Money{}, ErrOverflow
+	}
+	return New(sum, m.currency), nil
+}
+
+func (m Money) Sub(o Money) (Money, error) {
+	if o.minor == math.MinInt64 {
+		return Money{}, ErrOverflow
+	}
+	return m.Add(o.Neg())
+}
+
+// Neg returns -m. It saturates at the int64 bounds, which Sub rejects.
+func (m Money) Neg() Money {
+	if m.minor == math.MinInt64 {
+		return New(math.MaxInt64, m.currency)
+	}
+	return New(-m.minor, m.currency)
+}
+
+// Cmp compares m with o: -1 if m < o, 0 if equal and +1 if m > o.
+func (m Money) Cmp(o Money) (int, error) {
+	if m.currency != o.currency {
+		return 0, ErrCurrencyMismatch
+	}
+	switch {
+	case m.minor < o.minor:
+		return -1, nil
+	case m.minor > o.minor:
+		return 1, nil
+	}
+	return 0, nil
+}
+
+// Decimal formats m without currency, e.g. "1250.50" or "-3.00".
+func (m Money) Decimal() string {
+	scale := m.currency.MinorUnits()
+	sign, minor := "", uint64(m.minor)
+	if m.minor < 0 {
+		sign, minor = "-", uint64(-(m.minor+1))+1
+	}
+	s := strconv.FormatUint(minor, 10)
+	if scale == 0 {
+		return sign + s
+	}
+	if len(s) <= scale {
+		s = strings.Repeat("0", scale-len(s)+1) + s
+	}
+	return sign + s[:len(s)-scale] + "." + s[len(s)-scale:]
+}
+
+// Float64 returns m in major units. Use it only for metrics and display.
+func (m Money) Float64() float64 {
+	return float64(m.minor) / float64(pow10(m.currency.MinorUnits()))
+}
+
+func (m Money) String() string {
+	return m.Decimal() + " " + string(m.currency)
+}
+
+type jsonMoney struct {
+	Amount   string   `json:"amount"`
+	Currency Currency `json:"currency"`
+}
+
+// MarshalJSON encodes m as {"amount":"1250.50","currency":"TRY"}.
+func (m Money) MarshalJSON() ([]byte, error) {
+	return json.Marshal(jsonMoney{Amount: m.Decimal(), Currency: m.currency})
+}
+
+// UnmarshalJSON accepts the object form written by MarshalJSON. A bare JSON
+// number is read as whole units of DefaultCurrency, which is how balances and
+// amounts were stored and sent before Money existed.
+func (m *Money) UnmarshalJSON(data []byte) error {
+	if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 && (trimmed[0] == '-' || (trimmed[0] >= '0' && trimmed[0] <= '9')) {
+		major, err := strconv.ParseInt(string(trimmed), 10, 64)
+		if err != nil {
+			return fmt.Errorf("%w: %s", ErrInvalidAmount, trimmed)
+		}
+		parsed, err := FromMajor(major, DefaultCurrency)
+		if err != nil {
+			return err
+		}
+		*m = parsed
+		return nil
+	}
+
+	var raw jsonMoney
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	currency, err := ParseCurrency(string(raw.Currency))
+	if err != nil {
+		return err
+	}
+	parsed, err := Parse(raw.Amount, currency)
+	if err != nil {
+		return err
+	}
+	*m = parsed
+	return nil
+}
+
+func digits(s string) bool {
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+func pow10(n int) int64 {
+	p := int64(1)
+	for i := 0; i < n; i++ {
+		p *= 10
+	}
+	return p
+}
+
+func mul(a, b int64) (int64, bool) {
+	if a == 0 || b == 0 {
+		return 0, true
+	}
+	c := a * b
+	return c, c/b == a && !(a == -1 && b == math.MinInt64) && !(b == -1 && a == math.MinInt64)
+}
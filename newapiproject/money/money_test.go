@@ -0,0 +1,136 @@
+package money
+
+import (
+	"encoding/json"
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestAddSub(t *testing.T) {
+	tests := []struct {
+		name    string
+		a, b    Money
+		sum     Money
+		diff    Money
+		sumErr  error
+		diffErr error
+	}{
+		{"simple", New(150, TRY), New(50, TRY), New(200, TRY), New(100, TRY), nil, nil},
+		{"negative result", New(50, TRY), New(150, TRY), New(200, TRY), New(-100, TRY), nil, nil},
+		{"add overflows", New(math.MaxInt64, TRY), New(1, TRY), Money{}, New(math.MaxInt64-1, TRY), ErrOverflow, nil},
+		{"sub overflows", New(math.MinInt64, TRY), New(1, TRY), New(math.MinInt64+1, TRY), Money{}, nil, ErrOverflow},
+		{"sub of min int64", New(0, TRY), New(math.MinInt64, TRY), New(math.MinInt64, TRY), Money{}, nil, ErrOverflow},
+		{"add underflows", New(math.MinInt64, TRY), New(-1, TRY), Money{}, New(math.MinInt64+1, TRY), ErrOverflow, nil},
+		{"currency mismatch", New(100, TRY), New(100, USD), Money{}, Money{}, ErrCurrencyMismatch, ErrCurrencyMismatch},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sum, err := tt.a.Add(tt.b)
+			if !errors.Is(err, tt.sumErr) {
+				t.Fatalf("Add error = %v, want %v", err, tt.sumErr)
+			}
+			if err == nil && sum != tt.sum {
+				t.Errorf("Add = %v, want %v", sum, tt.sum)
+			}
+			diff, err := tt.a.Sub(tt.b)
+			if !errors.Is(err, tt.diffErr) {
+				t.Fatalf("Sub error = %v, want %v", err, tt.diffErr)
+			}
+			if err == nil && diff != tt.diff {
+				t.Errorf("Sub = %v, want %v", diff, tt.diff)
+			}
+		})
+	}
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		decimal  string
+		currency Currency
+		want     Money
+		err      error
+	}{
+		{"1250.5", TRY, New(125050, TRY), nil},
+		{"1250.50", TRY, New(125050, TRY), nil},
+		{"-3", USD, New(-300, USD), nil},
+		{"0.01", EUR, New(1, EUR), nil},
+		{"1500", JPY, New(1500, JPY), nil},
+		{"1.005", TRY, Money{}, ErrInvalidAmount},
+		{"1.5", JPY, Money{}, ErrInvalidAmount},
+		{"1e3", TRY, Money{}, ErrInvalidAmount},
+		{".5", TRY, Money{}, ErrInvalidAmount},
+		{"99999999999999999999", TRY, Money{}, ErrOverflow},
+		{"10", Currency("XXX"), Money{}, ErrUnknownCurrency},
+	}
+	for _, tt := range tests {
+		t.Run(tt.decimal+" "+string(tt.currency), func(t *testing.T) {
+			got, err := Parse(tt.decimal, tt.currency)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("Parse error = %v, want %v", err, tt.err)
+			}
+			if got != tt.want {
+				t.Errorf("Parse = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestJSONRoundTrip(t *testing.T) {
+	tests := []struct {
+		money Money
+		json  string
+	}{
+		{New(125050, TRY), `{"amount":"1250.50","currency":"TRY"}`},
+		{New(-5, USD), `{"amount":"-0.05","currency":"USD"}`},
+		{New(0, EUR), `{"amount":"0.00","currency":"EUR"}`},
+		{New(1500, JPY), `{"amount":"1500","currency":"JPY"}`},
+		{New(math.MinInt64, TRY), `{"amount":"-92233720368547758.08","currency":"TRY"}`},
+		{New(math.MaxInt64, TRY), `{"amount":"92233720368547758.07","currency":"TRY"}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.json, func(t *testing.T) {
+			data, err := json.Marshal(tt.money)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(data) != tt.json {
+				t.Errorf("Marshal = %s, want %s", data, tt.json)
+			}
+			var got Money
+			if err := json.Unmarshal(data, &got); err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.money {
+				t.Errorf("round trip = %v, want %v", got, tt.money)
+			}
+		})
+	}
+}
+
+func TestUnmarshalJSON(t *testing.T) {
+	tests := []struct {
+		json string
+		want Money
+		err  bool
+	}{
+		{`100`, New(10000, DefaultCurrency), false},
+		{`-7`, New(-700, DefaultCurrency), false},
+		{`1.5`, Money{}, true},
+		{`92233720368547759`, Money{}, true},
+		{`{"amount":"1.234","currency":"USD"}`, Money{}, true},
+		{`{"amount":"1","currency":"ABC"}`, Money{}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.json, func(t *testing.T) {
+			var got Money
+			err := json.Unmarshal([]byte(tt.json), &got)
+			if (err != nil) != tt.err {
+				t.Fatalf("Unmarshal error = %v, want error %v", err, tt.err)
+			}
+			if got != tt.want {
+				t.Errorf("Unmarshal = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
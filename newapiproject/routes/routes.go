@@ -0,0 +1,59 @@
+package routes
+
+import (
+	"log/slog"
+
+	"newapiprojet/handlers"
+	"newapiprojet/metrics"
+	"newapiprojet/middlewares"
+	"newapiprojet/tracing"
+
+	"github.com/gin-gonic/gin"
+	swaggerfiles "github.com/swaggo/files"
+	ginSwagger "github.com/swaggo/gin-swagger"
+)
+
+// Version is one mounted revision of the API. Each version registers its own
+// routes under Prefix, so several can be served side by side. Legacy
+// registers the routes the unprefixed paths alias, if the version has any.
+type Version struct {
+	Prefix   string
+	Register func(rg *gin.RouterGroup, h *handlers.Handler)
+	Legacy   func(rg *gin.RouterGroup, h *handlers.Handler)
+}
+
+// Versions lists every API version served, oldest first. A v2 is added by
+// appending {Prefix: "/v2", Register: registerV2}.
+var Versions = []Version{
+	{Prefix: "/v1", Register: registerV1, Legacy: registerV1Legacy},
+}
+
+// legacy is the version the unprefixed paths alias. They answer with
+// Deprecation and Sunset headers pointing at the prefixed route.
+var legacy = Versions[0]
+
+func NewRouter(h *handlers.Handler, logger *slog.Logger) *gin.Engine {
+	r := gin.New()
+	r.Use(gin.Recovery())
+
+	// Scrapes are registered before the request limit so they do not use up the daily quota.
+	r.GET("/metrics", gin.WrapH(metrics.Handler()))
+
+	mw := middlewares.NewNewapiprojetMiddlewares(logger)
+	r.Use(tracing.Middleware())
+	r.Use(tracing.Wrap("RequestID", middlewares.RequestIDMiddleware()))
+	r.Use(tracing.Wrap("Metrics", middlewares.MetricsMiddleware()))
+	r.Use(tracing.Wrap("Language", middlewares.LanguageMiddleware()))
+	r.Use(tracing.Wrap("Log", mw.LogMiddleware()))
+	r.Use(tracing.Wrap("Error", mw.ErrorMiddleware()))
+	r.Use(tracing.Wrap("RequestLimit", middlewares.RequestLimitMiddleware()))
+	r.Use(tracing.Wrap("RequestTimeout", middlewares.RequestTimeoutMiddleware()))
+
+	for _, v := range Versions {
+		v.Register(r.Group(v.Prefix), h)
+	}
+	legacy.Legacy(r.Group("", middlewares.DeprecationMiddleware(legacy.Prefix)), h)
+
+	r.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerfiles.Handler))
+	return r
+}
@@ -0,0 +1,61 @@
+package routes
+
+import (
+	"io"
+	"log/slog"
+	"slices"
+	"strings"
+	"testing"
+
+	"newapiprojet/calendar"
+	"newapiprojet/database/memdb"
+	"newapiprojet/handlers"
+	"newapiprojet/limits"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestLegacyRoutes(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	cal, err := calendar.New("Europe/Istanbul", "00:00")
+	if err != nil {
+		t.Fatal(err)
+	}
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	db := memdb.New()
+	r := NewRouter(handlers.NewHandler(db, db, cal, limits.Defaults{}, logger), logger)
+
+	var got []string
+	v1 := map[string]bool{}
+	for _, route := range r.Routes() {
+		if path, ok := strings.CutPrefix(route.Path, legacy.Prefix+"/"); ok {
+			v1[route.Method+" /"+path] = true
+			continue
+		}
+		if route.Path == "/metrics" || strings.HasPrefix(route.Path, "/swagger/") {
+			continue
+		}
+		got = append(got, route.Method+" "+route.Path)
+	}
+	slices.Sort(got)
+
+	// Only the routes the API had before it was versioned are aliased.
+	want := []string{
+		"DELETE /account/deleteacc/:id",
+		"DELETE /user/delete/:id",
+		"GET /account/balance/:accountID",
+		"POST /account/deposit",
+		"POST /account/pin-change/:id",
+		"POST /account/withdrawal",
+		"POST /user/login",
+		"POST /user/register",
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("legacy routes = %v, want %v", got, want)
+	}
+	for _, route := range got {
+		if !v1[route] {
+			t.Errorf("legacy route %s has no %s route", route, legacy.Prefix)
+		}
+	}
+}
@@ -0,0 +1,161 @@
+package routes
+
+import (
+	"newapiprojet/handlers"
+	"newapiprojet/middlewares"
+	"newapiprojet/models"
+	"newapiprojet/tracing"
+
+	"github.com/gin-gonic/gin"
+)
+
+// accountAuth authenticates the account routes: a user or card session, from
+// a registered ATM.
+func accountAuth(h *handlers.Handler) []gin.HandlerFunc {
+	return []gin.HandlerFunc{
+		tracing.Wrap("AuthenticateJWT", middlewares.AuthenticateJWT()),
+		tracing.Wrap("AuthenticateTerminal", h.AuthenticateTerminal()),
+		tracing.Wrap("CheckCardSession", h.CheckCardSession()),
+		tracing.HandlerSpan(),
+	}
+}
+
+// userAuth authenticates routes that need the user's own login.
+func userAuth() []gin.HandlerFunc {
+	return []gin.HandlerFunc{
+		tracing.Wrap("AuthenticateJWT", middlewares.AuthenticateJWT()),
+		middlewares.RejectCardSession(),
+		tracing.HandlerSpan(),
+	}
+}
+
+func registerV1(rg *gin.RouterGroup, h *handlers.Handler) {
+	// User routes
+	userRoutes := rg.Group("/user")
+	userRoutes.Use(tracing.HandlerSpan())
+	{
+		userRoutes.POST("/register", h.Register)
+		userRoutes.POST("/login", h.Login)
+	}
+
+	// Card sessions, opened at an ATM
+	cardRoutes := rg.Group("/card")
+	cardRoutes.Use(tracing.Wrap("AuthenticateTerminal", h.AuthenticateTerminal()), tracing.HandlerSpan())
+	{
+		cardRoutes.POST("/login", h.CardLogin)
+	}
+
+	// Terminal routes, called by the ATM itself
+	terminalRoutes := rg.Group("/terminal")
+	terminalRoutes.Use(tracing.Wrap("AuthenticateTerminal", h.AuthenticateTerminal()), tracing.HandlerSpan())
+	{
+		terminalRoutes.POST("/heartbeat", h.Heartbeat)
+	}
+
+	// Account routes
+	protected := rg.Group("/account")
+	protected.Use(accountAuth(h)...)
+	{
+		protected.GET("/balance/:accountID", h.GetAccountBalance)
+		protected.GET("/limits/:accountID", h.GetWithdrawalLimits)
+		protected.POST("/withdrawal", h.Withdrawal)
+		protected.POST("/deposit", h.Deposit)
+		protected.POST("/transfer", h.Transfer)
+		protected.POST("/holds", h.PlaceHold)
+		protected.GET("/holds/:id", h.GetHold)
+		protected.POST("/holds/:id/capture", h.CaptureHold)
+		protected.POST("/holds/:id/release", h.ReleaseHold)
+	}
+
+	// Account routes that card sessions cannot use
+	userOnly := protected.Group("")
+	userOnly.Use(middlewares.RejectCardSession())
+	{
+		userOnly.POST("/open", h.OpenAccount)
+		userOnly.POST("/pin-change/:id", h.PinChange)
+		userOnly.DELETE("/deleteacc/:id", h.DeleteAccountByID)
+		userOnly.POST("/standing-orders", h.CreateStandingOrder)
+		userOnly.GET("/standing-orders", h.ListStandingOrders)
+		userOnly.POST("/standing-orders/:id/pause", h.PauseStandingOrder)
+		userOnly.POST("/standing-orders/:id/resume", h.ResumeStandingOrder)
+		userOnly.DELETE("/standing-orders/:id", h.CancelStandingOrder)
+	}
+
+	protected2 := rg.Group("/user")
+	protected2.Use(userAuth()...)
+	{
+		protected2.DELETE("delete/:id", h.DeleteUser)
+	}
+
+	// Exchange rates
+	rates := rg.Group("/rates")
+	rates.Use(tracing.Wrap("AuthenticateJWT", middlewares.AuthenticateJWT()), tracing.HandlerSpan())
+	{
+		rates.GET("", h.GetExchangeRates)
+	}
+
+	// Staff routes
+	staff := rg.Group("/staff")
+	staff.Use(tracing.Wrap("AuthenticateJWT", middlewares.AuthenticateJWT()), middlewares.RejectCardSession(), h.RequireRole(models.RoleTeller, models.RoleAdmin), tracing.HandlerSpan())
+	{
+		staff.PUT("/accounts/:id/limits", h.SetWithdrawalLimits)
+		staff.PUT("/accounts/:id/overdraft", h.SetOverdraft)
+		staff.PUT("/accounts/:id/status", h.SetAccountStatus)
+		staff.POST("/deposits/:id/reversal", h.ReverseDeposit)
+		staff.POST("/withdrawals/:id/reversal", h.ReverseWithdrawal)
+		staff.GET("/reports/dormancy", h.DormancyReports)
+		staff.POST("/cards", h.IssueCard)
+		staff.POST("/cards/:id/block", h.BlockCard)
+		staff.GET("/terminals/:id/cassettes", h.GetCassettes)
+		staff.POST("/terminals/:id/loads", h.LoadCash)
+		staff.POST("/terminals/:id/unloads", h.UnloadCash)
+		staff.POST("/terminals/:id/reconciliations", h.ReconcileTerminal)
+		staff.GET("/terminals/:id/reconciliations", h.TerminalReconciliations)
+	}
+
+	// Admin routes
+	admin := rg.Group("/admin")
+	admin.Use(tracing.Wrap("AuthenticateJWT", middlewares.AuthenticateJWT()), middlewares.RejectCardSession(), h.RequireRole(models.RoleAdmin), tracing.HandlerSpan())
+	{
+		admin.PUT("/users/:id/role", h.SetUserRole)
+		admin.POST("/rates", h.SetExchangeRate)
+		admin.GET("/fees", h.GetFeeSchedules)
+		admin.PUT("/fees/:currency", h.SetFeeSchedule)
+		admin.GET("/terminals", h.ListTerminals)
+		admin.POST("/terminals", h.RegisterTerminal)
+		admin.POST("/terminals/:id/decommission", h.DecommissionTerminal)
+	}
+}
+
+// registerV1Legacy registers the routes the API had before it was versioned,
+// which the unprefixed paths still alias. Routes added since are only served
+// under /v1.
+func registerV1Legacy(rg *gin.RouterGroup, h *handlers.Handler) {
+	userRoutes := rg.Group("/user")
+	userRoutes.Use(tracing.HandlerSpan())
+	{
+		userRoutes.POST("/register", h.Register)
+		userRoutes.POST("/login", h.Login)
+	}
+
+	protected := rg.Group("/account")
+	protected.Use(accountAuth(h)...)
+	{
+		protected.GET("/balance/:accountID", h.GetAccountBalance)
+		protected.POST("/withdrawal", h.Withdrawal)
+		protected.POST("/deposit", h.Deposit)
+	}
+
+	userOnly := protected.Group("")
+	userOnly.Use(middlewares.RejectCardSession())
+	{
+		userOnly.POST("/pin-change/:id", h.PinChange)
+		userOnly.DELETE("/deleteacc/:id", h.DeleteAccountByID)
+	}
+
+	protected2 := rg.Group("/user")
+	protected2.Use(userAuth()...)
+	{
+		protected2.DELETE("delete/:id", h.DeleteUser)
+	}
+}
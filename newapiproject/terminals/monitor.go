@@ -0,0 +1,198 @@
+package terminals
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"slices"
+	"time"
+
+	"newapiprojet/cassettes"
+	"newapiprojet/database"
+	"newapiprojet/metrics"
+	"newapiprojet/models"
+	"newapiprojet/money"
+)
+
+// Terminals report in with heartbeats:
+//
+//	terminal_heartbeats/<terminalID>  models.TerminalHeartbeat
+//	terminal_online/<terminalID>      time of the last heartbeat, under an
+//	                                  etcd lease that runs out once
+//	                                  heartbeats stop
+const (
+	HeartbeatsPrefix = "terminal_heartbeats/"
+	OnlinePrefix     = "terminal_online/"
+)
+
+// DefaultHeartbeatTTL is how long a terminal stays online after a heartbeat
+// when the configuration does not say.
+const DefaultHeartbeatTTL = 90 * time.Second
+
+// HeartbeatTTL returns seconds as a duration, or DefaultHeartbeatTTL if it
+// is not positive.
+func HeartbeatTTL(seconds int) time.Duration {
+	if seconds <= 0 {
+		return DefaultHeartbeatTTL
+	}
+	return time.Duration(seconds) * time.Second
+}
+
+// Online reports whether a terminal is online: its online key is present
+// and its last heartbeat, hb, is no older than ttl. A terminal that never
+// sent a heartbeat has a nil hb.
+func Online(hb *models.TerminalHeartbeat, present bool, ttl time.Duration, now time.Time) bool {
+	return hb != nil && present && now.Sub(hb.LastSeenAt) <= ttl
+}
+
+// LowCash returns the currencies in which the cassettes hold less than
+// thresholds, given in major units by currency code. Only currencies the
+// terminal has cassettes for are checked.
+func LowCash(inventory models.CashInventory, thresholds map[string]int64) []money.Currency {
+	var low []money.Currency
+	checked := map[money.Currency]bool{}
+	for _, c := range inventory.Cassettes {
+		currency := c.Denomination.Currency()
+		if checked[currency] {
+			continue
+		}
+		checked[currency] = true
+		threshold, ok := thresholds[string(currency)]
+		if !ok {
+			continue
+		}
+		limit, err := money.FromMajor(threshold, currency)
+		if err != nil {
+			continue
+		}
+		if cassettes.Total(inventory.Cassettes, currency).Minor() < limit.Minor() {
+			low = append(low, currency)
+		}
+	}
+	slices.SortFunc(low, func(a, b money.Currency) int { return cmp.Compare(a, b) })
+	return low
+}
+
+// Monitor raises an alert when an active terminal goes offline or its cash
+// falls below a threshold. Each alert is raised once, until the terminal
+// sends a heartbeat again or is refilled. Terminals that never sent a
+// heartbeat are not checked.
+type Monitor struct {
+	db         database.Database
+	ttl        time.Duration
+	thresholds map[string]int64
+	logger     *slog.Logger
+	now        func() time.Time
+}
+
+func NewMonitor(db database.Database, ttl time.Duration, thresholds map[string]int64, logger *slog.Logger) *Monitor {
+	return &Monitor{db: db, ttl: ttl, thresholds: thresholds, logger: logger, now: time.Now}
+}
+
+// Run checks every active terminal once.
+func (m *Monitor) Run(ctx context.Context) error {
+	now := m.now()
+	list, err := m.db.List(ctx, "terminals/")
+	if err != nil {
+		return fmt.Errorf("listing terminals: %w", err)
+	}
+
+	var checked, offline, failed int
+	for key, data := range list {
+		var terminal models.Terminal
+		if err := json.Unmarshal(data, &terminal); err != nil {
+			failed++
+			m.logger.ErrorContext(ctx, "terminal check failed", "key", key, "error", err)
+			continue
+		}
+		if terminal.Status != models.TerminalActive {
+			continue
+		}
+		checked++
+		down, err := m.check(ctx, terminal, now)
+		if err != nil {
+			failed++
+			m.logger.ErrorContext(ctx, "terminal check failed", "terminal_id", terminal.ID, "error", err)
+			continue
+		}
+		if down {
+			offline++
+		}
+	}
+	metrics.TerminalsOffline.Set(float64(offline))
+
+	m.logger.InfoContext(ctx, "terminals checked", "terminals", checked, "offline", offline, "failed", failed)
+	if failed > 0 {
+		return fmt.Errorf("terminal check failed for %d terminals", failed)
+	}
+	return nil
+}
+
+// check updates one terminal's alert state, raises its new alerts and
+// reports whether it is offline.
+func (m *Monitor) check(ctx context.Context, terminal models.Terminal, now time.Time) (bool, error) {
+	id := terminal.ID.String()
+
+	var hb models.TerminalHeartbeat
+	var inventory models.CashInventory
+	var reported, offline, wentOffline bool
+	var newlyLow []money.Currency
+	err := m.db.Atomic(ctx, func(tx database.Tx) error {
+		reported, offline, wentOffline, newlyLow = false, false, false, nil
+
+		data := tx.Get(HeartbeatsPrefix + id)
+		if data == nil {
+			return nil
+		}
+		reported = true
+		if err := json.Unmarshal(data, &hb); err != nil {
+			return fmt.Errorf("unmarshaling heartbeat: %w", err)
+		}
+		inventory = models.CashInventory{TerminalID: terminal.ID}
+		if data := tx.Get("cassettes/" + id); data != nil {
+			if err := json.Unmarshal(data, &inventory); err != nil {
+				return fmt.Errorf("unmarshaling cash inventory: %w", err)
+			}
+		}
+
+		changed := false
+		offline = !Online(&hb, tx.Get(OnlinePrefix+id) != nil, m.ttl, now)
+		if offline && !hb.OfflineAlerted {
+			hb.OfflineAlerted, wentOffline, changed = true, true, true
+		}
+		low := LowCash(inventory, m.thresholds)
+		for _, currency := range low {
+			if !slices.Contains(hb.LowCashAlerted, currency) {
+				newlyLow = append(newlyLow, currency)
+			}
+		}
+		if !slices.Equal(low, hb.LowCashAlerted) {
+			hb.LowCashAlerted, changed = low, true
+		}
+		if !changed {
+			return nil
+		}
+
+		data, err := json.Marshal(hb)
+		if err != nil {
+			return fmt.Errorf("marshaling heartbeat: %w", err)
+		}
+		tx.Put(HeartbeatsPrefix+id, data)
+		return nil
+	})
+	if err != nil || !reported {
+		return false, err
+	}
+
+	if wentOffline {
+		metrics.TerminalAlertsTotal.WithLabelValues("offline").Inc()
+		m.logger.ErrorContext(ctx, "terminal offline", "alert", "offline", "terminal_id", terminal.ID, "location", terminal.Location, "branch", terminal.Branch, "last_seen_at", hb.LastSeenAt)
+	}
+	for _, currency := range newlyLow {
+		metrics.TerminalAlertsTotal.WithLabelValues("low_cash").Inc()
+		m.logger.WarnContext(ctx, "terminal low on cash", "alert", "low_cash", "terminal_id", terminal.ID, "location", terminal.Location, "branch", terminal.Branch, "cash", cassettes.Total(inventory.Cassettes, currency), "threshold", m.thresholds[string(currency)])
+	}
+	return offline, nil
+}
@@ -0,0 +1,95 @@
+package terminals
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// Terminals sign every request with their shared secret and send:
+//
+//	X-Terminal-ID         the terminal ID
+//	X-Terminal-Timestamp  the signing time in Unix seconds
+//	X-Terminal-Nonce      a value the terminal never sends twice
+//	X-Terminal-Signature  Sign(secret, timestamp, nonce, method, path, body)
+const (
+	IDHeader        = "X-Terminal-ID"
+	TimestampHeader = "X-Terminal-Timestamp"
+	NonceHeader     = "X-Terminal-Nonce"
+	SignatureHeader = "X-Terminal-Signature"
+)
+
+// MaxSkew is how far a request's timestamp may be from the server's clock.
+const MaxSkew = 5 * time.Minute
+
+// MaxBodyBytes is the largest request body read to check a signature.
+const MaxBodyBytes = 1 << 20
+
+// Nonces a terminal has used are kept under
+//
+//	terminal_nonces/<terminalID>/<nonce>
+//
+// for NonceTTL, as long as a request signed with them can be accepted.
+const (
+	NoncesPrefix = "terminal_nonces/"
+	NonceTTL     = 2 * MaxSkew
+)
+
+// ValidNonce reports whether nonce is 16 to 64 letters, digits, '-' or '_',
+// such as a UUID or a hex random value.
+func ValidNonce(nonce string) bool {
+	if len(nonce) < 16 || len(nonce) > 64 {
+		return false
+	}
+	for _, r := range nonce {
+		if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-' || r == '_') {
+			return false
+		}
+	}
+	return true
+}
+
+// NewSecret returns a random shared secret, hex encoded.
+func NewSecret() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("generating terminal secret: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// Sign returns the hex HMAC-SHA256, keyed with secret, of
+// "<timestamp>\n<nonce>\n<method>\n<path>\n<body hash>", where the body
+// hash is the hex SHA-256 of the request body (of no bytes if it is empty).
+func Sign(secret, timestamp, nonce, method, path string, body []byte) string {
+	sum := sha256.Sum256(body)
+	mac := hmac.New(sha256.New, []byte(secret))
+	fmt.Fprintf(mac, "%s\n%s\n%s\n%s\n%s", timestamp, nonce, method, path, hex.EncodeToString(sum[:]))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify reports whether signature is Sign's output for the request and
+// timestamp is within MaxSkew of now. It does not check that the nonce is
+// new.
+func Verify(secret, timestamp, nonce, method, path string, body []byte, signature string, now time.Time) bool {
+	unix, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return false
+	}
+	if skew := now.Sub(time.Unix(unix, 0)); skew > MaxSkew || skew < -MaxSkew {
+		return false
+	}
+	return hmac.Equal([]byte(Sign(secret, timestamp, nonce, method, path, body)), []byte(signature))
+}
+
+// Fingerprint returns the hex SHA-256 of cert, the form in which client
+// certificates are registered.
+func Fingerprint(cert *x509.Certificate) string {
+	sum := sha256.Sum256(cert.Raw)
+	return hex.EncodeToString(sum[:])
+}
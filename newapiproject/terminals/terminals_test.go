@@ -0,0 +1,85 @@
+package terminals
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestVerify(t *testing.T) {
+	const secret = "s3cret"
+	const nonce = "0123456789abcdef"
+	now := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+	stamp := func(t time.Time) string { return strconv.FormatInt(t.Unix(), 10) }
+	body := []byte(`{"amount":"10"}`)
+
+	tests := []struct {
+		name      string
+		timestamp string
+		signWith  string
+		valid     bool
+	}{
+		{"signed now", stamp(now), secret, true},
+		{"at the skew limit in the past", stamp(now.Add(-MaxSkew)), secret, true},
+		{"at the skew limit in the future", stamp(now.Add(MaxSkew)), secret, true},
+		{"too old", stamp(now.Add(-MaxSkew - time.Second)), secret, false},
+		{"too far ahead", stamp(now.Add(MaxSkew + time.Second)), secret, false},
+		{"timestamp not a number", "yesterday", secret, false},
+		{"other secret", stamp(now), "other", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			signature := Sign(tt.signWith, tt.timestamp, nonce, "POST", "/v1/account/withdrawal", body)
+			if got := Verify(secret, tt.timestamp, nonce, "POST", "/v1/account/withdrawal", body, signature, now); got != tt.valid {
+				t.Errorf("Verify = %v, want %v", got, tt.valid)
+			}
+		})
+	}
+}
+
+func TestVerifyCoversRequest(t *testing.T) {
+	const secret = "s3cret"
+	now := time.Now()
+	timestamp := strconv.FormatInt(now.Unix(), 10)
+	signature := Sign(secret, timestamp, "0123456789abcdef", "POST", "/v1/account/withdrawal", []byte(`{"amount":"10"}`))
+
+	// Changing any signed part of the request breaks the signature.
+	tests := []struct {
+		name, timestamp, nonce, method, path, body string
+	}{
+		{"timestamp", strconv.FormatInt(now.Unix()-1, 10), "0123456789abcdef", "POST", "/v1/account/withdrawal", `{"amount":"10"}`},
+		{"nonce", timestamp, "0123456789abcdeg", "POST", "/v1/account/withdrawal", `{"amount":"10"}`},
+		{"method", timestamp, "0123456789abcdef", "PUT", "/v1/account/withdrawal", `{"amount":"10"}`},
+		{"path", timestamp, "0123456789abcdef", "POST", "/v1/account/deposit", `{"amount":"10"}`},
+		{"body", timestamp, "0123456789abcdef", "POST", "/v1/account/withdrawal", `{"amount":"1000"}`},
+		{"empty body", timestamp, "0123456789abcdef", "POST", "/v1/account/withdrawal", ""},
+	}
+	for _, tt := range tests {
+		if Verify(secret, tt.timestamp, tt.nonce, tt.method, tt.path, []byte(tt.body), signature, now) {
+			t.Errorf("signature still valid with another %s", tt.name)
+		}
+	}
+}
+
+func TestValidNonce(t *testing.T) {
+	tests := []struct {
+		nonce string
+		valid bool
+	}{
+		{"0123456789abcdef", true},
+		{"6f1c2a54-3b0e-4c1f-9a57-2d8e4b6f0c13", true},
+		{"A_b-C_d-E_f-G_h-", true},
+		{strings.Repeat("a", 64), true},
+		{"0123456789abcde", false},
+		{strings.Repeat("a", 65), false},
+		{"0123456789abcde!", false},
+		{"0123456789abcdé0", false},
+		{"", false},
+	}
+	for _, tt := range tests {
+		if got := ValidNonce(tt.nonce); got != tt.valid {
+			t.Errorf("ValidNonce(%q) = %v, want %v", tt.nonce, got, tt.valid)
+		}
+	}
+}
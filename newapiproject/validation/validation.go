@@ -0,0 +1,132 @@
+package validation
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+
+	"newapiprojet/apperrors"
+	"newapiprojet/cards"
+	"newapiprojet/cron"
+	"newapiprojet/i18n"
+	"newapiprojet/money"
+
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+)
+
+var (
+	pinPattern      = regexp.MustCompile(`^\d{4}$`)
+	usernamePattern = regexp.MustCompile(`^[a-zA-Z][a-zA-Z0-9._]{2,29}$`)
+)
+
+// Register installs the custom validators on gin's binding engine so that
+// `binding:"..."` tags on request DTOs can use them:
+//
+//	amount    positive money.Money of at most maxAmount whole units (no upper bound when maxAmount <= 0)
+//	currency  a supported ISO 4217 code
+//	cron      a five-field cron expression or macro such as "@monthly"
+//	pan       a card number with a valid Luhn check digit
+//	pin       four digits, not all equal and not a straight sequence
+//	username  3-30 letters, digits, '.' or '_', starting with a letter
+//
+// Field errors are reported using the JSON field names.
+func Register(maxAmount int) error {
+	v, ok := binding.Validator.Engine().(*validator.Validate)
+	if !ok {
+		return errors.New("gin binding validator is not go-playground/validator")
+	}
+
+	v.RegisterTagNameFunc(func(field reflect.StructField) string {
+		for _, tag := range []string{"json", "uri"} {
+			name := strings.Split(field.Tag.Get(tag), ",")[0]
+			if name != "" && name != "-" {
+				return name
+			}
+		}
+		return field.Name
+	})
+
+	validators := map[string]validator.Func{
+		"amount": func(fl validator.FieldLevel) bool {
+			amount, ok := fl.Field().Interface().(money.Money)
+			if !ok || !amount.IsPositive() {
+				return false
+			}
+			if maxAmount <= 0 {
+				return true
+			}
+			limit, err := money.FromMajor(int64(maxAmount), amount.Currency())
+			if err != nil {
+				return true
+			}
+			cmp, _ := amount.Cmp(limit)
+			return cmp <= 0
+		},
+		"pin": func(fl validator.FieldLevel) bool {
+			return ValidPIN(fl.Field().String())
+		},
+		"currency": func(fl validator.FieldLevel) bool {
+			_, err := money.ParseCurrency(fl.Field().String())
+			return err == nil
+		},
+		"cron": func(fl validator.FieldLevel) bool {
+			_, err := cron.Parse(fl.Field().String())
+			return err == nil
+		},
+		"pan": func(fl validator.FieldLevel) bool {
+			return cards.ValidPAN(fl.Field().String())
+		},
+		"username": func(fl validator.FieldLevel) bool {
+			return usernamePattern.MatchString(fl.Field().String())
+		},
+	}
+	for tag, fn := range validators {
+		if err := v.RegisterValidation(tag, fn); err != nil {
+			return fmt.Errorf("registering %q validator: %w", tag, err)
+		}
+	}
+	return nil
+}
+
+// ValidPIN reports whether pin satisfies the PIN policy.
+func ValidPIN(pin string) bool {
+	if !pinPattern.MatchString(pin) {
+		return false
+	}
+	same, ascending, descending := true, true, true
+	for i := 1; i < len(pin); i++ {
+		diff := int(pin[i]) - int(pin[i-1])
+		same = same && diff == 0
+		ascending = ascending && diff == 1
+		descending = descending && diff == -1
+	}
+	return !same && !ascending && !descending
+}
+
+// FromBindError converts an error from c.ShouldBindJSON or c.ShouldBindUri
+// into an API error. Validation failures list every offending field, and
+// malformed money amounts become InvalidAmount; anything else means the body
+// could not be decoded.
+func FromBindError(err error) *apperrors.Error {
+	if errors.Is(err, money.ErrInvalidAmount) || errors.Is(err, money.ErrUnknownCurrency) || errors.Is(err, money.ErrOverflow) {
+		return apperrors.Wrap(apperrors.InvalidAmount, err)
+	}
+
+	var verrs validator.ValidationErrors
+	if !errors.As(err, &verrs) {
+		return apperrors.Wrap(apperrors.InvalidRequest, err).WithDetail(i18n.MsgInvalidBody)
+	}
+
+	fields := make([]apperrors.FieldError, 0, len(verrs))
+	for _, fe := range verrs {
+		fields = append(fields, apperrors.FieldError{
+			Field: fe.Field(),
+			Rule:  fe.Tag(),
+			Param: fe.Param(),
+		})
+	}
+	return apperrors.Wrap(apperrors.ValidationFailed, err).WithFields(fields)
+}